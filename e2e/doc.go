@@ -0,0 +1,4 @@
+// Package e2e holds the whole-system tests: payment, fraud, wallet and
+// metrics services run in process, wired through an in-memory broker (see
+// membroker) with an in-memory SQLite database per service.
+package e2e
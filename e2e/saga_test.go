@@ -0,0 +1,215 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-event-contracts/membroker"
+	"github.com/jeffleon2/draftea-fraud-service/fraudtest"
+	"github.com/jeffleon2/draftea-metric-service/metricstest"
+	"github.com/jeffleon2/draftea-payment-service/paymenttest"
+	"github.com/jeffleon2/draftea-wallet-service/wallettest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const sagaTimeout = 10 * time.Second
+
+// system is the four services wired through one broker, each with its own
+// database.
+type system struct {
+	broker   *membroker.Broker
+	payments *paymenttest.Service
+	fraud    *fraudtest.Service
+	wallets  *wallettest.Service
+	metrics  *metricstest.Service
+}
+
+func startSystem(t *testing.T) *system {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &system{broker: membroker.New()}
+
+	var err error
+	s.payments, err = paymenttest.Start(ctx, s.broker, openDB(t, "payments"))
+	require.NoError(t, err)
+	s.fraud, err = fraudtest.Start(ctx, s.broker, openDB(t, "fraud"))
+	require.NoError(t, err)
+	s.wallets, err = wallettest.Start(ctx, s.broker, openDB(t, "wallets"))
+	require.NoError(t, err)
+	s.metrics, err = metricstest.Start(ctx, s.broker)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		cancel()
+		s.payments.Close()
+		s.fraud.Close()
+		s.wallets.Close()
+		s.metrics.Close()
+	})
+	return s
+}
+
+// openDB opens an in-memory SQLite database in place of a service's Postgres.
+func openDB(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s-%d?mode=memory&cache=shared", name, time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// payment waits for the only payment to reach status.
+func (s *system) payment(t *testing.T, status string) paymenttest.Payment {
+	t.Helper()
+
+	var payment paymenttest.Payment
+	require.Eventually(t, func() bool {
+		payments, err := s.payments.Payments(context.Background())
+		if err != nil || len(payments) != 1 {
+			return false
+		}
+		payment = payments[0]
+		return payment.Status == status
+	}, sagaTimeout, 10*time.Millisecond, "payment never reached %s", status)
+	return payment
+}
+
+func TestPaymentSaga(t *testing.T) {
+	for _, format := range []string{"json", "protobuf"} {
+		t.Run(format, func(t *testing.T) {
+			t.Setenv("KAFKA_PUBLISH_FORMAT", format)
+			ctx := context.Background()
+			s := startSystem(t)
+			require.NoError(t, s.wallets.CreateWallet(ctx, "w1", "user_1", 1000))
+
+			require.NoError(t, s.payments.CreatePayment(ctx, 150.5, "USD", "CREDIT_CARD", "user_1"))
+
+			payment := s.payment(t, "AUTHORIZED")
+			assert.True(t, payment.FraudCleared)
+			assert.True(t, payment.WalletApproved)
+			require.Eventually(t, func() bool {
+				balance, err := s.wallets.Balance(ctx, "user_1")
+				return err == nil && balance == 849.5
+			}, sagaTimeout, 10*time.Millisecond, "wallet was not debited")
+
+			decisions, err := s.fraud.Decisions(ctx, payment.ID)
+			require.NoError(t, err)
+			require.Len(t, decisions, 1)
+			assert.Equal(t, contracts.FraudStatusApproved, decisions[0].Status)
+
+			require.Eventually(t, func() bool {
+				return s.metrics.Handled(contracts.EventTypeWalletDebitRequested) == 1
+			}, sagaTimeout, 10*time.Millisecond)
+			for _, eventType := range []string{
+				contracts.EventTypePaymentCreated,
+				contracts.EventTypePaymentChecked,
+				contracts.EventTypeWalletFundsVerified,
+				contracts.EventTypeWalletDebitRequested,
+			} {
+				assert.Equal(t, 1, s.metrics.Handled(eventType), eventType)
+			}
+			assert.Empty(t, s.broker.DeadLetters())
+		})
+	}
+}
+
+func TestPaymentSagaPropagatesTraceID(t *testing.T) {
+	s := startSystem(t)
+	require.NoError(t, s.wallets.CreateWallet(context.Background(), "w1", "user_1", 1000))
+
+	ctx := contracts.WithTraceID(context.Background(), "trace-e2e")
+	require.NoError(t, s.payments.CreatePayment(ctx, 150.5, "USD", "CREDIT_CARD", "user_1"))
+
+	payment := s.payment(t, "AUTHORIZED")
+	assert.Equal(t, "trace-e2e", payment.TraceID)
+	require.Eventually(t, func() bool {
+		return s.metrics.Handled(contracts.EventTypeWalletDebitRequested) == 1
+	}, sagaTimeout, 10*time.Millisecond)
+
+	for _, topic := range []string{
+		contracts.EventTypePaymentCreated,
+		contracts.EventTypePaymentChecked,
+		contracts.EventTypeWalletFundsVerified,
+		contracts.EventTypeWalletDebitRequested,
+	} {
+		messages := s.broker.Messages(topic)
+		require.Len(t, messages, 1, topic)
+		assert.Equal(t, "trace-e2e", messages[0].Headers[contracts.HeaderTraceID], topic)
+	}
+}
+
+func TestPaymentSagaInsufficientFunds(t *testing.T) {
+	ctx := context.Background()
+	s := startSystem(t)
+	require.NoError(t, s.wallets.CreateWallet(ctx, "w1", "user_1", 100))
+
+	require.NoError(t, s.payments.CreatePayment(ctx, 150.5, "USD", "CREDIT_CARD", "user_1"))
+
+	payment := s.payment(t, "FAILED")
+	assert.Equal(t, "Insufficient funds", payment.FailedReason)
+	assert.False(t, payment.WalletApproved)
+	assert.Empty(t, s.broker.Messages(contracts.EventTypeWalletDebitRequested), "a failed payment is not debited")
+
+	balance, err := s.wallets.Balance(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, balance)
+	assert.Empty(t, s.broker.DeadLetters())
+}
+
+func TestPaymentSagaSkipsRedeliveredEvents(t *testing.T) {
+	ctx := context.Background()
+	s := startSystem(t)
+	require.NoError(t, s.wallets.CreateWallet(ctx, "w1", "user_1", 1000))
+
+	require.NoError(t, s.payments.CreatePayment(ctx, 150.5, "USD", "CREDIT_CARD", "user_1"))
+	s.payment(t, "AUTHORIZED")
+	require.Eventually(t, func() bool {
+		return s.metrics.Handled(contracts.EventTypeWalletDebitRequested) == 1
+	}, sagaTimeout, 10*time.Millisecond)
+
+	var eventIDs []string
+	for _, topic := range []string{contracts.EventTypePaymentChecked, contracts.EventTypeWalletFundsVerified} {
+		messages := s.broker.Messages(topic)
+		require.Len(t, messages, 1, topic)
+		var envelope contracts.Envelope
+		require.NoError(t, json.Unmarshal(messages[0].Value, &envelope))
+		eventIDs = append(eventIDs, envelope.ID)
+	}
+	processed, err := s.payments.ProcessedEvents(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, eventIDs, processed, "the inbox records events by envelope ID")
+
+	// The producer writes payments.checked again, as after a timed out publish.
+	checked := s.broker.Messages(contracts.EventTypePaymentChecked)[0]
+	_, err = s.broker.Produce(ctx, checked.Topic, checked.Key, checked.Value, checked.Headers)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		var consumed int64
+		for _, offset := range s.broker.Committed("payment-service", checked.Topic) {
+			consumed += offset
+		}
+		return consumed == 2
+	}, sagaTimeout, 10*time.Millisecond, "the duplicate was not consumed")
+
+	processed, err = s.payments.ProcessedEvents(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, eventIDs, processed)
+	assert.Len(t, s.broker.Messages(contracts.EventTypeWalletDebitRequested), 1, "the duplicate is not applied again")
+	assert.Equal(t, "AUTHORIZED", s.payment(t, "AUTHORIZED").Status)
+	assert.Empty(t, s.broker.DeadLetters())
+}
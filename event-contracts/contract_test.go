@@ -0,0 +1,201 @@
+package contracts_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-event-contracts/eventspb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// contractEvents lists every published event. Adding an event type without
+// adding it here leaves it without contract tests.
+var contractEvents = []contracts.Event{
+	contracts.PaymentCreatedEvent{},
+	contracts.FraudCheckEvent{},
+	contracts.WalletResponseEvent{},
+	contracts.WalletDebitRequestedEvent{},
+}
+
+// TestSchemaMatchesGoType fails when a field is added to, renamed in or
+// removed from an event without updating its current schema.
+func TestSchemaMatchesGoType(t *testing.T) {
+	for _, event := range contractEvents {
+		t.Run(event.EventType(), func(t *testing.T) {
+			var schema struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			}
+			readJSON(t, schemaPath(event.EventType(), event.EventVersion()), &schema)
+
+			var properties []string
+			for name := range schema.Properties {
+				properties = append(properties, name)
+			}
+			sort.Strings(properties)
+
+			assert.Equal(t, jsonFields(reflect.TypeOf(event)), properties)
+		})
+	}
+}
+
+// protoMessages are the protobuf messages of contractEvents.
+var protoMessages = map[string]proto.Message{
+	contracts.EventTypePaymentCreated:       &eventspb.PaymentCreated{},
+	contracts.EventTypePaymentChecked:       &eventspb.PaymentChecked{},
+	contracts.EventTypeWalletFundsVerified:  &eventspb.WalletFundsVerified{},
+	contracts.EventTypeWalletDebitRequested: &eventspb.WalletDebitRequested{},
+}
+
+// TestProtoMatchesGoType fails when a field is added to an event without
+// adding it to its protobuf message, which would drop it from protobuf
+// encoded messages.
+func TestProtoMatchesGoType(t *testing.T) {
+	for _, event := range contractEvents {
+		t.Run(event.EventType(), func(t *testing.T) {
+			message, ok := protoMessages[event.EventType()]
+			require.True(t, ok, "missing protobuf message")
+
+			var fields []string
+			descriptors := message.ProtoReflect().Descriptor().Fields()
+			for i := 0; i < descriptors.Len(); i++ {
+				fields = append(fields, string(descriptors.Get(i).Name()))
+			}
+			sort.Strings(fields)
+
+			assert.Equal(t, jsonFields(reflect.TypeOf(event)), fields)
+		})
+	}
+}
+
+// TestFixturesMatchGoType fails when the Go type can no longer read a
+// published payload, e.g. because a field was renamed.
+func TestFixturesMatchGoType(t *testing.T) {
+	for _, event := range contractEvents {
+		t.Run(event.EventType(), func(t *testing.T) {
+			raw, err := os.ReadFile(fixturePath(event.EventType(), event.EventVersion()))
+			require.NoError(t, err, "every current version needs a fixture")
+
+			decoded := reflect.New(reflect.TypeOf(event)).Interface()
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.DisallowUnknownFields()
+			require.NoError(t, decoder.Decode(decoded))
+
+			assert.NoError(t, contracts.ValidateEvent(reflect.ValueOf(decoded).Elem().Interface().(contracts.Event)))
+		})
+	}
+}
+
+// TestFixturesMatchEveryMinorSchema checks compatibility across versions: a
+// payload of any version must still pass every schema of its major, so a
+// consumer on an older minor keeps reading what newer producers publish.
+func TestFixturesMatchEveryMinorSchema(t *testing.T) {
+	for _, event := range contractEvents {
+		eventType := event.EventType()
+		fixtures, err := filepath.Glob(filepath.Join("testdata", eventType, "*.json"))
+		require.NoError(t, err)
+		require.NotEmpty(t, fixtures, "missing fixtures for %s", eventType)
+
+		for _, fixture := range fixtures {
+			fixtureVersion := strings.TrimSuffix(filepath.Base(fixture), ".json")
+			raw, err := os.ReadFile(fixture)
+			require.NoError(t, err)
+
+			for _, version := range contracts.Versions(eventType) {
+				if majorOf(version) != majorOf(fixtureVersion) {
+					continue
+				}
+				t.Run(eventType+"/"+fixtureVersion+"->"+version, func(t *testing.T) {
+					assert.NoError(t, contracts.Validate(eventType, version, raw))
+				})
+			}
+		}
+	}
+}
+
+func TestValidateEnvelope(t *testing.T) {
+	event := contracts.PaymentCreatedEvent{
+		ID:         "payment-123",
+		Amount:     100,
+		Currency:   "USD",
+		Status:     "PENDING",
+		Method:     "card",
+		CustomerID: "user-1",
+		TraceID:    "trace-123",
+		CreatedAt:  time.Now(),
+	}
+
+	assert.NoError(t, contracts.ValidateEnvelope(marshal(t, event, "")))
+}
+
+func TestValidateEnvelopeRejectsInvalidData(t *testing.T) {
+	event := contracts.PaymentCreatedEvent{ID: "payment-123", Amount: -1, Currency: "usd"}
+
+	err := contracts.ValidateEnvelope(marshal(t, event, ""))
+
+	assert.ErrorIs(t, err, contracts.ErrInvalidEvent)
+}
+
+func TestValidateEnvelopeRejectsMessagesWithoutEnvelope(t *testing.T) {
+	err := contracts.ValidateEnvelope([]byte(`{"id":"payment-123","status":"APPROVED"}`))
+
+	assert.ErrorIs(t, err, contracts.ErrInvalidEnvelope)
+}
+
+func TestValidateUsesLatestMinorOfSameMajor(t *testing.T) {
+	raw := []byte(`{"id":"payment-123","trace_id":"","status":"APPROVED","checked_at":"2025-01-15T10:30:01Z"}`)
+
+	assert.NoError(t, contracts.Validate(contracts.EventTypePaymentChecked, "1.4", raw))
+	assert.ErrorIs(t, contracts.Validate(contracts.EventTypePaymentChecked, "2.0", raw), contracts.ErrUnsupportedVersion)
+	assert.ErrorIs(t, contracts.Validate("payments.refunded", "1.0", raw), contracts.ErrUnknownEventType)
+}
+
+func TestValidateRejectsUnknownStatus(t *testing.T) {
+	event := contracts.FraudCheckEvent{ID: "payment-123", Status: "MAYBE", CheckedAt: time.Now()}
+
+	assert.ErrorIs(t, contracts.ValidateEvent(event), contracts.ErrInvalidEvent)
+}
+
+func schemaPath(eventType, version string) string {
+	return filepath.Join("schemas", eventType, version+".json")
+}
+
+func fixturePath(eventType, version string) string {
+	return filepath.Join("testdata", eventType, version+".json")
+}
+
+func readJSON(t *testing.T, name string, v interface{}) {
+	t.Helper()
+
+	raw, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, v))
+}
+
+// jsonFields returns the sorted JSON names of the fields of a struct type.
+func jsonFields(typ reflect.Type) []string {
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func majorOf(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
@@ -0,0 +1,130 @@
+package contracts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// SpecVersion is the CloudEvents version of the envelope.
+	SpecVersion = "1.0"
+	// ContentType is the Kafka content-type header of enveloped messages
+	// (CloudEvents structured mode).
+	ContentType = "application/cloudevents+json"
+	// HeaderContentType is the Kafka header carrying ContentType.
+	HeaderContentType = "content-type"
+	// HeaderMessageKey carries the message key on transports without keys
+	// of their own, such as NATS.
+	HeaderMessageKey = "x-message-key"
+
+	dataContentType = "application/json"
+	// schemaPrefix is also the directory of the embedded JSON Schemas.
+	schemaPrefix = "/schemas"
+)
+
+var (
+	ErrInvalidEnvelope    = errors.New("invalid event envelope")
+	ErrUnexpectedType     = errors.New("unexpected event type")
+	ErrUnsupportedVersion = errors.New("unsupported event version")
+)
+
+// Event is implemented by the payloads published inside an envelope.
+type Event interface {
+	// EventType names the event, e.g. payments.created.
+	EventType() string
+	// EventVersion is the version of the payload schema, "<major>.<minor>".
+	// Minor versions only add optional fields; a new major version breaks
+	// consumers of the previous one.
+	EventVersion() string
+}
+
+// Traced is implemented by events that belong to a traced payment flow.
+type Traced interface {
+	EventTraceID() string
+}
+
+// Envelope is a CloudEvents-style envelope around every published event.
+// DataSchema is /schemas/<type>/<version>.
+type Envelope struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	SpecVersion     string          `json:"specversion"`
+	Time            time.Time       `json:"time"`
+	TraceID         string          `json:"traceid,omitempty"`
+	DataSchema      string          `json:"dataschema"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// New wraps event in an envelope produced by source.
+func New(source string, event Event) (Envelope, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("error marshaling %s event: %w", event.EventType(), err)
+	}
+
+	envelope := Envelope{
+		ID:              uuid.NewString(),
+		Type:            event.EventType(),
+		Source:          source,
+		SpecVersion:     SpecVersion,
+		Time:            time.Now().UTC(),
+		DataSchema:      path.Join(schemaPrefix, event.EventType(), event.EventVersion()),
+		DataContentType: dataContentType,
+		Data:            data,
+	}
+	if traced, ok := event.(Traced); ok {
+		envelope.TraceID = traced.EventTraceID()
+	}
+	return envelope, nil
+}
+
+// Parse decodes an envelope without its data.
+func Parse(raw []byte) (Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return Envelope{}, err
+	}
+	if envelope.SpecVersion != SpecVersion || envelope.Type == "" || len(envelope.Data) == 0 {
+		return Envelope{}, fmt.Errorf("%w: specversion %q, type %q", ErrInvalidEnvelope, envelope.SpecVersion, envelope.Type)
+	}
+	return envelope, nil
+}
+
+// Version returns the payload schema version, taken from DataSchema.
+func (e Envelope) Version() string {
+	return path.Base(e.DataSchema)
+}
+
+// Decode unmarshals the data into event. The envelope must carry event's
+// type, and a version with the same major as the one the consumer knows.
+func (e Envelope) Decode(event Event) error {
+	if e.Type != event.EventType() {
+		return fmt.Errorf("%w: got %s, want %s", ErrUnexpectedType, e.Type, event.EventType())
+	}
+	if major(e.Version()) != major(event.EventVersion()) {
+		return fmt.Errorf("%w: %s %s, this consumer reads %s", ErrUnsupportedVersion, e.Type, e.Version(), event.EventVersion())
+	}
+	return json.Unmarshal(e.Data, event)
+}
+
+// Decode parses raw as an envelope and decodes its data into event.
+func Decode(raw []byte, event Event) (Envelope, error) {
+	envelope, err := Parse(raw)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return envelope, envelope.Decode(event)
+}
+
+func major(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
@@ -0,0 +1,88 @@
+package contracts_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = "payment-service"
+
+func TestNewEnvelope(t *testing.T) {
+	event := contracts.PaymentCreatedEvent{ID: "payment-123", Amount: 100, TraceID: "trace-123"}
+
+	env, err := contracts.New(source, event)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, env.ID)
+	assert.Equal(t, contracts.EventTypePaymentCreated, env.Type)
+	assert.Equal(t, source, env.Source)
+	assert.Equal(t, contracts.SpecVersion, env.SpecVersion)
+	assert.Equal(t, "trace-123", env.TraceID)
+	assert.Equal(t, "/schemas/payments.created/1.0", env.DataSchema)
+	assert.Equal(t, "1.0", env.Version())
+	assert.False(t, env.Time.IsZero())
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	event := contracts.WalletDebitRequestedEvent{PaymentID: "payment-123", UserID: "user-1", Amount: 50}
+	raw := marshal(t, event, "")
+
+	var decoded contracts.WalletDebitRequestedEvent
+	env, err := contracts.Decode(raw, &decoded)
+
+	require.NoError(t, err)
+	assert.Equal(t, event, decoded)
+	assert.Equal(t, contracts.EventTypeWalletDebitRequested, env.Type)
+}
+
+func TestDecodeAcceptsNewerMinorVersion(t *testing.T) {
+	raw := marshal(t, contracts.FraudCheckEvent{ID: "payment-123", Status: "APPROVED"}, "/schemas/payments.checked/1.4")
+
+	var decoded contracts.FraudCheckEvent
+	_, err := contracts.Decode(raw, &decoded)
+
+	require.NoError(t, err)
+	assert.Equal(t, "APPROVED", decoded.Status)
+}
+
+func TestDecodeRejectsUnknownMajorVersion(t *testing.T) {
+	raw := marshal(t, contracts.FraudCheckEvent{ID: "payment-123"}, "/schemas/payments.checked/2.0")
+
+	var decoded contracts.FraudCheckEvent
+	_, err := contracts.Decode(raw, &decoded)
+
+	assert.ErrorIs(t, err, contracts.ErrUnsupportedVersion)
+}
+
+func TestDecodeRejectsOtherType(t *testing.T) {
+	raw := marshal(t, contracts.FraudCheckEvent{ID: "payment-123"}, "")
+
+	var decoded contracts.WalletResponseEvent
+	_, err := contracts.Decode(raw, &decoded)
+
+	assert.ErrorIs(t, err, contracts.ErrUnexpectedType)
+}
+
+func TestParseRejectsMessagesWithoutEnvelope(t *testing.T) {
+	_, err := contracts.Parse([]byte(`{"id":"payment-123","status":"APPROVED"}`))
+
+	assert.ErrorIs(t, err, contracts.ErrInvalidEnvelope)
+}
+
+// marshal wraps event in an envelope, overriding its dataschema when given.
+func marshal(t *testing.T, event contracts.Event, dataSchema string) []byte {
+	t.Helper()
+
+	env, err := contracts.New(source, event)
+	require.NoError(t, err)
+	if dataSchema != "" {
+		env.DataSchema = dataSchema
+	}
+	raw, err := json.Marshal(env)
+	require.NoError(t, err)
+	return raw
+}
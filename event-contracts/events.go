@@ -0,0 +1,158 @@
+// Package contracts holds the canonical events exchanged by the payment,
+// fraud, wallet and metrics services over Kafka: their Go types, the
+// CloudEvents-style envelope they travel in and the JSON Schema of every
+// published version.
+package contracts
+
+import "time"
+
+// Event types carried in the envelope. Each one matches the topic the event
+// is published to.
+const (
+	EventTypePaymentCreated       = "payments.created"
+	EventTypePaymentChecked       = "payments.checked"
+	EventTypeWalletFundsVerified  = "wallet.funds.verified"
+	EventTypeWalletDebitRequested = "wallet.debit.requested"
+)
+
+// Current payload schema version of each event type. Publishing a new
+// version needs its schema under schemas/<type>/<version>.json and a
+// fixture under testdata/<type>/<version>.json.
+const (
+	PaymentCreatedVersion       = "1.0"
+	PaymentCheckedVersion       = "1.0"
+	WalletFundsVerifiedVersion  = "1.1"
+	WalletDebitRequestedVersion = "1.0"
+)
+
+// Fraud check statuses of payments.checked.
+const (
+	FraudStatusApproved = "APPROVED"
+	FraudStatusDeclined = "DECLINED"
+	// FraudStatusReview holds the payment for manual review, e.g. a possible
+	// sanctions list match.
+	FraudStatusReview = "REVIEW"
+)
+
+type WalletStatus string
+
+// Funds verification statuses of wallet.funds.verified.
+const (
+	WalletStatusApproved WalletStatus = "APPROVED"
+	WalletStatusDeclined WalletStatus = "DECLINED"
+)
+
+// PaymentCreatedEvent is published by payment-service when a payment is
+// created; fraud-service, wallet-service and metrics-service consume it.
+type PaymentCreatedEvent struct {
+	ID              string    `json:"id"`
+	Amount          float64   `json:"amount"`
+	Currency        string    `json:"currency"`
+	Status          string    `json:"status"`
+	Method          string    `json:"method"`
+	CustomerID      string    `json:"customer_id"`
+	CustomerName    string    `json:"customer_name,omitempty"`
+	CustomerCountry string    `json:"customer_country,omitempty"`
+	TraceID         string    `json:"trace_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (e PaymentCreatedEvent) EventType() string {
+	return EventTypePaymentCreated
+}
+
+func (e PaymentCreatedEvent) EventVersion() string {
+	return PaymentCreatedVersion
+}
+
+func (e PaymentCreatedEvent) EventTraceID() string {
+	return e.TraceID
+}
+
+// MessageKey keys the event by payment so all events of a payment share a partition.
+func (e PaymentCreatedEvent) MessageKey() string {
+	return e.ID
+}
+
+// FraudCheckEvent is published by fraud-service with the fraud decision of
+// a payment; payment-service and metrics-service consume it.
+type FraudCheckEvent struct {
+	ID        string    `json:"id"`
+	TraceID   string    `json:"trace_id"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func (e FraudCheckEvent) EventType() string {
+	return EventTypePaymentChecked
+}
+
+func (e FraudCheckEvent) EventVersion() string {
+	return PaymentCheckedVersion
+}
+
+func (e FraudCheckEvent) EventTraceID() string {
+	return e.TraceID
+}
+
+// MessageKey keys the event by payment so all events of a payment share a partition.
+func (e FraudCheckEvent) MessageKey() string {
+	return e.ID
+}
+
+// WalletResponseEvent is published by wallet-service with the funds
+// verification of a payment; payment-service and metrics-service consume it.
+type WalletResponseEvent struct {
+	PaymentID string       `json:"payment_id"`
+	UserID    string       `json:"user_id"`
+	Status    WalletStatus `json:"status"`
+	Amount    float64      `json:"amount"`
+	Reason    string       `json:"reason"`
+	// TraceID was added in 1.1.
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+func (e WalletResponseEvent) EventType() string {
+	return EventTypeWalletFundsVerified
+}
+
+func (e WalletResponseEvent) EventVersion() string {
+	return WalletFundsVerifiedVersion
+}
+
+func (e WalletResponseEvent) EventTraceID() string {
+	return e.TraceID
+}
+
+// MessageKey keys the event by user so the events of a wallet stay in order.
+func (e WalletResponseEvent) MessageKey() string {
+	return e.UserID
+}
+
+// WalletDebitRequestedEvent is published by payment-service once a payment is
+// authorized; wallet-service and metrics-service consume it.
+type WalletDebitRequestedEvent struct {
+	PaymentID string  `json:"payment_id"`
+	UserID    string  `json:"user_id"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+	TraceID   string  `json:"trace_id"`
+}
+
+func (e WalletDebitRequestedEvent) EventType() string {
+	return EventTypeWalletDebitRequested
+}
+
+func (e WalletDebitRequestedEvent) EventVersion() string {
+	return WalletDebitRequestedVersion
+}
+
+func (e WalletDebitRequestedEvent) EventTraceID() string {
+	return e.TraceID
+}
+
+// MessageKey keys the event by user so debits of a wallet are applied in order.
+func (e WalletDebitRequestedEvent) MessageKey() string {
+	return e.UserID
+}
@@ -0,0 +1,277 @@
+// Package membroker is an in-process message broker with the Kafka semantics
+// the services rely on: topics split in partitions, messages with the same
+// key kept in one partition and consumed in order, consumer groups sharing
+// the partitions of their topics, committed offsets and redelivery of the
+// messages a handler failed. It lets tests run the whole payment saga without
+// Kafka.
+package membroker
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPartitions      = 3
+	DefaultMaxDeliveries   = 5
+	DefaultRedeliveryDelay = 10 * time.Millisecond
+)
+
+// Message is a message stored in a topic partition.
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Time      time.Time
+}
+
+// DeadLetter is a message a consumer group gave up on: it could not be
+// decoded, its handler failed permanently or it was delivered MaxDeliveries
+// times. Its offset is committed so the partition moves on.
+type DeadLetter struct {
+	Message    Message
+	GroupID    string
+	Deliveries int
+	Err        error
+}
+
+// Broker stores topics in memory. Its zero value is not usable; create it
+// with New.
+type Broker struct {
+	// Partitions is the number of partitions of new topics.
+	Partitions int
+	// MaxDeliveries is how many times a group hands a message to its
+	// handler before dead-lettering it.
+	MaxDeliveries int
+	// RedeliveryDelay is the wait before a failed message is handed to the
+	// handler again.
+	RedeliveryDelay time.Duration
+
+	mu          sync.Mutex
+	changed     *sync.Cond
+	topics      map[string][][]Message
+	roundRobin  map[string]int
+	groups      map[string]*group
+	deadLetters []DeadLetter
+}
+
+// group is a consumer group: the partitions of each topic are shared by the
+// members subscribed to it, and offsets[topic][partition] is the next offset
+// the group consumes. A join or leave moves partitions between members; a
+// member that lost a partition mid-message cannot commit it, so the new owner
+// gets it again.
+type group struct {
+	members []*Consumer
+	offsets map[string][]int64
+}
+
+func New() *Broker {
+	b := &Broker{
+		Partitions:      DefaultPartitions,
+		MaxDeliveries:   DefaultMaxDeliveries,
+		RedeliveryDelay: DefaultRedeliveryDelay,
+		topics:          make(map[string][][]Message),
+		roundRobin:      make(map[string]int),
+		groups:          make(map[string]*group),
+	}
+	b.changed = sync.NewCond(&b.mu)
+	return b
+}
+
+// Produce appends a message to topic, creating the topic if needed. Messages
+// with the same key go to the same partition; messages without a key are
+// spread round robin.
+func (b *Broker) Produce(ctx context.Context, topic string, key, value []byte, headers map[string]string) (Message, error) {
+	if err := ctx.Err(); err != nil {
+		return Message{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	partitions := b.topic(topic)
+	partition := b.partitionFor(topic, key, len(partitions))
+	msg := Message{
+		Topic:     topic,
+		Partition: partition,
+		Offset:    int64(len(partitions[partition])),
+		Key:       key,
+		Value:     value,
+		Headers:   headers,
+		Time:      time.Now(),
+	}
+	partitions[partition] = append(partitions[partition], msg)
+
+	b.changed.Broadcast()
+	return msg, nil
+}
+
+// Messages returns every message of topic, partition by partition.
+func (b *Broker) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var messages []Message
+	for _, partition := range b.topics[topic] {
+		messages = append(messages, partition...)
+	}
+	return messages
+}
+
+// Committed returns the next offset groupID consumes from each partition of
+// topic.
+func (b *Broker) Committed(groupID, topic string) []int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.groups[groupID]
+	if !ok {
+		return make([]int64, len(b.topics[topic]))
+	}
+	return append([]int64(nil), g.offsetsOf(topic, len(b.topic(topic)))...)
+}
+
+// DeadLetters returns the messages the consumer groups gave up on.
+func (b *Broker) DeadLetters() []DeadLetter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]DeadLetter(nil), b.deadLetters...)
+}
+
+func (b *Broker) partitionCount(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.topic(topic))
+}
+
+// topic returns the partitions of name, creating them if needed. b.mu must
+// be held.
+func (b *Broker) topic(name string) [][]Message {
+	partitions, ok := b.topics[name]
+	if !ok {
+		partitions = make([][]Message, max(b.Partitions, 1))
+		b.topics[name] = partitions
+	}
+	return partitions
+}
+
+func (b *Broker) partitionFor(topic string, key []byte, partitions int) int {
+	if len(key) == 0 {
+		partition := b.roundRobin[topic] % partitions
+		b.roundRobin[topic]++
+		return partition
+	}
+
+	hash := fnv.New32a()
+	hash.Write(key)
+	return int(hash.Sum32() % uint32(partitions))
+}
+
+func (b *Broker) join(c *Consumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.groups[c.GroupID]
+	if !ok {
+		g = &group{offsets: make(map[string][]int64)}
+		b.groups[c.GroupID] = g
+	}
+	for _, topic := range c.Topics {
+		b.topic(topic)
+	}
+	g.members = append(g.members, c)
+	b.changed.Broadcast()
+}
+
+func (b *Broker) leave(c *Consumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g := b.groups[c.GroupID]
+	for i, member := range g.members {
+		if member == c {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			break
+		}
+	}
+	b.changed.Broadcast()
+}
+
+// fetch blocks until c owns partition of topic and the partition has a
+// message at the group's offset. It returns false once ctx is cancelled.
+func (b *Broker) fetch(ctx context.Context, c *Consumer, topic string, partition int) (Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for {
+		if ctx.Err() != nil {
+			return Message{}, false
+		}
+
+		g := b.groups[c.GroupID]
+		partitions := b.topic(topic)
+		if g.owner(topic, partition) == c {
+			offset := g.offsetsOf(topic, len(partitions))[partition]
+			if offset < int64(len(partitions[partition])) {
+				return partitions[partition][offset], true
+			}
+		}
+		b.changed.Wait()
+	}
+}
+
+// commit moves the group's offset past msg. It fails if c no longer owns
+// the partition of msg.
+func (b *Broker) commit(c *Consumer, msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g := b.groups[c.GroupID]
+	if g.owner(msg.Topic, msg.Partition) != c {
+		return errRebalanced
+	}
+	g.offsetsOf(msg.Topic, len(b.topic(msg.Topic)))[msg.Partition] = msg.Offset + 1
+	b.changed.Broadcast()
+	return nil
+}
+
+func (b *Broker) deadLetter(deadLetter DeadLetter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deadLetters = append(b.deadLetters, deadLetter)
+}
+
+var errRebalanced = errors.New("consumer group rebalanced")
+
+// owner returns the member consuming partition of topic: the members
+// subscribed to topic take its partitions in turn, in the order they joined.
+func (g *group) owner(topic string, partition int) *Consumer {
+	var subscribed []*Consumer
+	for _, member := range g.members {
+		if member.subscribes(topic) {
+			subscribed = append(subscribed, member)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+	return subscribed[partition%len(subscribed)]
+}
+
+func (g *group) offsetsOf(topic string, partitions int) []int64 {
+	offsets, ok := g.offsets[topic]
+	if !ok {
+		offsets = make([]int64, partitions)
+		g.offsets[topic] = offsets
+	}
+	return offsets
+}
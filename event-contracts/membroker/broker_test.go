@@ -0,0 +1,268 @@
+package membroker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-event-contracts/membroker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const topic = "payments.checked"
+
+// recorder collects the IDs of the handled events.
+type recorder struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *recorder) handle(ctx context.Context, topic string, envelope contracts.Envelope) error {
+	var event contracts.FraudCheckEvent
+	if err := envelope.Decode(&event); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, event.ID)
+	return nil
+}
+
+func (r *recorder) handled() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ids...)
+}
+
+func fraudCheck(id string) contracts.FraudCheckEvent {
+	return contracts.FraudCheckEvent{ID: id, Status: "APPROVED", CheckedAt: time.Now()}
+}
+
+func publish(t *testing.T, broker *membroker.Broker, ids ...string) {
+	t.Helper()
+
+	publisher := broker.Publisher("fraud-service")
+	for _, id := range ids {
+		require.NoError(t, publisher.Publish(context.Background(), topic, fraudCheck(id)))
+	}
+}
+
+func listen(t *testing.T, consumer *membroker.Consumer, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) context.CancelFunc {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.Listen(ctx, handler)
+	t.Cleanup(func() {
+		cancel()
+		consumer.Close()
+	})
+	return cancel
+}
+
+func TestPublishKeysMessagesToPartitions(t *testing.T) {
+	broker := membroker.New()
+
+	publish(t, broker, "payment-1", "payment-2", "payment-1")
+
+	messages := broker.Messages(topic)
+	require.Len(t, messages, 3)
+	partitions := map[string]int{}
+	for _, msg := range messages {
+		key := string(msg.Key)
+		if partition, ok := partitions[key]; ok {
+			assert.Equal(t, partition, msg.Partition, "messages with the same key share a partition")
+		}
+		partitions[key] = msg.Partition
+		assert.Equal(t, contracts.ContentType, msg.Headers[contracts.HeaderContentType])
+	}
+}
+
+func TestPublishRejectsInvalidEvents(t *testing.T) {
+	broker := membroker.New()
+
+	err := broker.Publisher("fraud-service").Publish(context.Background(), topic, contracts.FraudCheckEvent{ID: "payment-1", Status: "MAYBE"})
+
+	assert.ErrorIs(t, err, contracts.ErrInvalidEvent)
+	assert.Empty(t, broker.Messages(topic))
+}
+
+func TestTraceIDTravelsWithTheMessage(t *testing.T) {
+	broker := membroker.New()
+	publisher := broker.Publisher("wallet-service")
+
+	// WalletResponseEvent takes the trace ID of ctx when it has none.
+	ctx := contracts.WithTraceID(context.Background(), "trace-1")
+	require.NoError(t, publisher.Publish(ctx, contracts.EventTypeWalletFundsVerified, contracts.WalletResponseEvent{
+		PaymentID: "payment-1",
+		UserID:    "user-1",
+		Status:    contracts.WalletStatusApproved,
+		Amount:    10,
+	}))
+	require.Equal(t, "trace-1", broker.Messages(contracts.EventTypeWalletFundsVerified)[0].Headers[contracts.HeaderTraceID])
+
+	traceIDs := make(chan string, 1)
+	listen(t, broker.Consumer("payment-service", contracts.EventTypeWalletFundsVerified), func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		assert.Equal(t, "trace-1", envelope.TraceID)
+		traceIDs <- contracts.TraceID(ctx)
+		return nil
+	})
+
+	select {
+	case traceID := <-traceIDs:
+		assert.Equal(t, "trace-1", traceID)
+	case <-time.After(time.Second):
+		t.Fatal("message was not handled")
+	}
+}
+
+func TestListenKeepsOrderPerKey(t *testing.T) {
+	broker := membroker.New()
+	publisher := broker.Publisher("fraud-service")
+	for i := range 30 {
+		event := fraudCheck(fmt.Sprintf("payment-%d", i%3))
+		event.Reason = fmt.Sprint(i)
+		require.NoError(t, publisher.Publish(context.Background(), topic, event))
+	}
+
+	var mu sync.Mutex
+	got := map[string][]string{}
+	listen(t, broker.Consumer("payment-service", topic), func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		var event contracts.FraudCheckEvent
+		if err := envelope.Decode(&event); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		got[event.ID] = append(got[event.ID], event.Reason)
+		return nil
+	})
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got["payment-0"])+len(got["payment-1"])+len(got["payment-2"]) == 30
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	for key := range 3 {
+		var want []string
+		for i := key; i < 30; i += 3 {
+			want = append(want, fmt.Sprint(i))
+		}
+		assert.Equal(t, want, got[fmt.Sprintf("payment-%d", key)])
+	}
+}
+
+func TestConsumerGroups(t *testing.T) {
+	broker := membroker.New()
+	ids := []string{"payment-1", "payment-2", "payment-3", "payment-4", "payment-5", "payment-6"}
+
+	var first, second, metrics recorder
+	listen(t, broker.Consumer("payment-service", topic), first.handle)
+	listen(t, broker.Consumer("payment-service", topic), second.handle)
+	listen(t, broker.Consumer("metrics-service", topic), metrics.handle)
+	publish(t, broker, ids...)
+
+	require.Eventually(t, func() bool {
+		return len(first.handled())+len(second.handled()) == len(ids) && len(metrics.handled()) == len(ids)
+	}, time.Second, time.Millisecond)
+	assert.ElementsMatch(t, ids, append(first.handled(), second.handled()...), "the members of a group share the messages")
+	assert.ElementsMatch(t, ids, metrics.handled(), "every group gets every message")
+	assert.Equal(t, []int64{0, 0, 0}, broker.Committed("fraud-service", topic))
+}
+
+func TestListenRedeliversFailedMessages(t *testing.T) {
+	broker := membroker.New()
+	publish(t, broker, "payment-1")
+
+	var deliveries int
+	var mu sync.Mutex
+	listen(t, broker.Consumer("payment-service", topic), func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries++
+		if deliveries < 3 {
+			return errors.New("database connection timeout")
+		}
+		return nil
+	})
+
+	msg := broker.Messages(topic)[0]
+	require.Eventually(t, func() bool {
+		return broker.Committed("payment-service", topic)[msg.Partition] == msg.Offset+1
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, 3, deliveries)
+	mu.Unlock()
+	assert.Empty(t, broker.DeadLetters())
+}
+
+func TestListenDeadLettersMessages(t *testing.T) {
+	broker := membroker.New()
+	broker.MaxDeliveries = 2
+	errPermanent := errors.New("unknown payment")
+
+	publish(t, broker, "transient", "permanent")
+	_, err := broker.Produce(context.Background(), topic, []byte("malformed"), []byte("{"), nil)
+	require.NoError(t, err)
+
+	consumer := broker.Consumer("payment-service", topic)
+	consumer.IsPermanent = func(err error) bool { return errors.Is(err, errPermanent) }
+	listen(t, consumer, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		var event contracts.FraudCheckEvent
+		if err := envelope.Decode(&event); err != nil {
+			return err
+		}
+		if event.ID == "permanent" {
+			return errPermanent
+		}
+		return errors.New("database connection timeout")
+	})
+
+	require.Eventually(t, func() bool { return len(broker.DeadLetters()) == 3 }, time.Second, time.Millisecond)
+	deliveries := map[string]int{}
+	for _, deadLetter := range broker.DeadLetters() {
+		assert.Equal(t, "payment-service", deadLetter.GroupID)
+		assert.Error(t, deadLetter.Err)
+		deliveries[string(deadLetter.Message.Key)] = deadLetter.Deliveries
+	}
+	assert.Equal(t, map[string]int{"transient": 2, "permanent": 1, "malformed": 1}, deliveries)
+}
+
+func TestLeavingMemberHandsOverItsPartitions(t *testing.T) {
+	broker := membroker.New()
+
+	var first recorder
+	stopFirst := listen(t, broker.Consumer("payment-service", topic), first.handle)
+	publish(t, broker, "payment-1", "payment-2", "payment-3")
+	require.Eventually(t, func() bool { return len(first.handled()) == 3 }, time.Second, time.Millisecond)
+
+	var second recorder
+	listen(t, broker.Consumer("payment-service", topic), second.handle)
+	stopFirst()
+	publish(t, broker, "payment-4", "payment-5", "payment-6")
+
+	require.Eventually(t, func() bool { return len(second.handled()) == 3 }, time.Second, time.Millisecond)
+	assert.ElementsMatch(t, []string{"payment-4", "payment-5", "payment-6"}, second.handled(), "the new owner starts at the committed offsets")
+	assert.Len(t, first.handled(), 3)
+}
+
+func TestPublishWithProtobufSerializer(t *testing.T) {
+	broker := membroker.New()
+	publisher := broker.Publisher("fraud-service")
+	publisher.Serializer = contracts.Protobuf
+	require.NoError(t, publisher.Publish(context.Background(), topic, fraudCheck("payment-1")))
+
+	var got recorder
+	listen(t, broker.Consumer("payment-service", topic), got.handle)
+
+	require.Eventually(t, func() bool { return len(got.handled()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, contracts.ProtobufContentType, broker.Messages(topic)[0].Headers[contracts.HeaderContentType])
+	assert.Equal(t, []string{"payment-1"}, got.handled())
+}
@@ -0,0 +1,155 @@
+package membroker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+)
+
+// Consumer is a member of a consumer group. Each message of its topics is
+// handled by one member of the group; other groups get every message too.
+type Consumer struct {
+	Broker  *Broker
+	GroupID string
+	Topics  []string
+	// Serializers decode messages by their content-type header.
+	Serializers contracts.Serializers
+	// IsPermanent reports handler errors that redelivery cannot fix; such
+	// messages are dead-lettered at once. Nil means every error is retried.
+	IsPermanent func(error) bool
+
+	wg sync.WaitGroup
+}
+
+// Consumer returns a member of the consumer group groupID subscribed to topics.
+func (b *Broker) Consumer(groupID string, topics ...string) *Consumer {
+	return &Consumer{
+		Broker:      b,
+		GroupID:     groupID,
+		Topics:      topics,
+		Serializers: contracts.DefaultSerializers,
+	}
+}
+
+// Listen joins the group and hands the messages of the partitions assigned to
+// c to handler, decoded and validated, with at-least-once semantics: the
+// group's offset only moves past a message once handler returns nil or the
+// message is dead-lettered. A failed message is redelivered after
+// RedeliveryDelay, holding back the rest of its partition.
+//
+// Listen returns at once. When ctx is cancelled c leaves the group and its
+// partitions go to the remaining members, starting at the committed offsets.
+func (c *Consumer) Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	c.Broker.join(c)
+	context.AfterFunc(ctx, func() {
+		c.Broker.leave(c)
+	})
+
+	for _, topic := range c.Topics {
+		for partition := range c.Broker.partitionCount(topic) {
+			c.wg.Add(1)
+			go func() {
+				defer c.wg.Done()
+				c.consume(ctx, topic, partition, handler)
+			}()
+		}
+	}
+}
+
+// Close waits for the listeners to stop. The context given to Listen must be
+// cancelled first.
+func (c *Consumer) Close() error {
+	c.wg.Wait()
+	return nil
+}
+
+func (c *Consumer) subscribes(topic string) bool {
+	return slices.Contains(c.Topics, topic)
+}
+
+// consume handles the messages of one partition in order, while c owns it.
+func (c *Consumer) consume(ctx context.Context, topic string, partition int, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	deliveries := 0
+	lastOffset := int64(-1)
+	for {
+		msg, ok := c.Broker.fetch(ctx, c, topic, partition)
+		if !ok {
+			return
+		}
+		if msg.Offset != lastOffset {
+			deliveries, lastOffset = 0, msg.Offset
+		}
+		deliveries++
+
+		err := c.handle(ctx, msg, handler)
+		if err != nil && !c.permanent(err) && deliveries < c.Broker.MaxDeliveries {
+			if waitFor(ctx, c.Broker.RedeliveryDelay) != nil {
+				return
+			}
+			continue
+		}
+
+		// A rebalance hands the message to the partition's new owner.
+		if c.Broker.commit(c, msg) != nil {
+			continue
+		}
+		if err != nil {
+			c.Broker.deadLetter(DeadLetter{Message: msg, GroupID: c.GroupID, Deliveries: deliveries, Err: err})
+		}
+	}
+}
+
+// handle decodes msg and calls handler with the trace ID of msg in its context.
+func (c *Consumer) handle(ctx context.Context, msg Message, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) error {
+	serializer, err := c.Serializers.For(msg.Headers[contracts.HeaderContentType])
+	if err != nil {
+		return &decodeError{err}
+	}
+	envelope, err := serializer.Unmarshal(msg.Value)
+	if err != nil {
+		return &decodeError{err}
+	}
+	if err := envelope.Validate(); err != nil {
+		return &decodeError{err}
+	}
+
+	traceID := contracts.MessageTraceID(msg.Headers[contracts.HeaderTraceID], envelope)
+	return handler(contracts.WithTraceID(ctx, traceID), msg.Topic, envelope)
+}
+
+// permanent reports whether err is not worth a redelivery: messages that
+// cannot be decoded, and the errors IsPermanent reports.
+func (c *Consumer) permanent(err error) bool {
+	var decodeErr *decodeError
+	return errors.As(err, &decodeErr) || (c.IsPermanent != nil && c.IsPermanent(err))
+}
+
+// decodeError is a message that could not be decoded or validated.
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("error decoding message: %s", e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+func waitFor(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
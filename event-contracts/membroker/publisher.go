@@ -0,0 +1,85 @@
+package membroker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+)
+
+// Keyed is implemented by events that carry a partition key. Messages with
+// the same key go to the same partition and are consumed in order.
+type Keyed interface {
+	MessageKey() string
+}
+
+// Publisher publishes to the broker the way the services' Kafka publishers
+// do, so it can stand in for them behind their Publisher interface.
+type Publisher struct {
+	Broker *Broker
+	// Source is the CloudEvents source of the published events.
+	Source string
+	// Serializer encodes events; JSON unless set otherwise.
+	Serializer contracts.Serializer
+}
+
+// Publisher returns a Publisher for the service source.
+func (b *Broker) Publisher(source string) *Publisher {
+	return &Publisher{
+		Broker:     b,
+		Source:     source,
+		Serializer: contracts.JSON,
+	}
+}
+
+// Publish writes message to topic, keyed when it implements Keyed. Events
+// (see contracts.Event) are wrapped in an envelope, must match their JSON
+// Schema and are encoded by Serializer; other messages are plain JSON. The
+// trace ID of the event, or else of ctx, goes in the trace header.
+func (p *Publisher) Publish(ctx context.Context, topic string, message interface{}) error {
+	headers := map[string]string{}
+	if traceID := contracts.TraceID(ctx); traceID != "" {
+		headers[contracts.HeaderTraceID] = traceID
+	}
+	var key []byte
+	if keyed, ok := message.(Keyed); ok {
+		key = []byte(keyed.MessageKey())
+	}
+
+	event, ok := message.(contracts.Event)
+	if !ok {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("error marshaling message: %w", err)
+		}
+		_, err = p.Broker.Produce(ctx, topic, key, data, headers)
+		return err
+	}
+
+	envelope, err := contracts.New(p.Source, event)
+	if err != nil {
+		return err
+	}
+	if envelope.TraceID == "" {
+		envelope.TraceID = contracts.TraceID(ctx)
+	}
+	if envelope.TraceID != "" {
+		headers[contracts.HeaderTraceID] = envelope.TraceID
+	}
+	if err := envelope.Validate(); err != nil {
+		return fmt.Errorf("error publishing %s event: %w", event.EventType(), err)
+	}
+	serializer := p.Serializer
+	if serializer == nil {
+		serializer = contracts.JSON
+	}
+	data, err := serializer.Marshal(envelope, event)
+	if err != nil {
+		return fmt.Errorf("error marshaling %s event: %w", event.EventType(), err)
+	}
+
+	headers[contracts.HeaderContentType] = serializer.ContentType()
+	_, err = p.Broker.Produce(ctx, topic, key, data, headers)
+	return err
+}
@@ -0,0 +1,213 @@
+package contracts
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:embed schemas
+var schemaFiles embed.FS
+
+const envelopeSchema = "schemas/envelope.json"
+
+var (
+	ErrInvalidEvent     = errors.New("event does not match its schema")
+	ErrUnknownEventType = errors.New("unknown event type")
+)
+
+// registry holds the compiled schemas: the envelope and, per event type,
+// every published version.
+type registry struct {
+	envelope *jsonschema.Schema
+	events   map[string]map[string]*jsonschema.Schema
+}
+
+var loadRegistry = sync.OnceValues(compileSchemas)
+
+// ValidateEnvelope validates an enveloped message: the envelope itself and
+// its data against the schema named by its dataschema.
+func ValidateEnvelope(raw []byte) error {
+	reg, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	if err := reg.envelope.Validate(instance); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return Validate(envelope.Type, envelope.Version(), envelope.Data)
+}
+
+// Validate validates the envelope and its data, whatever format it was
+// read from (see Serializer.Unmarshal).
+func (e Envelope) Validate() error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return ValidateEnvelope(raw)
+}
+
+// ValidateEvent validates event against the schema of its type and version.
+func ValidateEvent(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return Validate(event.EventType(), event.EventVersion(), data)
+}
+
+// Validate validates the data of an event of eventType. A version without its
+// own schema is validated against the latest known minor of its major: newer
+// minors only add optional fields, so older consumers can still check them.
+func Validate(eventType, version string, data []byte) error {
+	reg, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+
+	versions, ok := reg.events[eventType]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+	schema := versions[version]
+	if schema == nil {
+		schema = latestMinor(versions, version)
+	}
+	if schema == nil {
+		return fmt.Errorf("%w: %s %s", ErrUnsupportedVersion, eventType, version)
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %s %s: %w", ErrInvalidEvent, eventType, version, err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		return fmt.Errorf("%w: %s %s: %w", ErrInvalidEvent, eventType, version, err)
+	}
+	return nil
+}
+
+// Versions returns the schema versions known for eventType.
+func Versions(eventType string) []string {
+	reg, err := loadRegistry()
+	if err != nil {
+		return nil
+	}
+
+	var versions []string
+	for version := range reg.events[eventType] {
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+func latestMinor(versions map[string]*jsonschema.Schema, version string) *jsonschema.Schema {
+	wantMajor, _, ok := parseVersion(version)
+	if !ok {
+		return nil
+	}
+
+	var latest *jsonschema.Schema
+	latestMinor := -1
+	for v, schema := range versions {
+		major, minor, ok := parseVersion(v)
+		if ok && major == wantMajor && minor > latestMinor {
+			latest, latestMinor = schema, minor
+		}
+	}
+	return latest
+}
+
+func parseVersion(version string) (major, minor int, ok bool) {
+	majorText, minorText, found := strings.Cut(version, ".")
+	if !found {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(majorText)
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err = strconv.Atoi(minorText)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// compileSchemas compiles schemas/envelope.json and every
+// schemas/<type>/<version>.json.
+func compileSchemas() (*registry, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+
+	var files []string
+	err := fs.WalkDir(schemaFiles, "schemas", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := schemaFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", name, err)
+		}
+		if err := compiler.AddResource(schemaURL(name), doc); err != nil {
+			return err
+		}
+		files = append(files, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reg := &registry{events: make(map[string]map[string]*jsonschema.Schema)}
+	for _, name := range files {
+		schema, err := compiler.Compile(schemaURL(name))
+		if err != nil {
+			return nil, fmt.Errorf("error compiling %s: %w", name, err)
+		}
+
+		if name == envelopeSchema {
+			reg.envelope = schema
+			continue
+		}
+		eventType := path.Base(path.Dir(name))
+		version := strings.TrimSuffix(path.Base(name), ".json")
+		if reg.events[eventType] == nil {
+			reg.events[eventType] = make(map[string]*jsonschema.Schema)
+		}
+		reg.events[eventType][version] = schema
+	}
+	if reg.envelope == nil {
+		return nil, fmt.Errorf("missing %s", envelopeSchema)
+	}
+
+	return reg, nil
+}
+
+func schemaURL(name string) string {
+	return "https://draftea.local/" + name
+}
@@ -0,0 +1,243 @@
+package contracts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jeffleon2/draftea-event-contracts/eventspb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProtobufContentType is the Kafka content-type header of protobuf encoded
+// envelopes (see eventspb.Envelope).
+const ProtobufContentType = "application/cloudevents+protobuf"
+
+const protobufDataContentType = "application/protobuf"
+
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// Serializer encodes envelopes for Kafka. Messages carry its ContentType in
+// the content-type header so consumers can pick the matching Serializer.
+type Serializer interface {
+	ContentType() string
+	// Marshal encodes envelope, built by New for event.
+	Marshal(envelope Envelope, event Event) ([]byte, error)
+	// Unmarshal decodes a message into an envelope with JSON data, so
+	// consumers validate and decode every format the same way.
+	Unmarshal(raw []byte) (Envelope, error)
+}
+
+var (
+	// JSON writes the envelope as CloudEvents JSON.
+	JSON Serializer = jsonSerializer{}
+	// Protobuf writes the envelope and its data as protobuf messages.
+	Protobuf Serializer = protobufSerializer{}
+)
+
+// Serializers picks a Serializer by content type.
+type Serializers []Serializer
+
+// DefaultSerializers are the formats consumers accept: JSON and protobuf.
+var DefaultSerializers = Serializers{JSON, Protobuf}
+
+// For returns the serializer of contentType. Messages without a content-type
+// header were published before it existed and are JSON.
+func (s Serializers) For(contentType string) (Serializer, error) {
+	if contentType == "" {
+		contentType = ContentType
+	}
+	for _, serializer := range s {
+		if serializer.ContentType() == contentType {
+			return serializer, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) ContentType() string {
+	return ContentType
+}
+
+func (jsonSerializer) Marshal(envelope Envelope, _ Event) ([]byte, error) {
+	return json.Marshal(envelope)
+}
+
+func (jsonSerializer) Unmarshal(raw []byte) (Envelope, error) {
+	return Parse(raw)
+}
+
+type protobufSerializer struct{}
+
+func (protobufSerializer) ContentType() string {
+	return ProtobufContentType
+}
+
+func (protobufSerializer) Marshal(envelope Envelope, event Event) ([]byte, error) {
+	message, err := toProto(event)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling %s event: %w", event.EventType(), err)
+	}
+
+	return proto.Marshal(&eventspb.Envelope{
+		Id:              envelope.ID,
+		Type:            envelope.Type,
+		Source:          envelope.Source,
+		SpecVersion:     envelope.SpecVersion,
+		Time:            timestamppb.New(envelope.Time),
+		TraceId:         envelope.TraceID,
+		DataSchema:      envelope.DataSchema,
+		DataContentType: protobufDataContentType,
+		Data:            data,
+	})
+}
+
+func (protobufSerializer) Unmarshal(raw []byte) (Envelope, error) {
+	var message eventspb.Envelope
+	if err := proto.Unmarshal(raw, &message); err != nil {
+		return Envelope{}, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	if message.GetSpecVersion() != SpecVersion || message.GetType() == "" {
+		return Envelope{}, fmt.Errorf("%w: specversion %q, type %q", ErrInvalidEnvelope, message.GetSpecVersion(), message.GetType())
+	}
+	if message.GetDataContentType() != protobufDataContentType {
+		return Envelope{}, fmt.Errorf("%w: datacontenttype %q", ErrInvalidEnvelope, message.GetDataContentType())
+	}
+
+	event, err := fromProto(message.GetType(), message.GetData())
+	if err != nil {
+		return Envelope{}, err
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("error marshaling %s event: %w", message.GetType(), err)
+	}
+
+	return Envelope{
+		ID:              message.GetId(),
+		Type:            message.GetType(),
+		Source:          message.GetSource(),
+		SpecVersion:     message.GetSpecVersion(),
+		Time:            message.GetTime().AsTime(),
+		TraceID:         message.GetTraceId(),
+		DataSchema:      message.GetDataSchema(),
+		DataContentType: dataContentType,
+		Data:            data,
+	}, nil
+}
+
+func toProto(event Event) (proto.Message, error) {
+	switch e := event.(type) {
+	case PaymentCreatedEvent:
+		return &eventspb.PaymentCreated{
+			Id:              e.ID,
+			Amount:          e.Amount,
+			Currency:        e.Currency,
+			Status:          e.Status,
+			Method:          e.Method,
+			CustomerId:      e.CustomerID,
+			CustomerName:    e.CustomerName,
+			CustomerCountry: e.CustomerCountry,
+			TraceId:         e.TraceID,
+			CreatedAt:       timestamppb.New(e.CreatedAt),
+		}, nil
+	case FraudCheckEvent:
+		return &eventspb.PaymentChecked{
+			Id:        e.ID,
+			TraceId:   e.TraceID,
+			Status:    e.Status,
+			Reason:    e.Reason,
+			CheckedAt: timestamppb.New(e.CheckedAt),
+		}, nil
+	case WalletResponseEvent:
+		return &eventspb.WalletFundsVerified{
+			PaymentId: e.PaymentID,
+			UserId:    e.UserID,
+			Status:    string(e.Status),
+			Amount:    e.Amount,
+			Reason:    e.Reason,
+			TraceId:   e.TraceID,
+		}, nil
+	case WalletDebitRequestedEvent:
+		return &eventspb.WalletDebitRequested{
+			PaymentId: e.PaymentID,
+			UserId:    e.UserID,
+			Amount:    e.Amount,
+			Reason:    e.Reason,
+			TraceId:   e.TraceID,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: no protobuf message for %s", ErrUnknownEventType, event.EventType())
+	}
+}
+
+// fromProto decodes the protobuf data of an eventType envelope. Fields added
+// by newer minor versions are skipped by proto.Unmarshal, as they are by
+// json.Unmarshal.
+func fromProto(eventType string, data []byte) (Event, error) {
+	switch eventType {
+	case EventTypePaymentCreated:
+		var m eventspb.PaymentCreated
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("error unmarshaling %s event: %w", eventType, err)
+		}
+		return PaymentCreatedEvent{
+			ID:              m.GetId(),
+			Amount:          m.GetAmount(),
+			Currency:        m.GetCurrency(),
+			Status:          m.GetStatus(),
+			Method:          m.GetMethod(),
+			CustomerID:      m.GetCustomerId(),
+			CustomerName:    m.GetCustomerName(),
+			CustomerCountry: m.GetCustomerCountry(),
+			TraceID:         m.GetTraceId(),
+			CreatedAt:       m.GetCreatedAt().AsTime(),
+		}, nil
+	case EventTypePaymentChecked:
+		var m eventspb.PaymentChecked
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("error unmarshaling %s event: %w", eventType, err)
+		}
+		return FraudCheckEvent{
+			ID:        m.GetId(),
+			TraceID:   m.GetTraceId(),
+			Status:    m.GetStatus(),
+			Reason:    m.GetReason(),
+			CheckedAt: m.GetCheckedAt().AsTime(),
+		}, nil
+	case EventTypeWalletFundsVerified:
+		var m eventspb.WalletFundsVerified
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("error unmarshaling %s event: %w", eventType, err)
+		}
+		return WalletResponseEvent{
+			PaymentID: m.GetPaymentId(),
+			UserID:    m.GetUserId(),
+			Status:    WalletStatus(m.GetStatus()),
+			Amount:    m.GetAmount(),
+			Reason:    m.GetReason(),
+			TraceID:   m.GetTraceId(),
+		}, nil
+	case EventTypeWalletDebitRequested:
+		var m eventspb.WalletDebitRequested
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("error unmarshaling %s event: %w", eventType, err)
+		}
+		return WalletDebitRequestedEvent{
+			PaymentID: m.GetPaymentId(),
+			UserID:    m.GetUserId(),
+			Amount:    m.GetAmount(),
+			Reason:    m.GetReason(),
+			TraceID:   m.GetTraceId(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+}
@@ -0,0 +1,105 @@
+package contracts_test
+
+import (
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-event-contracts/eventspb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSerializersRoundTrip(t *testing.T) {
+	event := contracts.PaymentCreatedEvent{
+		ID:         "payment-123",
+		Amount:     100.5,
+		Currency:   "USD",
+		Status:     "PENDING",
+		Method:     "card",
+		CustomerID: "user-1",
+		TraceID:    "trace-123",
+		CreatedAt:  time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+
+	for _, serializer := range contracts.DefaultSerializers {
+		t.Run(serializer.ContentType(), func(t *testing.T) {
+			env, err := contracts.New(source, event)
+			require.NoError(t, err)
+
+			raw, err := serializer.Marshal(env, event)
+			require.NoError(t, err)
+			decodedEnv, err := serializer.Unmarshal(raw)
+			require.NoError(t, err)
+
+			assert.NoError(t, decodedEnv.Validate())
+			assert.Equal(t, env.ID, decodedEnv.ID)
+			assert.Equal(t, env.TraceID, decodedEnv.TraceID)
+			assert.Equal(t, "1.0", decodedEnv.Version())
+			assert.True(t, env.Time.Equal(decodedEnv.Time))
+
+			var decoded contracts.PaymentCreatedEvent
+			require.NoError(t, decodedEnv.Decode(&decoded))
+			assert.Equal(t, event, decoded)
+		})
+	}
+}
+
+func TestProtobufRoundTripsEveryEvent(t *testing.T) {
+	for _, event := range contractEvents {
+		t.Run(event.EventType(), func(t *testing.T) {
+			env, err := contracts.New(source, event)
+			require.NoError(t, err)
+
+			raw, err := contracts.Protobuf.Marshal(env, event)
+			require.NoError(t, err)
+			decodedEnv, err := contracts.Protobuf.Unmarshal(raw)
+			require.NoError(t, err)
+
+			assert.Equal(t, event.EventType(), decodedEnv.Type)
+			assert.JSONEq(t, string(env.Data), string(decodedEnv.Data))
+		})
+	}
+}
+
+func TestProtobufIsSmallerThanJSON(t *testing.T) {
+	event := contracts.WalletDebitRequestedEvent{PaymentID: "payment-123", UserID: "user-1", Amount: 50, TraceID: "trace-123"}
+	env, err := contracts.New(source, event)
+	require.NoError(t, err)
+
+	jsonRaw, err := contracts.JSON.Marshal(env, event)
+	require.NoError(t, err)
+	protoRaw, err := contracts.Protobuf.Marshal(env, event)
+	require.NoError(t, err)
+
+	assert.Less(t, len(protoRaw), len(jsonRaw))
+}
+
+func TestProtobufUnmarshalRejectsUnknownType(t *testing.T) {
+	raw, err := proto.Marshal(&eventspb.Envelope{Type: "payments.refunded", SpecVersion: contracts.SpecVersion, DataContentType: "application/protobuf"})
+	require.NoError(t, err)
+
+	_, err = contracts.Protobuf.Unmarshal(raw)
+
+	assert.ErrorIs(t, err, contracts.ErrUnknownEventType)
+}
+
+func TestProtobufUnmarshalRejectsJSON(t *testing.T) {
+	_, err := contracts.Protobuf.Unmarshal(marshal(t, contracts.FraudCheckEvent{ID: "payment-123"}, ""))
+
+	assert.ErrorIs(t, err, contracts.ErrInvalidEnvelope)
+}
+
+func TestSerializersFor(t *testing.T) {
+	serializer, err := contracts.DefaultSerializers.For(contracts.ProtobufContentType)
+	require.NoError(t, err)
+	assert.Equal(t, contracts.Protobuf, serializer)
+
+	serializer, err = contracts.DefaultSerializers.For("")
+	require.NoError(t, err)
+	assert.Equal(t, contracts.JSON, serializer, "messages without content-type are JSON")
+
+	_, err = contracts.DefaultSerializers.For("application/avro")
+	assert.ErrorIs(t, err, contracts.ErrUnsupportedContentType)
+}
@@ -0,0 +1,43 @@
+package contracts
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// HeaderTraceID is the message header carrying the trace ID of a payment
+// flow. It is set from the envelope's traceid, or from the context for
+// messages without an envelope such as DLQ messages.
+const HeaderTraceID = "x-trace-id"
+
+type traceIDKey struct{}
+
+// NewTraceID returns a new random trace ID.
+func NewTraceID() string {
+	return uuid.NewString()
+}
+
+// WithTraceID returns a copy of ctx carrying traceID. An empty traceID
+// leaves ctx unchanged.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	if traceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceID returns the trace ID carried by ctx, or "".
+func TraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
+
+// MessageTraceID returns the trace ID of a consumed message: its trace
+// header or, for producers that do not set it, the envelope's traceid.
+func MessageTraceID(header string, envelope Envelope) string {
+	if header != "" {
+		return header
+	}
+	return envelope.TraceID
+}
@@ -3,17 +3,33 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"net"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 
+	"github.com/gin-gonic/gin"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-fraud-service/config"
+	"github.com/jeffleon2/draftea-fraud-service/fraudpb"
+	"github.com/jeffleon2/draftea-fraud-service/internal/breaker"
 	"github.com/jeffleon2/draftea-fraud-service/internal/handler"
+	"github.com/jeffleon2/draftea-fraud-service/internal/health"
+	"github.com/jeffleon2/draftea-fraud-service/internal/logging"
+	"github.com/jeffleon2/draftea-fraud-service/internal/metrics"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
 	"github.com/jeffleon2/draftea-fraud-service/internal/publisher"
+	"github.com/jeffleon2/draftea-fraud-service/internal/repository/posgrest"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
+	"github.com/jeffleon2/draftea-fraud-service/internal/screening"
 	"github.com/jeffleon2/draftea-fraud-service/internal/service"
 	"github.com/jeffleon2/draftea-fraud-service/internal/subscriber"
+	"github.com/jeffleon2/draftea-fraud-service/internal/tracing"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -22,31 +38,188 @@ func main() {
 
 	cfg, err := config.New()
 	if err != nil {
-		fmt.Println("Error reading config file", err)
-		os.Exit(1)
+		logrus.Fatalf("Error reading config file: %v", err)
 	}
+	if err := logging.Setup(cfg.Logging); err != nil {
+		logrus.Fatalf("Error setting up logging: %v", err)
+	}
+	checker := health.NewChecker(cfg.APP.HealthCheckTimeout)
 
-	brokers := strings.Split(cfg.Kafka.Brokers, ",")
-	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
-	subscriberTopic := strings.Split(cfg.Kafka.SubscriberTopics, ",")
-	publishers := publisher.NewKafkaPublisher(brokers[0], publishTopics, cfg.Kafka.GetRetryConfig())
-	multiConsumer := subscriber.NewMultiTopicConsumer(brokers, subscriberTopic, cfg.Kafka.PaymentConsumerGroup, publishers, cfg.Kafka.GetRetryConfig())
-	fraudService := service.NewFraudService(publishers)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		logrus.Fatalf("Error setting up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	db, err := cfg.DB.GormConnect()
+	if err != nil {
+		logrus.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		logrus.Fatalf("failed to instrument database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.FraudDecision{}); err != nil {
+		logrus.Fatalf("failed to auto migrate: %v", err)
+	}
+	checker.Critical("postgres", health.Database(db))
+
+	publisherConfig, err := cfg.Kafka.GetPublisherConfig()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	messageTransport, err := cfg.APP.MessageTransport()
+	if err != nil {
+		logrus.Fatalf("invalid configuration: %v", err)
+	}
+	var (
+		publishers    service.Publisher
+		multiConsumer eventConsumer
+	)
+	switch messageTransport {
+	case config.TransportNATS:
+		publishers, multiConsumer = connectNATS(ctx, cfg, publisherConfig, checker)
+	default:
+		publishers, multiConsumer = connectKafka(ctx, cfg, publisherConfig, checker)
+	}
+	ruleSet := rules.Default(cfg.Fraud.RuleSetVersion, cfg.Fraud.HighValueThreshold)
+	if cfg.Fraud.RulesFile != "" {
+		ruleSet, err = rules.Load(cfg.Fraud.RulesFile)
+		if err != nil {
+			logrus.Fatalf("failed to load fraud rules: %v", err)
+		}
+	}
+
+	metrics.RegisterMetrics()
+
+	var screeningHandler *handler.ScreeningHandler
+	if cfg.Fraud.SanctionsFile != "" {
+		screener, err := screening.NewScreener(cfg.Fraud.SanctionsFile, cfg.Fraud.SanctionsDeclineScore, cfg.Fraud.SanctionsReviewScore)
+		if err != nil {
+			logrus.Fatalf("failed to load sanctions list: %v", err)
+		}
+		info := screener.ListInfo()
+		logrus.Infof("Loaded sanctions list %s (%d entries)", info.Version, info.Entries)
+
+		ruleSet.Rules = append([]rules.Rule{screening.NewRule(screener)}, ruleSet.Rules...)
+		screeningHandler = handler.Screening(screener)
+		if cfg.Fraud.SanctionsReloadInterval > 0 {
+			go screener.Watch(ctx, cfg.Fraud.SanctionsReloadInterval)
+		}
+	}
+	logrus.Infof("Loaded fraud rule set %s (%d live, %d shadow rules)", ruleSet.Version, len(ruleSet.Rules), len(ruleSet.Shadow))
+
+	dbBreaker := breaker.New("postgres", cfg.CircuitBreaker)
+	dbBreaker.IsFailure = posgrest.IsFailure
+	decisionRepo := posgrest.NewDecisionRepository(db, dbBreaker)
+	fraudService := service.NewFraudService(publishers, ruleSet, decisionRepo)
 	FraudHandler := handler.Fraud(fraudService)
+	auditHandler := handler.Audit(fraudService)
+	rulesHandler := handler.Rules(fraudService)
+	preCheckHandler := handler.PreCheck(fraudService)
+
+	router := gin.New()
+	router.Use(gin.Recovery(), otelgin.Middleware(models.ServiceName, otelgin.WithGinFilter(handler.ObservedRoute)), logging.Requests(handler.ObservedRoute))
+	handler.RegisterRoutes(router, checker, auditHandler, rulesHandler, preCheckHandler, screeningHandler)
+	go func() {
+		if err := router.Run(fmt.Sprintf(":%s", cfg.APP.PORT)); err != nil {
+			logrus.Fatalf("failed to start http server: %v", err)
+		}
+	}()
+
+	var grpcServer *grpc.Server
+	if cfg.APP.GRPCPort != "" {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.APP.GRPCPort))
+		if err != nil {
+			logrus.Fatalf("failed to listen for gRPC: %v", err)
+		}
+		grpcServer = grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+		fraudpb.RegisterPreCheckServer(grpcServer, handler.PreCheckServer(fraudService))
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logrus.Fatalf("failed to start gRPC server: %v", err)
+			}
+		}()
+	}
 
-	multiConsumer.Listen(ctx, func(topic string, value []byte) error {
-		log.Printf("📩 Received event → topic=%s value=%s\n", topic, string(value))
-		FraudHandler.Handler(ctx, value)
-		return nil
+	multiConsumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		logging.FromContext(ctx).Infof("Received %s event %s", envelope.Type, envelope.ID)
+		return FraudHandler.Handler(ctx, envelope)
 	})
+	checker.Ready()
 
 	<-ctx.Done()
+	checker.Stopping()
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
 
-	for _, reader := range multiConsumer.Readers {
-		if err := reader.Close(); err != nil {
-			log.Println("Error closing consumer:", err)
-		}
+	if err := multiConsumer.Close(); err != nil {
+		logrus.WithError(err).Error("Error closing consumer")
+	}
+
+	logrus.Info("Fraud service stopped")
+}
+
+// eventConsumer is the multi-topic consumer of the configured transport.
+type eventConsumer interface {
+	Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error)
+	Close() error
+}
+
+// connectKafka connects to Kafka and returns its publisher and the consumer
+// of the subscribed topics, whose checks it registers in checker.
+func connectKafka(ctx context.Context, cfg *config.Config, publisherConfig config.PublisherConfig, checker *health.Checker) (*publisher.KafkaPublisher, *subscriber.KafkaConsumer) {
+	dialer, err := cfg.Kafka.Dialer()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	transport, err := cfg.Kafka.Transport()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	if err := cfg.Kafka.CheckConnection(ctx, dialer); err != nil {
+		logrus.Fatalf("failed to connect to Kafka: %v", err)
 	}
 
-	log.Println("Fraud service stopped")
+	brokers := cfg.Kafka.BrokerList()
+	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
+	publishTopics = append(publishTopics, cfg.Kafka.GetRetryConfig().RetryTopics()...)
+	subscriberTopics := strings.Split(cfg.Kafka.SubscriberTopics, ",")
+	publisherConfig.SyncTopics = append(cfg.Kafka.GetRetryConfig().RetryTopics(), models.PaymentsDLQTopic, models.TopicPaymentChecked)
+	publishers := publisher.NewKafkaPublisher(brokers, transport, publishTopics, cfg.Kafka.GetRetryConfig(), publisherConfig, breaker.New("kafka", cfg.CircuitBreaker))
+	multiConsumer := subscriber.NewMultiTopicConsumer(brokers, dialer, subscriberTopics, cfg.Kafka.PaymentConsumerGroup, publishers, cfg.Kafka.GetRetryConfig(), cfg.Kafka.GetCommitConfig(), cfg.Kafka.ConsumerWorkers)
+
+	checker.Critical("kafka", func(ctx context.Context) error {
+		return cfg.Kafka.CheckConnection(ctx, dialer)
+	})
+	checker.Optional("kafka_consumer_group", func(ctx context.Context) error {
+		return cfg.Kafka.CheckConsumerGroup(ctx, transport, cfg.Kafka.PaymentConsumerGroup, multiConsumer.Topics())
+	})
+	return publishers, multiConsumer
+}
+
+// connectNATS connects to NATS, creates the streams of every topic the
+// service publishes or consumes and returns the JetStream publisher and
+// consumer, registering the NATS check in checker. Topics, consumer group and
+// retry delays are the KAFKA_* settings.
+func connectNATS(ctx context.Context, cfg *config.Config, publisherConfig config.PublisherConfig, checker *health.Checker) (*publisher.NATSPublisher, *subscriber.NATSConsumer) {
+	conn, js, err := cfg.NATS.Connect()
+	if err != nil {
+		logrus.Fatalf("failed to connect to NATS: %v", err)
+	}
+	checker.Critical("nats", health.NATS(conn))
+
+	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
+	subscriberTopics := strings.Split(cfg.Kafka.SubscriberTopics, ",")
+	if err := cfg.NATS.EnsureStreams(ctx, js, append(publishTopics, subscriberTopics...)); err != nil {
+		logrus.Fatalf("failed to create NATS streams: %v", err)
+	}
+
+	publishers := publisher.NewNATSPublisher(js, cfg.Kafka.GetRetryConfig(), publisherConfig, breaker.New("nats", cfg.CircuitBreaker))
+	multiConsumer, err := subscriber.NewNATSConsumer(ctx, js, subscriberTopics, cfg.Kafka.PaymentConsumerGroup, publishers, cfg.Kafka.GetRetryConfig(), cfg.NATS, cfg.Kafka.ConsumerWorkers)
+	if err != nil {
+		logrus.Fatalf("failed to create NATS consumers: %v", err)
+	}
+	return publishers, multiConsumer
 }
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func (db *DB) GormConnect() (*gorm.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		db.HOST, db.USER, db.PASSWORD, db.NAME, db.PORT, db.SSLMODE,
+	)
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger()})
+}
+
+// gormLogger writes the slow queries and the errors of GORM to the service
+// logger, with placeholders instead of the values of the queries.
+func gormLogger() logger.Interface {
+	return logger.New(gormWriter{}, logger.Config{
+		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  logger.Warn,
+		IgnoreRecordNotFoundError: true,
+		ParameterizedQueries:      true,
+	})
+}
+
+// gormWriter writes the entries of gormLogger, all slow queries or errors, as
+// warnings.
+type gormWriter struct{}
+
+func (gormWriter) Printf(format string, args ...any) {
+	logrus.Warnf(format, args...)
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"github.com/sirupsen/logrus"
+)
+
+// Supported values of KAFKA_SASL_MECHANISM.
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+const kafkaDialTimeout = 10 * time.Second
+
+// PublisherConfig tunes the Kafka writers of the publisher.
+type PublisherConfig struct {
+	BatchSize    int
+	BatchTimeout time.Duration
+	Compression  kafka.Compression
+	RequiredAcks kafka.RequiredAcks
+	// Async makes Publish return before the broker acknowledges the message;
+	// delivery is reported to the completion callback instead.
+	Async bool
+	// Serializer encodes published events, JSON or protobuf.
+	Serializer contracts.Serializer
+	// SyncTopics are always written synchronously, whatever Async says:
+	// the consumer must know a retry, DLQ or saga publish succeeded before
+	// it commits the offset of the message that caused it.
+	SyncTopics []string
+}
+
+// GetPublisherConfig parses the KAFKA_PUBLISH_* settings.
+func (k Kafka) GetPublisherConfig() (PublisherConfig, error) {
+	compression, err := parseCompression(k.PublishCompression)
+	if err != nil {
+		return PublisherConfig{}, err
+	}
+	serializer, err := parseSerializer(k.PublishFormat)
+	if err != nil {
+		return PublisherConfig{}, err
+	}
+
+	return PublisherConfig{
+		BatchSize:    k.PublishBatchSize,
+		BatchTimeout: k.PublishBatchTimeout,
+		Compression:  compression,
+		RequiredAcks: k.PublishRequiredAcks,
+		Async:        k.PublishAsync,
+		Serializer:   serializer,
+	}, nil
+}
+
+func parseCompression(name string) (kafka.Compression, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("unsupported KAFKA_PUBLISH_COMPRESSION %q (use none, gzip, snappy, lz4 or zstd)", name)
+	}
+}
+
+func parseSerializer(name string) (contracts.Serializer, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "json":
+		return contracts.JSON, nil
+	case "protobuf":
+		return contracts.Protobuf, nil
+	default:
+		return nil, fmt.Errorf("unsupported KAFKA_PUBLISH_FORMAT %q (use json or protobuf)", name)
+	}
+}
+
+// BrokerList returns every configured broker, trimmed and without empty entries.
+func (k Kafka) BrokerList() []string {
+	var brokers []string
+	for _, broker := range strings.Split(k.Brokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	return brokers
+}
+
+// Dialer returns the dialer used by readers and direct broker connections,
+// with the configured TLS and SASL settings.
+func (k Kafka) Dialer() (*kafka.Dialer, error) {
+	tlsConfig, mechanism, err := k.security()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		Timeout:       kafkaDialTimeout,
+		DualStack:     true,
+		TLS:           tlsConfig,
+		SASLMechanism: mechanism,
+	}, nil
+}
+
+// Transport returns the transport used by writers, with the configured TLS
+// and SASL settings.
+func (k Kafka) Transport() (*kafka.Transport, error) {
+	tlsConfig, mechanism, err := k.security()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Transport{
+		DialTimeout: kafkaDialTimeout,
+		TLS:         tlsConfig,
+		SASL:        mechanism,
+	}, nil
+}
+
+// CheckConnection dials every broker with dialer and fails unless at least
+// one of them accepts the connection, TLS handshake and SASL authentication.
+// Brokers that cannot be reached while others can are logged as warnings.
+func (k Kafka) CheckConnection(ctx context.Context, dialer *kafka.Dialer) error {
+	brokers := k.BrokerList()
+	if len(brokers) == 0 {
+		return errors.New("KAFKA_BROKERS is empty")
+	}
+
+	var errs []error
+	for _, broker := range brokers {
+		if err := checkBroker(ctx, dialer, broker); err != nil {
+			errs = append(errs, fmt.Errorf("broker %s: %w", broker, err))
+		}
+	}
+
+	if len(errs) == len(brokers) {
+		return fmt.Errorf("no Kafka broker reachable: %w", errors.Join(errs...))
+	}
+	for _, err := range errs {
+		logrus.Warnf("Kafka %v", err)
+	}
+	return nil
+}
+
+func checkBroker(ctx context.Context, dialer *kafka.Dialer, broker string) error {
+	conn, err := dialer.DialContext(ctx, "tcp", broker)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Brokers()
+	return err
+}
+
+// stableGroupState is the state of a consumer group that is not rebalancing.
+const stableGroupState = "Stable"
+
+// CheckConsumerGroup fails unless groupID is a stable consumer group in which
+// every one of topics is assigned to a member.
+func (k Kafka) CheckConsumerGroup(ctx context.Context, transport *kafka.Transport, groupID string, topics []string) error {
+	client := &kafka.Client{Addr: kafka.TCP(k.BrokerList()...), Transport: transport}
+	resp, err := client.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{GroupIDs: []string{groupID}})
+	if err != nil {
+		return err
+	}
+	if len(resp.Groups) == 0 {
+		return fmt.Errorf("consumer group %s not found", groupID)
+	}
+	group := resp.Groups[0]
+	if group.Error != nil {
+		return group.Error
+	}
+	if group.GroupState != stableGroupState {
+		return fmt.Errorf("consumer group %s is %s", groupID, group.GroupState)
+	}
+
+	assigned := make(map[string]bool)
+	for _, member := range group.Members {
+		for _, topic := range member.MemberAssignments.Topics {
+			assigned[topic.Topic] = true
+		}
+	}
+	var unassigned []string
+	for _, topic := range topics {
+		if !assigned[topic] {
+			unassigned = append(unassigned, topic)
+		}
+	}
+	if len(unassigned) > 0 {
+		return fmt.Errorf("no member of consumer group %s is assigned %s", groupID, strings.Join(unassigned, ", "))
+	}
+	return nil
+}
+
+func (k Kafka) security() (*tls.Config, sasl.Mechanism, error) {
+	tlsConfig, err := k.tlsConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mechanism, err := k.saslMechanism()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tlsConfig, mechanism, nil
+}
+
+func (k Kafka) tlsConfig() (*tls.Config, error) {
+	if !k.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: k.TLSInsecureSkipVerify,
+	}
+
+	if k.TLSCAFile != "" {
+		ca, err := os.ReadFile(k.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading KAFKA_TLS_CA_FILE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("KAFKA_TLS_CA_FILE %s has no PEM certificates", k.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (k.TLSCertFile == "") != (k.TLSKeyFile == "") {
+		return nil, errors.New("KAFKA_TLS_CERT_FILE and KAFKA_TLS_KEY_FILE must be set together")
+	}
+	if k.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(k.TLSCertFile, k.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading Kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (k Kafka) saslMechanism() (sasl.Mechanism, error) {
+	mechanism := strings.ToUpper(strings.TrimSpace(k.SASLMechanism))
+	if mechanism == "" {
+		return nil, nil
+	}
+	if k.SASLUsername == "" || k.SASLPassword == "" {
+		return nil, fmt.Errorf("KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD are required for SASL %s", mechanism)
+	}
+
+	switch mechanism {
+	case SASLPlain:
+		return plain.Mechanism{Username: k.SASLUsername, Password: k.SASLPassword}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, k.SASLUsername, k.SASLPassword)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, k.SASLUsername, k.SASLPassword)
+	default:
+		return nil, fmt.Errorf("unsupported KAFKA_SASL_MECHANISM %q (use %s, %s or %s)", k.SASLMechanism, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+	}
+}
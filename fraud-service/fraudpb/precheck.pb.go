@@ -0,0 +1,403 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: draftea/fraud/v1/precheck.proto
+
+package fraudpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PreCheckRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId       string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Amount          float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency        string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	Method          string                 `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	CustomerId      string                 `protobuf:"bytes,5,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	CustomerName    string                 `protobuf:"bytes,6,opt,name=customer_name,json=customerName,proto3" json:"customer_name,omitempty"`
+	CustomerCountry string                 `protobuf:"bytes,7,opt,name=customer_country,json=customerCountry,proto3" json:"customer_country,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PreCheckRequest) Reset() {
+	*x = PreCheckRequest{}
+	mi := &file_draftea_fraud_v1_precheck_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreCheckRequest) ProtoMessage() {}
+
+func (x *PreCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_draftea_fraud_v1_precheck_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreCheckRequest.ProtoReflect.Descriptor instead.
+func (*PreCheckRequest) Descriptor() ([]byte, []int) {
+	return file_draftea_fraud_v1_precheck_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PreCheckRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *PreCheckRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *PreCheckRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *PreCheckRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *PreCheckRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *PreCheckRequest) GetCustomerName() string {
+	if x != nil {
+		return x.CustomerName
+	}
+	return ""
+}
+
+func (x *PreCheckRequest) GetCustomerCountry() string {
+	if x != nil {
+		return x.CustomerCountry
+	}
+	return ""
+}
+
+func (x *PreCheckRequest) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type RuleResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Triggered     bool                   `protobuf:"varint,2,opt,name=triggered,proto3" json:"triggered,omitempty"`
+	Score         float64                `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleResult) Reset() {
+	*x = RuleResult{}
+	mi := &file_draftea_fraud_v1_precheck_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleResult) ProtoMessage() {}
+
+func (x *RuleResult) ProtoReflect() protoreflect.Message {
+	mi := &file_draftea_fraud_v1_precheck_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleResult.ProtoReflect.Descriptor instead.
+func (*RuleResult) Descriptor() ([]byte, []int) {
+	return file_draftea_fraud_v1_precheck_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RuleResult) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *RuleResult) GetTriggered() bool {
+	if x != nil {
+		return x.Triggered
+	}
+	return false
+}
+
+func (x *RuleResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RuleResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *RuleResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RuleResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PreCheckResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId      string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	CustomerId     string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Status         string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Reason         string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Score          float64                `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	RuleSetVersion string                 `protobuf:"bytes,6,opt,name=rule_set_version,json=ruleSetVersion,proto3" json:"rule_set_version,omitempty"`
+	Rules          []*RuleResult          `protobuf:"bytes,7,rep,name=rules,proto3" json:"rules,omitempty"`
+	CheckedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PreCheckResponse) Reset() {
+	*x = PreCheckResponse{}
+	mi := &file_draftea_fraud_v1_precheck_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreCheckResponse) ProtoMessage() {}
+
+func (x *PreCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_draftea_fraud_v1_precheck_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreCheckResponse.ProtoReflect.Descriptor instead.
+func (*PreCheckResponse) Descriptor() ([]byte, []int) {
+	return file_draftea_fraud_v1_precheck_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PreCheckResponse) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *PreCheckResponse) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *PreCheckResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PreCheckResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PreCheckResponse) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *PreCheckResponse) GetRuleSetVersion() string {
+	if x != nil {
+		return x.RuleSetVersion
+	}
+	return ""
+}
+
+func (x *PreCheckResponse) GetRules() []*RuleResult {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *PreCheckResponse) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+var File_draftea_fraud_v1_precheck_proto protoreflect.FileDescriptor
+
+const file_draftea_fraud_v1_precheck_proto_rawDesc = "" +
+	"\n" +
+	"\x1fdraftea/fraud/v1/precheck.proto\x12\x10draftea.fraud.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa8\x02\n" +
+	"\x0fPreCheckRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x1f\n" +
+	"\vcustomer_id\x18\x05 \x01(\tR\n" +
+	"customerId\x12#\n" +
+	"\rcustomer_name\x18\x06 \x01(\tR\fcustomerName\x12)\n" +
+	"\x10customer_country\x18\a \x01(\tR\x0fcustomerCountry\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x9a\x01\n" +
+	"\n" +
+	"RuleResult\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12\x1c\n" +
+	"\ttriggered\x18\x02 \x01(\bR\ttriggered\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xb1\x02\n" +
+	"\x10PreCheckResponse\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x1f\n" +
+	"\vcustomer_id\x18\x02 \x01(\tR\n" +
+	"customerId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x01R\x05score\x12(\n" +
+	"\x10rule_set_version\x18\x06 \x01(\tR\x0eruleSetVersion\x122\n" +
+	"\x05rules\x18\a \x03(\v2\x1c.draftea.fraud.v1.RuleResultR\x05rules\x129\n" +
+	"\n" +
+	"checked_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt2Z\n" +
+	"\bPreCheck\x12N\n" +
+	"\x05Check\x12!.draftea.fraud.v1.PreCheckRequest\x1a\".draftea.fraud.v1.PreCheckResponseB4Z2github.com/jeffleon2/draftea-fraud-service/fraudpbb\x06proto3"
+
+var (
+	file_draftea_fraud_v1_precheck_proto_rawDescOnce sync.Once
+	file_draftea_fraud_v1_precheck_proto_rawDescData []byte
+)
+
+func file_draftea_fraud_v1_precheck_proto_rawDescGZIP() []byte {
+	file_draftea_fraud_v1_precheck_proto_rawDescOnce.Do(func() {
+		file_draftea_fraud_v1_precheck_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_draftea_fraud_v1_precheck_proto_rawDesc), len(file_draftea_fraud_v1_precheck_proto_rawDesc)))
+	})
+	return file_draftea_fraud_v1_precheck_proto_rawDescData
+}
+
+var file_draftea_fraud_v1_precheck_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_draftea_fraud_v1_precheck_proto_goTypes = []any{
+	(*PreCheckRequest)(nil),       // 0: draftea.fraud.v1.PreCheckRequest
+	(*RuleResult)(nil),            // 1: draftea.fraud.v1.RuleResult
+	(*PreCheckResponse)(nil),      // 2: draftea.fraud.v1.PreCheckResponse
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+}
+var file_draftea_fraud_v1_precheck_proto_depIdxs = []int32{
+	3, // 0: draftea.fraud.v1.PreCheckRequest.created_at:type_name -> google.protobuf.Timestamp
+	1, // 1: draftea.fraud.v1.PreCheckResponse.rules:type_name -> draftea.fraud.v1.RuleResult
+	3, // 2: draftea.fraud.v1.PreCheckResponse.checked_at:type_name -> google.protobuf.Timestamp
+	0, // 3: draftea.fraud.v1.PreCheck.Check:input_type -> draftea.fraud.v1.PreCheckRequest
+	2, // 4: draftea.fraud.v1.PreCheck.Check:output_type -> draftea.fraud.v1.PreCheckResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_draftea_fraud_v1_precheck_proto_init() }
+func file_draftea_fraud_v1_precheck_proto_init() {
+	if File_draftea_fraud_v1_precheck_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_draftea_fraud_v1_precheck_proto_rawDesc), len(file_draftea_fraud_v1_precheck_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_draftea_fraud_v1_precheck_proto_goTypes,
+		DependencyIndexes: file_draftea_fraud_v1_precheck_proto_depIdxs,
+		MessageInfos:      file_draftea_fraud_v1_precheck_proto_msgTypes,
+	}.Build()
+	File_draftea_fraud_v1_precheck_proto = out.File
+	file_draftea_fraud_v1_precheck_proto_goTypes = nil
+	file_draftea_fraud_v1_precheck_proto_depIdxs = nil
+}
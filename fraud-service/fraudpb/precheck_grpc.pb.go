@@ -0,0 +1,121 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: draftea/fraud/v1/precheck.proto
+
+package fraudpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PreCheck_Check_FullMethodName = "/draftea.fraud.v1.PreCheck/Check"
+)
+
+// PreCheckClient is the client API for PreCheck service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PreCheckClient interface {
+	Check(ctx context.Context, in *PreCheckRequest, opts ...grpc.CallOption) (*PreCheckResponse, error)
+}
+
+type preCheckClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPreCheckClient(cc grpc.ClientConnInterface) PreCheckClient {
+	return &preCheckClient{cc}
+}
+
+func (c *preCheckClient) Check(ctx context.Context, in *PreCheckRequest, opts ...grpc.CallOption) (*PreCheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PreCheckResponse)
+	err := c.cc.Invoke(ctx, PreCheck_Check_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PreCheckServer is the server API for PreCheck service.
+// All implementations must embed UnimplementedPreCheckServer
+// for forward compatibility.
+type PreCheckServer interface {
+	Check(context.Context, *PreCheckRequest) (*PreCheckResponse, error)
+	mustEmbedUnimplementedPreCheckServer()
+}
+
+// UnimplementedPreCheckServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPreCheckServer struct{}
+
+func (UnimplementedPreCheckServer) Check(context.Context, *PreCheckRequest) (*PreCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+}
+func (UnimplementedPreCheckServer) mustEmbedUnimplementedPreCheckServer() {}
+func (UnimplementedPreCheckServer) testEmbeddedByValue()                  {}
+
+// UnsafePreCheckServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PreCheckServer will
+// result in compilation errors.
+type UnsafePreCheckServer interface {
+	mustEmbedUnimplementedPreCheckServer()
+}
+
+func RegisterPreCheckServer(s grpc.ServiceRegistrar, srv PreCheckServer) {
+	// If the following call pancis, it indicates UnimplementedPreCheckServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PreCheck_ServiceDesc, srv)
+}
+
+func _PreCheck_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PreCheckServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PreCheck_Check_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PreCheckServer).Check(ctx, req.(*PreCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PreCheck_ServiceDesc is the grpc.ServiceDesc for PreCheck service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PreCheck_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "draftea.fraud.v1.PreCheck",
+	HandlerType: (*PreCheckServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Check",
+			Handler:    _PreCheck_Check_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "draftea/fraud/v1/precheck.proto",
+}
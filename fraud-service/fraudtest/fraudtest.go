@@ -0,0 +1,105 @@
+// Package fraudtest runs fraud-service in process for whole-system tests:
+// its event handler consumes from a membroker.Broker instead of Kafka and the
+// decision audit log uses the given database instead of Postgres. The
+// configuration is read from the environment, with the service defaults, and
+// payments are evaluated without the simulated analysis delay.
+package fraudtest
+
+import (
+	"context"
+	"strings"
+
+	"github.com/caarlos0/env/v6"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-event-contracts/membroker"
+	"github.com/jeffleon2/draftea-fraud-service/config"
+	"github.com/jeffleon2/draftea-fraud-service/internal/breaker"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/repository/posgrest"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
+	"github.com/jeffleon2/draftea-fraud-service/internal/service"
+	"github.com/jeffleon2/draftea-fraud-service/internal/subscriber"
+	"gorm.io/gorm"
+)
+
+// Decision is a fraud decision recorded in the audit log.
+type Decision struct {
+	PaymentID string
+	Status    string
+	Reason    string
+}
+
+// Service is a running fraud-service.
+type Service struct {
+	Consumer *membroker.Consumer
+
+	fraud *service.FraudService
+}
+
+// Start migrates db and starts consuming the service's topics from broker
+// until ctx is cancelled.
+func Start(ctx context.Context, broker *membroker.Broker, db *gorm.DB) (*Service, error) {
+	var cfg config.Config
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&models.FraudDecision{}); err != nil {
+		return nil, err
+	}
+
+	ruleSet := rules.Default(cfg.Fraud.RuleSetVersion, cfg.Fraud.HighValueThreshold)
+	if cfg.Fraud.RulesFile != "" {
+		loaded, err := rules.Load(cfg.Fraud.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		ruleSet = loaded
+	}
+
+	publisherConfig, err := cfg.Kafka.GetPublisherConfig()
+	if err != nil {
+		return nil, err
+	}
+	publisher := broker.Publisher(models.ServiceName)
+	publisher.Serializer = publisherConfig.Serializer
+
+	dbBreaker := breaker.New("postgres", cfg.CircuitBreaker)
+	dbBreaker.IsFailure = posgrest.IsFailure
+	decisionRepo := posgrest.NewDecisionRepository(db, dbBreaker)
+	fraudService := service.NewFraudService(publisher, ruleSet, decisionRepo)
+	fraudService.AnalysisDelay = 0
+	fraudHandler := handler.Fraud(fraudService)
+
+	consumer := broker.Consumer(cfg.Kafka.PaymentConsumerGroup, strings.Split(cfg.Kafka.SubscriberTopics, ",")...)
+	consumer.IsPermanent = subscriber.IsPermanent
+	consumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		return fraudHandler.Handler(ctx, envelope)
+	})
+
+	return &Service{Consumer: consumer, fraud: fraudService}, nil
+}
+
+// Decisions returns the decisions recorded for a payment, newest first.
+func (s *Service) Decisions(ctx context.Context, paymentID string) ([]Decision, error) {
+	stored, err := s.fraud.Decisions.GetByPaymentID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := make([]Decision, len(*stored))
+	for i, decision := range *stored {
+		decisions[i] = Decision{
+			PaymentID: decision.PaymentID,
+			Status:    decision.Status,
+			Reason:    decision.Reason,
+		}
+	}
+	return decisions, nil
+}
+
+// Close waits for the consumer to stop. The context given to Start must be
+// cancelled first.
+func (s *Service) Close() error {
+	return s.Consumer.Close()
+}
@@ -0,0 +1,167 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jeffleon2/draftea-fraud-service/config"
+	"github.com/jeffleon2/draftea-fraud-service/internal/metrics"
+)
+
+// ErrOpen is returned without calling the dependency while the breaker is
+// open, or half-open with every probe slot taken.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the breaker state. Its value is the one exported by the
+// circuit_breaker_state gauge.
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker stops calling a dependency after MaxFailures consecutive failures
+// and lets a few probes through once OpenTimeout has passed.
+type Breaker struct {
+	Name   string
+	Config config.CircuitBreaker
+	// IsFailure decides which errors count against the dependency. Errors it
+	// rejects, such as "record not found", pass through without tripping the
+	// breaker. Nil counts every error except context cancellation.
+	IsFailure func(err error) bool
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+}
+
+// New creates a closed breaker and exports its state under name.
+func New(name string, cfg config.CircuitBreaker) *Breaker {
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 60 * time.Second
+	}
+	if cfg.HalfOpenMaxRequests <= 0 {
+		cfg.HalfOpenMaxRequests = 1
+	}
+
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(Closed))
+	return &Breaker{Name: name, Config: cfg}
+}
+
+// Execute calls fn unless the breaker is open, and records its result.
+func (b *Breaker) Execute(fn func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// State returns the current state, moving an expired open breaker to half-open.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.expire()
+	return b.state
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.expire()
+	switch b.state {
+	case Open:
+		metrics.CircuitBreakerRejectionsTotal.WithLabelValues(b.Name).Inc()
+		return ErrOpen
+	case HalfOpen:
+		if b.probes >= b.Config.HalfOpenMaxRequests {
+			metrics.CircuitBreakerRejectionsTotal.WithLabelValues(b.Name).Inc()
+			return ErrOpen
+		}
+		b.probes++
+	}
+	return nil
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := err != nil && b.isFailure(err)
+
+	switch b.state {
+	case Closed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.Config.MaxFailures {
+			b.setState(Open)
+		}
+	case HalfOpen:
+		if b.probes > 0 {
+			b.probes--
+		}
+		if failed {
+			b.setState(Open)
+			return
+		}
+		b.successes++
+		if b.successes >= b.Config.HalfOpenMaxRequests {
+			b.setState(Closed)
+		}
+	}
+}
+
+func (b *Breaker) isFailure(err error) bool {
+	if b.IsFailure != nil {
+		return b.IsFailure(err)
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// expire moves an open breaker whose timeout has passed to half-open.
+func (b *Breaker) expire() {
+	if b.state == Open && time.Since(b.openedAt) >= b.Config.OpenTimeout {
+		b.setState(HalfOpen)
+	}
+}
+
+func (b *Breaker) setState(state State) {
+	b.state = state
+	b.failures = 0
+	b.successes = 0
+	b.probes = 0
+	if state == Open {
+		b.openedAt = time.Now()
+	}
+	metrics.CircuitBreakerState.WithLabelValues(b.Name).Set(float64(state))
+}
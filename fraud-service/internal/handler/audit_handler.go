@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+)
+
+const (
+	defaultPageLimit    = 50
+	maxPageLimit        = 500
+	defaultReportPeriod = 24 * time.Hour
+)
+
+// AuditServiceIn defines the read operations over the fraud decision audit log.
+type AuditServiceIn interface {
+	GetDecisionsByPayment(ctx context.Context, paymentID string) (*[]models.FraudDecision, error)
+	GetDecisionsByCustomer(ctx context.Context, customerID string, limit, offset int) (*[]models.FraudDecision, error)
+	ShadowReport(ctx context.Context, from, to time.Time) (*models.ShadowReport, error)
+}
+
+// AuditHandler exposes the fraud decision audit log over HTTP
+// so audits and disputes can explain past decisions.
+type AuditHandler struct {
+	AuditService AuditServiceIn
+}
+
+// Audit creates a new AuditHandler with the provided audit service.
+func Audit(s AuditServiceIn) *AuditHandler {
+	return &AuditHandler{
+		AuditService: s,
+	}
+}
+
+// GetByPayment handles GET /fraud/decisions/payments/:payment_id.
+// It returns every decision recorded for the payment, newest first.
+func (h *AuditHandler) GetByPayment(c *gin.Context) {
+	decisions, err := h.AuditService.GetDecisionsByPayment(c.Request.Context(), c.Param("payment_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"decisions": decisions})
+}
+
+// GetByCustomer handles GET /fraud/decisions/customers/:customer_id.
+// Results are paginated with the limit and offset query parameters.
+func (h *AuditHandler) GetByCustomer(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit <= 0 || limit > maxPageLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		return
+	}
+
+	decisions, err := h.AuditService.GetDecisionsByCustomer(c.Request.Context(), c.Param("customer_id"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"decisions": decisions,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+// ShadowReport handles GET /fraud/shadow/report.
+// The period is given by the from and to query parameters in RFC3339 format
+// and defaults to the last 24 hours.
+func (h *AuditHandler) ShadowReport(c *gin.Context) {
+	to := time.Now().UTC()
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to, expected RFC3339"})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultReportPeriod)
+	if raw := c.Query("from"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, expected RFC3339"})
+			return
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	report, err := h.AuditService.ShadowReport(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
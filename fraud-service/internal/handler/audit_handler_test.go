@@ -0,0 +1,129 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler/mocks"
+	"github.com/jeffleon2/draftea-fraud-service/internal/health"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newAuditRouter(t *testing.T, s handler.AuditServiceIn) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router, health.NewChecker(time.Second), handler.Audit(s), handler.Rules(mocks.NewMockRulesServiceIn(t)), handler.PreCheck(mocks.NewMockPreCheckServiceIn(t)), nil)
+	return router
+}
+
+func TestAuditHandler_GetByPayment(t *testing.T) {
+	mockService := mocks.NewMockAuditServiceIn(t)
+	router := newAuditRouter(t, mockService)
+
+	decisions := &[]models.FraudDecision{
+		{ID: "decision-1", PaymentID: "payment-123", Status: models.PaymentStatusDeclined, RuleSetVersion: "v1"},
+	}
+	mockService.EXPECT().
+		GetDecisionsByPayment(mock.Anything, "payment-123").
+		Return(decisions, nil).
+		Once()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fraud/decisions/payments/payment-123", nil)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Decisions []models.FraudDecision `json:"decisions"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Len(t, body.Decisions, 1)
+	assert.Equal(t, "decision-1", body.Decisions[0].ID)
+}
+
+func TestAuditHandler_GetByPayment_ServiceError(t *testing.T) {
+	mockService := mocks.NewMockAuditServiceIn(t)
+	router := newAuditRouter(t, mockService)
+
+	mockService.EXPECT().
+		GetDecisionsByPayment(mock.Anything, "payment-123").
+		Return(nil, errors.New("db down")).
+		Once()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fraud/decisions/payments/payment-123", nil)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestAuditHandler_GetByCustomer_Pagination(t *testing.T) {
+	mockService := mocks.NewMockAuditServiceIn(t)
+	router := newAuditRouter(t, mockService)
+
+	mockService.EXPECT().
+		GetDecisionsByCustomer(mock.Anything, "customer-456", 10, 30).
+		Return(&[]models.FraudDecision{}, nil).
+		Once()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fraud/decisions/customers/customer-456?limit=10&offset=30", nil)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuditHandler_GetByCustomer_InvalidLimit(t *testing.T) {
+	mockService := mocks.NewMockAuditServiceIn(t)
+	router := newAuditRouter(t, mockService)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fraud/decisions/customers/customer-456?limit=-1", nil)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "GetDecisionsByCustomer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuditHandler_ShadowReport(t *testing.T) {
+	mockService := mocks.NewMockAuditServiceIn(t)
+	router := newAuditRouter(t, mockService)
+
+	from, _ := time.Parse(time.RFC3339, "2025-01-01T00:00:00Z")
+	to, _ := time.Parse(time.RFC3339, "2025-01-08T00:00:00Z")
+	report := &models.ShadowReport{From: from, To: to, Evaluations: 10, Changed: 2}
+
+	mockService.EXPECT().
+		ShadowReport(mock.Anything, from, to).
+		Return(report, nil).
+		Once()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fraud/shadow/report?from=2025-01-01T00:00:00Z&to=2025-01-08T00:00:00Z", nil)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body models.ShadowReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 10, body.Evaluations)
+	assert.Equal(t, 2, body.Changed)
+}
+
+func TestAuditHandler_ShadowReport_InvalidPeriod(t *testing.T) {
+	mockService := mocks.NewMockAuditServiceIn(t)
+	router := newAuditRouter(t, mockService)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fraud/shadow/report?from=2025-01-08T00:00:00Z&to=2025-01-01T00:00:00Z", nil)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
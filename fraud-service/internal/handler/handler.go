@@ -2,10 +2,11 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-fraud-service/internal/logging"
 	"github.com/jeffleon2/draftea-fraud-service/internal/models"
-	"github.com/sirupsen/logrus"
+	"github.com/jeffleon2/draftea-fraud-service/internal/subscriber"
 )
 
 // FraudServiceIn defines the interface for fraud evaluation operations.
@@ -29,25 +30,30 @@ func Fraud(s FraudServiceIn) *FraudHandler {
 	}
 }
 
-// Handler processes raw payment event messages from Kafka.
-// It unmarshals the JSON payload into a PaymentCreatedEvent and delegates
-// fraud evaluation to the FraudService. Returns an error if unmarshalling
-// fails or if fraud evaluation encounters an issue.
-func (h *FraudHandler) Handler(ctx context.Context, raw []byte) error {
+// Handler processes payment event envelopes from Kafka, already decoded and
+// validated by the subscriber whatever their format.
+// It decodes the envelope data into a PaymentCreatedEvent and delegates
+// fraud evaluation to the FraudService. Returns an error if decoding fails
+// (a permanent error: wrong type or unknown major version) or if fraud
+// evaluation encounters an issue.
+func (h *FraudHandler) Handler(ctx context.Context, envelope contracts.Envelope) error {
+	logger := logging.FromContext(ctx)
+
 	var event models.PaymentCreatedEvent
 
-	if err := json.Unmarshal(raw, &event); err != nil {
-		logrus.Errorf("Error unmarshalling PaymentCreatedEvent: %s", err.Error())
-		return err
+	if err := envelope.Decode(&event); err != nil {
+		logger.Errorf("Error unmarshalling PaymentCreatedEvent: %s", err.Error())
+		return subscriber.Permanent(err)
 	}
+	logger = logger.WithField(logging.FieldPaymentID, event.ID)
 
 	err := h.FraudService.EvaluatePayment(ctx, event)
 	if err != nil {
-		logrus.Errorf("Error evaluating fraud: %s", err.Error())
+		logger.Errorf("Error evaluating fraud: %s", err.Error())
 		return err
 	}
 
-	logrus.Info("PaymentCreatedEvent handled successfully")
+	logger.Info("PaymentCreatedEvent handled successfully")
 
 	return nil
 }
@@ -2,15 +2,18 @@ package handler_test
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-fraud-service/internal/handler"
 	"github.com/jeffleon2/draftea-fraud-service/internal/handler/mocks"
 	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/subscriber"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandler_Success(t *testing.T) {
@@ -27,8 +30,7 @@ func TestHandler_Success(t *testing.T) {
 		TraceID:    "trace-789",
 	}
 
-	eventBytes, err := json.Marshal(event)
-	assert.NoError(t, err)
+	eventBytes := envelope(t, event)
 
 	ctx := context.Background()
 
@@ -37,7 +39,7 @@ func TestHandler_Success(t *testing.T) {
 		Return(nil).
 		Once()
 
-	err = h.Handler(ctx, eventBytes)
+	err := h.Handler(ctx, eventBytes)
 
 	assert.NoError(t, err)
 	mockService.AssertExpectations(t)
@@ -47,13 +49,15 @@ func TestHandler_UnmarshalError(t *testing.T) {
 	mockService := mocks.NewMockFraudServiceIn(t)
 	h := handler.Fraud(mockService)
 
-	invalidJSON := []byte(`{"invalid json`)
+	invalidJSON := envelope(t, models.PaymentCreatedEvent{})
+	invalidJSON.Data = []byte(`{"invalid json`)
 	ctx := context.Background()
 
 	err := h.Handler(ctx, invalidJSON)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unexpected end of JSON input")
+	assert.True(t, subscriber.IsPermanent(err))
 	mockService.AssertNotCalled(t, "EvaluatePayment", mock.Anything, mock.Anything)
 }
 
@@ -71,8 +75,7 @@ func TestHandler_ServiceError(t *testing.T) {
 		TraceID:    "trace-789",
 	}
 
-	eventBytes, err := json.Marshal(event)
-	assert.NoError(t, err)
+	eventBytes := envelope(t, event)
 
 	ctx := context.Background()
 	expectedError := errors.New("service evaluation failed")
@@ -82,10 +85,11 @@ func TestHandler_ServiceError(t *testing.T) {
 		Return(expectedError).
 		Once()
 
-	err = h.Handler(ctx, eventBytes)
+	err := h.Handler(ctx, eventBytes)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
+	assert.False(t, subscriber.IsPermanent(err))
 	mockService.AssertExpectations(t)
 }
 
@@ -103,8 +107,7 @@ func TestHandler_HighValuePayment(t *testing.T) {
 		TraceID:    "trace-suspicious",
 	}
 
-	eventBytes, err := json.Marshal(event)
-	assert.NoError(t, err)
+	eventBytes := envelope(t, event)
 
 	ctx := context.Background()
 
@@ -113,7 +116,7 @@ func TestHandler_HighValuePayment(t *testing.T) {
 		Return(nil).
 		Once()
 
-	err = h.Handler(ctx, eventBytes)
+	err := h.Handler(ctx, eventBytes)
 
 	assert.NoError(t, err)
 	mockService.AssertExpectations(t)
@@ -132,17 +135,97 @@ func TestHandler_EmptyPayload(t *testing.T) {
 	mockService := mocks.NewMockFraudServiceIn(t)
 	h := handler.Fraud(mockService)
 
-	emptyJSON := []byte(`{}`)
+	var emptyEvent models.PaymentCreatedEvent
 	ctx := context.Background()
 
-	var emptyEvent models.PaymentCreatedEvent
 	mockService.EXPECT().
 		EvaluatePayment(ctx, emptyEvent).
 		Return(nil).
 		Once()
 
-	err := h.Handler(ctx, emptyJSON)
+	err := h.Handler(ctx, envelope(t, emptyEvent))
 
 	assert.NoError(t, err)
 	mockService.AssertExpectations(t)
 }
+
+func TestHandler_ProtobufEvent(t *testing.T) {
+	mockService := mocks.NewMockFraudServiceIn(t)
+	h := handler.Fraud(mockService)
+
+	event := models.PaymentCreatedEvent{
+		ID:         "payment-123",
+		Amount:     5000.0,
+		Currency:   "USD",
+		Status:     "PENDING",
+		Method:     "credit_card",
+		CustomerID: "customer-456",
+		TraceID:    "trace-789",
+		CreatedAt:  time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+	raw, err := contracts.Protobuf.Marshal(envelope(t, event), event)
+	require.NoError(t, err)
+	env, err := contracts.Protobuf.Unmarshal(raw)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	mockService.EXPECT().
+		EvaluatePayment(ctx, event).
+		Return(nil).
+		Once()
+
+	assert.NoError(t, h.Handler(ctx, env))
+}
+
+func TestHandler_UnknownMajorVersion(t *testing.T) {
+	mockService := mocks.NewMockFraudServiceIn(t)
+	h := handler.Fraud(mockService)
+
+	env := envelope(t, models.PaymentCreatedEvent{ID: "payment-123"})
+	env.DataSchema = "/schemas/payments.created/2.0"
+
+	err := h.Handler(context.Background(), env)
+
+	assert.ErrorIs(t, err, contracts.ErrUnsupportedVersion)
+	assert.True(t, subscriber.IsPermanent(err))
+	mockService.AssertNotCalled(t, "EvaluatePayment", mock.Anything, mock.Anything)
+}
+
+func TestHandler_NewerMinorVersion(t *testing.T) {
+	mockService := mocks.NewMockFraudServiceIn(t)
+	h := handler.Fraud(mockService)
+
+	event := models.PaymentCreatedEvent{ID: "payment-123", Amount: 100}
+	env := envelope(t, event)
+	env.DataSchema = "/schemas/payments.created/1.3"
+
+	ctx := context.Background()
+	mockService.EXPECT().
+		EvaluatePayment(ctx, event).
+		Return(nil).
+		Once()
+
+	assert.NoError(t, h.Handler(ctx, env))
+}
+
+func TestHandler_UnexpectedEventType(t *testing.T) {
+	mockService := mocks.NewMockFraudServiceIn(t)
+	h := handler.Fraud(mockService)
+
+	env := envelope(t, models.FraudCheckEvent{ID: "payment-123", Status: models.PaymentStatusApproved})
+
+	err := h.Handler(context.Background(), env)
+
+	assert.ErrorIs(t, err, contracts.ErrUnexpectedType)
+	assert.True(t, subscriber.IsPermanent(err))
+	mockService.AssertNotCalled(t, "EvaluatePayment", mock.Anything, mock.Anything)
+}
+
+// envelope wraps event the way the producing service publishes it.
+func envelope(t *testing.T, event contracts.Event) contracts.Envelope {
+	t.Helper()
+
+	env, err := contracts.New("payment-service", event)
+	require.NoError(t, err)
+	return env
+}
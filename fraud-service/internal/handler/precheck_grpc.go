@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeffleon2/draftea-fraud-service/fraudpb"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PreCheckGRPC serves the pre-check over gRPC with the same rules and the
+// same validation as POST /fraud/precheck.
+type PreCheckGRPC struct {
+	fraudpb.UnimplementedPreCheckServer
+	PreCheckService PreCheckServiceIn
+}
+
+// PreCheckServer creates a new PreCheckGRPC with the provided service.
+func PreCheckServer(s PreCheckServiceIn) *PreCheckGRPC {
+	return &PreCheckGRPC{
+		PreCheckService: s,
+	}
+}
+
+// Check implements fraudpb.PreCheckServer.
+func (h *PreCheckGRPC) Check(ctx context.Context, req *fraudpb.PreCheckRequest) (*fraudpb.PreCheckResponse, error) {
+	if req.GetAmount() <= 0 || req.GetCurrency() == "" || req.GetMethod() == "" || req.GetCustomerId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "amount, currency, method and customer_id are required")
+	}
+
+	createdAt := time.Now()
+	if req.GetCreatedAt() != nil {
+		createdAt = req.GetCreatedAt().AsTime()
+	}
+
+	result, err := h.PreCheckService.PreCheck(ctx, models.PaymentCreatedEvent{
+		ID:              req.GetPaymentId(),
+		Amount:          req.GetAmount(),
+		Currency:        req.GetCurrency(),
+		Status:          models.PaymentStatusPending,
+		Method:          req.GetMethod(),
+		CustomerID:      req.GetCustomerId(),
+		CustomerName:    req.GetCustomerName(),
+		CustomerCountry: req.GetCustomerCountry(),
+		CreatedAt:       createdAt,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &fraudpb.PreCheckResponse{
+		PaymentId:      result.PaymentID,
+		CustomerId:     result.CustomerID,
+		Status:         result.Status,
+		Reason:         result.Reason,
+		Score:          result.Score,
+		RuleSetVersion: result.RuleSetVersion,
+		CheckedAt:      timestamppb.New(result.CheckedAt),
+	}
+	for _, rule := range result.Rules {
+		resp.Rules = append(resp.Rules, &fraudpb.RuleResult{
+			Rule:      rule.Rule,
+			Triggered: rule.Triggered,
+			Score:     rule.Score,
+			Action:    rule.Action,
+			Reason:    rule.Reason,
+			Error:     rule.Error,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/jeffleon2/draftea-fraud-service/fraudpb"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler/mocks"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newPreCheckClient(t *testing.T, s handler.PreCheckServiceIn) fraudpb.PreCheckClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	fraudpb.RegisterPreCheckServer(server, handler.PreCheckServer(s))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return fraudpb.NewPreCheckClient(conn)
+}
+
+func TestPreCheckGRPC_Check(t *testing.T) {
+	mockService := mocks.NewMockPreCheckServiceIn(t)
+	client := newPreCheckClient(t, mockService)
+
+	mockService.EXPECT().
+		PreCheck(mock.Anything, mock.MatchedBy(func(event models.PaymentCreatedEvent) bool {
+			return event.Amount == 15000 &&
+				event.CustomerID == "customer-456" &&
+				event.Status == models.PaymentStatusPending &&
+				!event.CreatedAt.IsZero()
+		})).
+		Return(&models.PreCheckResult{
+			CustomerID: "customer-456",
+			Status:     models.PaymentStatusDeclined,
+			Reason:     "High-value transaction suspicious",
+			Rules:      []models.RuleResult{{Rule: "high_value", Triggered: true, Score: 1, Action: models.PaymentStatusDeclined}},
+		}, nil).
+		Once()
+
+	resp, err := client.Check(context.Background(), &fraudpb.PreCheckRequest{
+		Amount:     15000,
+		Currency:   "USD",
+		Method:     "credit_card",
+		CustomerId: "customer-456",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentStatusDeclined, resp.GetStatus())
+	require.Len(t, resp.GetRules(), 1)
+	assert.Equal(t, "high_value", resp.GetRules()[0].GetRule())
+}
+
+func TestPreCheckGRPC_Check_InvalidRequest(t *testing.T) {
+	client := newPreCheckClient(t, mocks.NewMockPreCheckServiceIn(t))
+
+	_, err := client.Check(context.Background(), &fraudpb.PreCheckRequest{Amount: -1, Currency: "USD"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestPreCheckGRPC_Check_ServiceError(t *testing.T) {
+	mockService := mocks.NewMockPreCheckServiceIn(t)
+	client := newPreCheckClient(t, mockService)
+
+	mockService.EXPECT().
+		PreCheck(mock.Anything, mock.Anything).
+		Return(nil, errors.New("db down")).
+		Once()
+
+	_, err := client.Check(context.Background(), &fraudpb.PreCheckRequest{Amount: 100, Currency: "USD", Method: "credit_card", CustomerId: "customer-456"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
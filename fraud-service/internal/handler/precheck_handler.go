@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+)
+
+// PreCheckServiceIn defines the synchronous fraud evaluation used before a payment exists.
+type PreCheckServiceIn interface {
+	PreCheck(ctx context.Context, event models.PaymentCreatedEvent) (*models.PreCheckResult, error)
+}
+
+// PreCheckHandler answers risk questions synchronously, e.g. to decide
+// whether a step-up prompt is needed before creating a payment.
+type PreCheckHandler struct {
+	PreCheckService PreCheckServiceIn
+}
+
+// PreCheck creates a new PreCheckHandler with the provided service.
+func PreCheck(s PreCheckServiceIn) *PreCheckHandler {
+	return &PreCheckHandler{
+		PreCheckService: s,
+	}
+}
+
+// PreCheckRequest is the body of POST /fraud/precheck.
+// It mirrors the payments.created event; the payment ID is optional
+// because the payment usually does not exist yet.
+type PreCheckRequest struct {
+	PaymentID       string    `json:"payment_id"`
+	Amount          float64   `json:"amount" binding:"required,gt=0"`
+	Currency        string    `json:"currency" binding:"required"`
+	Method          string    `json:"method" binding:"required"`
+	CustomerID      string    `json:"customer_id" binding:"required"`
+	CustomerName    string    `json:"customer_name"`
+	CustomerCountry string    `json:"customer_country"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Check handles POST /fraud/precheck.
+// The decision is returned with 200 whatever its status; it is non-binding
+// and the payment is evaluated again when payments.created is consumed.
+func (h *PreCheckHandler) Check(c *gin.Context) {
+	var req PreCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	createdAt := req.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	result, err := h.PreCheckService.PreCheck(c.Request.Context(), models.PaymentCreatedEvent{
+		ID:              req.PaymentID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		Status:          models.PaymentStatusPending,
+		Method:          req.Method,
+		CustomerID:      req.CustomerID,
+		CustomerName:    req.CustomerName,
+		CustomerCountry: req.CustomerCountry,
+		CreatedAt:       createdAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,82 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler/mocks"
+	"github.com/jeffleon2/draftea-fraud-service/internal/health"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newPreCheckRouter(t *testing.T, s handler.PreCheckServiceIn) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router, health.NewChecker(time.Second), handler.Audit(mocks.NewMockAuditServiceIn(t)), handler.Rules(mocks.NewMockRulesServiceIn(t)), handler.PreCheck(s), nil)
+	return router
+}
+
+func TestPreCheckHandler_Check(t *testing.T) {
+	mockService := mocks.NewMockPreCheckServiceIn(t)
+	router := newPreCheckRouter(t, mockService)
+
+	mockService.EXPECT().
+		PreCheck(mock.Anything, mock.MatchedBy(func(event models.PaymentCreatedEvent) bool {
+			return event.Amount == 15000 &&
+				event.CustomerID == "customer-456" &&
+				event.Status == models.PaymentStatusPending &&
+				!event.CreatedAt.IsZero()
+		})).
+		Return(&models.PreCheckResult{CustomerID: "customer-456", Status: models.PaymentStatusDeclined, Reason: "High-value transaction suspicious"}, nil).
+		Once()
+
+	body := `{"amount":15000,"currency":"USD","method":"credit_card","customer_id":"customer-456"}`
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/fraud/precheck", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var result models.PreCheckResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, models.PaymentStatusDeclined, result.Status)
+}
+
+func TestPreCheckHandler_Check_InvalidBody(t *testing.T) {
+	mockService := mocks.NewMockPreCheckServiceIn(t)
+	router := newPreCheckRouter(t, mockService)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/fraud/precheck", strings.NewReader(`{"amount":-1,"currency":"USD"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPreCheckHandler_Check_ServiceError(t *testing.T) {
+	mockService := mocks.NewMockPreCheckServiceIn(t)
+	router := newPreCheckRouter(t, mockService)
+
+	mockService.EXPECT().
+		PreCheck(mock.Anything, mock.Anything).
+		Return(nil, errors.New("db down")).
+		Once()
+
+	body := `{"amount":100,"currency":"USD","method":"credit_card","customer_id":"customer-456"}`
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/fraud/precheck", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-fraud-service/internal/health"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// RegisterRoutes mounts the fraud-service HTTP endpoints on the router.
+func RegisterRoutes(router *gin.Engine, checker *health.Checker, audit *AuditHandler, rulesHandler *RulesHandler, preCheck *PreCheckHandler, screening *ScreeningHandler) {
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/healthz", gin.WrapF(checker.Liveness))
+	router.GET("/readyz", gin.WrapF(checker.Readiness))
+
+	decisions := router.Group("/fraud/decisions")
+	decisions.GET("/payments/:payment_id", audit.GetByPayment)
+	decisions.GET("/customers/:customer_id", audit.GetByCustomer)
+
+	shadow := router.Group("/fraud/shadow")
+	shadow.GET("/report", audit.ShadowReport)
+
+	fraudRules := router.Group("/fraud/rules")
+	fraudRules.POST("/dry-run", rulesHandler.DryRun)
+
+	router.POST("/fraud/precheck", preCheck.Check)
+
+	// Screening is optional; the routes only exist when a sanctions list is configured.
+	if screening != nil {
+		sanctions := router.Group("/fraud/screening")
+		sanctions.GET("/list", screening.GetList)
+		sanctions.POST("/reload", screening.Reload)
+	}
+}
+
+// ObservedRoute leaves the Prometheus scrapes and the probes out of the
+// traces and the request logs.
+func ObservedRoute(c *gin.Context) bool {
+	switch c.FullPath() {
+	case "/metrics", "/healthz", "/readyz":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
+)
+
+// RulesServiceIn defines the operations used to author fraud rules.
+type RulesServiceIn interface {
+	DryRunExpression(expression string, samples []rules.Input) ([]models.DryRunResult, error)
+}
+
+// RulesHandler lets analysts test rule expressions before deploying them.
+type RulesHandler struct {
+	RulesService RulesServiceIn
+}
+
+// Rules creates a new RulesHandler with the provided rules service.
+func Rules(s RulesServiceIn) *RulesHandler {
+	return &RulesHandler{
+		RulesService: s,
+	}
+}
+
+// DryRunRequest is the body of POST /fraud/rules/dry-run.
+type DryRunRequest struct {
+	Expression string        `json:"expression" binding:"required"`
+	Samples    []rules.Input `json:"samples"`
+}
+
+// DryRun handles POST /fraud/rules/dry-run.
+// It compiles the expression and evaluates it against the sample events
+// without publishing or recording any decision. Expressions that fail to
+// compile are answered with 422 and the compiler diagnostics.
+func (h *RulesHandler) DryRun(c *gin.Context) {
+	var req DryRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	results, err := h.RulesService.DryRunExpression(req.Expression, req.Samples)
+	if err != nil {
+		var compileErr *rules.CompileError
+		if errors.As(err, &compileErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": compileErr.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
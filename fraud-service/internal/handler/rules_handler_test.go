@@ -0,0 +1,81 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler"
+	"github.com/jeffleon2/draftea-fraud-service/internal/handler/mocks"
+	"github.com/jeffleon2/draftea-fraud-service/internal/health"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newRulesRouter(t *testing.T, s handler.RulesServiceIn) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router, health.NewChecker(time.Second), handler.Audit(mocks.NewMockAuditServiceIn(t)), handler.Rules(s), handler.PreCheck(mocks.NewMockPreCheckServiceIn(t)), nil)
+	return router
+}
+
+func TestRulesHandler_DryRun(t *testing.T) {
+	mockService := mocks.NewMockRulesServiceIn(t)
+	router := newRulesRouter(t, mockService)
+
+	mockService.EXPECT().
+		DryRunExpression(`customer.age_days < 7`, mock.MatchedBy(func(samples []rules.Input) bool {
+			return len(samples) == 1 && samples[0].Event.ID == "payment-1" && samples[0].Customer.AgeDays == 3
+		})).
+		Return([]models.DryRunResult{{PaymentID: "payment-1", Matched: true}}, nil).
+		Once()
+
+	body := `{"expression":"customer.age_days < 7","samples":[{"event":{"id":"payment-1","amount":100},"customer":{"age_days":3}}]}`
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/fraud/rules/dry-run", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Results []models.DryRunResult `json:"results"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Results, 1)
+	assert.True(t, resp.Results[0].Matched)
+}
+
+func TestRulesHandler_DryRun_CompileError(t *testing.T) {
+	mockService := mocks.NewMockRulesServiceIn(t)
+	router := newRulesRouter(t, mockService)
+
+	mockService.EXPECT().
+		DryRunExpression("amount +", mock.Anything).
+		Return(nil, &rules.CompileError{Expression: "amount +", Issues: "syntax error"}).
+		Once()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/fraud/rules/dry-run", strings.NewReader(`{"expression":"amount +"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestRulesHandler_DryRun_MissingExpression(t *testing.T) {
+	mockService := mocks.NewMockRulesServiceIn(t)
+	router := newRulesRouter(t, mockService)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/fraud/rules/dry-run", strings.NewReader(`{"samples":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
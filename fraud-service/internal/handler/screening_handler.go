@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+)
+
+// ScreeningServiceIn defines the operations over the sanctions list in use.
+type ScreeningServiceIn interface {
+	ListInfo() models.SanctionsListInfo
+	Reload() (models.SanctionsListInfo, bool, error)
+}
+
+// ScreeningHandler exposes the sanctions list version and manual reloads.
+type ScreeningHandler struct {
+	ScreeningService ScreeningServiceIn
+}
+
+// Screening creates a new ScreeningHandler with the provided screener.
+func Screening(s ScreeningServiceIn) *ScreeningHandler {
+	return &ScreeningHandler{
+		ScreeningService: s,
+	}
+}
+
+// GetList handles GET /fraud/screening/list.
+func (h *ScreeningHandler) GetList(c *gin.Context) {
+	c.JSON(http.StatusOK, h.ScreeningService.ListInfo())
+}
+
+// Reload handles POST /fraud/screening/reload.
+// A list that fails to load is reported with 422 and the previous list stays in use.
+func (h *ScreeningHandler) Reload(c *gin.Context) {
+	info, changed, err := h.ScreeningService.Reload()
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "list": info})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"changed": changed, "list": info})
+}
@@ -0,0 +1,9 @@
+package models
+
+// CustomerProfile is the enrichment data available to fraud rules
+// in addition to the payment event. It is derived from the customer's
+// history in the fraud decision audit log.
+type CustomerProfile struct {
+	AgeDays     int64 `json:"age_days"`
+	Payments24h int64 `json:"payments_24h"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// DryRunResult is the outcome of evaluating an expression against one sample event.
+type DryRunResult struct {
+	PaymentID string `json:"payment_id"`
+	Matched   bool   `json:"matched"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RuleResult captures the outcome of a single fraud rule for one evaluation.
+type RuleResult struct {
+	Rule      string  `json:"rule"`
+	Triggered bool    `json:"triggered"`
+	Score     float64 `json:"score"`
+	Action    string  `json:"action,omitempty"`
+	Reason    string  `json:"reason,omitempty"`
+	Shadow    bool    `json:"shadow,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	// Match is set by the sanctions screening rule when a watchlist entry matched.
+	Match *SanctionsMatch `json:"match,omitempty"`
+}
+
+// FraudDecision is the persisted audit record of a fraud evaluation.
+// It stores the input event together with every rule result so a decision
+// can be explained long after the FraudCheckEvent was published.
+type FraudDecision struct {
+	ID             string              `json:"id" gorm:"primaryKey"`
+	PaymentID      string              `json:"payment_id" gorm:"index;not null"`
+	CustomerID     string              `json:"customer_id" gorm:"index;not null"`
+	TraceID        string              `json:"trace_id"`
+	Status         string              `json:"status"`
+	Reason         string              `json:"reason,omitempty"`
+	ShadowStatus   string              `json:"shadow_status,omitempty"`
+	Score          float64             `json:"score"`
+	RuleSetVersion string              `json:"rule_set_version"`
+	Rules          []RuleResult        `json:"rules" gorm:"serializer:json"`
+	Event          PaymentCreatedEvent `json:"event" gorm:"serializer:json"`
+	Customer       CustomerProfile     `json:"customer" gorm:"serializer:json"`
+	EvaluatedAt    time.Time           `json:"evaluated_at" gorm:"index"`
+}
+
+func (d *FraudDecision) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+
+	return
+}
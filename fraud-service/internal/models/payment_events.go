@@ -1,14 +1,7 @@
 package models
 
-import "time"
+import contracts "github.com/jeffleon2/draftea-event-contracts"
 
-type PaymentCreatedEvent struct {
-	ID         string    `json:"id"`
-	Amount     float64   `json:"amount"`
-	Currency   string    `json:"currency"`
-	Status     string    `json:"status"`
-	Method     string    `json:"method"`
-	CustomerID string    `json:"customer_id"`
-	TraceID    string    `json:"trace_id"`
-	CreatedAt  time.Time `json:"created_at"`
-}
+// PaymentCreatedEvent is consumed from payment-service. Its schema lives in
+// the shared event-contracts module.
+type PaymentCreatedEvent = contracts.PaymentCreatedEvent
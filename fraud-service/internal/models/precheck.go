@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PreCheckResult is the synchronous, non-binding fraud decision returned
+// before a payment is created. It is never published nor stored in the audit log.
+type PreCheckResult struct {
+	PaymentID      string       `json:"payment_id,omitempty"`
+	CustomerID     string       `json:"customer_id"`
+	Status         string       `json:"status"`
+	Reason         string       `json:"reason,omitempty"`
+	Score          float64      `json:"score"`
+	RuleSetVersion string       `json:"rule_set_version"`
+	Rules          []RuleResult `json:"rules"`
+	CheckedAt      time.Time    `json:"checked_at"`
+}
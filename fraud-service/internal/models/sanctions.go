@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// SanctionsMatch records the watchlist entry that produced a screening hit.
+type SanctionsMatch struct {
+	ListVersion    string   `json:"list_version"`
+	EntryUID       string   `json:"entry_uid"`
+	EntryName      string   `json:"entry_name"`
+	MatchedName    string   `json:"matched_name"`
+	Programs       []string `json:"programs,omitempty"`
+	Countries      []string `json:"countries,omitempty"`
+	Score          float64  `json:"score"`
+	CountryMatched bool     `json:"country_matched"`
+}
+
+// SanctionsListInfo describes the sanctions list snapshot currently in use.
+type SanctionsListInfo struct {
+	Version     string    `json:"version"`
+	Source      string    `json:"source"`
+	PublishedAt string    `json:"published_at,omitempty"`
+	LoadedAt    time.Time `json:"loaded_at"`
+	Entries     int       `json:"entries"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ShadowReport compares shadow rule decisions with live decisions over a period.
+type ShadowReport struct {
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Evaluations int                `json:"evaluations"`
+	Changed     int                `json:"changed"`
+	Rules       []ShadowRuleReport `json:"rules"`
+}
+
+// ShadowRuleReport summarizes how a single shadow rule behaved against live traffic.
+// NewlyFlagged counts payments the shadow rule flagged while the live decision approved them.
+type ShadowRuleReport struct {
+	Rule          string `json:"rule"`
+	Evaluated     int    `json:"evaluated"`
+	Triggered     int    `json:"triggered"`
+	Agreements    int    `json:"agreements"`
+	Disagreements int    `json:"disagreements"`
+	NewlyFlagged  int    `json:"newly_flagged"`
+}
@@ -0,0 +1,61 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+)
+
+// encodedMessage is a message ready to be written by any transport.
+type encodedMessage struct {
+	key   []byte
+	value []byte
+	// contentType is empty for plain JSON messages.
+	contentType string
+	// traceID is the trace ID of the event, or else of the context.
+	traceID string
+}
+
+// encode encodes message and keys it when it implements Keyed. Events (see
+// contracts.Event) are wrapped in an envelope, must match their JSON Schema
+// and are encoded by serializer; other messages, such as DLQ messages, are
+// plain JSON. Events without a trace ID take the one of ctx.
+func encode(ctx context.Context, serializer contracts.Serializer, message interface{}) (encodedMessage, error) {
+	msg := encodedMessage{traceID: contracts.TraceID(ctx)}
+	if keyed, ok := message.(Keyed); ok {
+		msg.key = []byte(keyed.MessageKey())
+	}
+
+	event, ok := message.(contracts.Event)
+	if !ok {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return encodedMessage{}, fmt.Errorf("error marshaling message: %w", err)
+		}
+		msg.value = data
+		return msg, nil
+	}
+
+	envelope, err := contracts.New(models.ServiceName, event)
+	if err != nil {
+		return encodedMessage{}, err
+	}
+	if envelope.TraceID == "" {
+		envelope.TraceID = msg.traceID
+	}
+	msg.traceID = envelope.TraceID
+	if err := envelope.Validate(); err != nil {
+		return encodedMessage{}, fmt.Errorf("error publishing %s event: %w", event.EventType(), err)
+	}
+	data, err := serializer.Marshal(envelope, event)
+	if err != nil {
+		return encodedMessage{}, fmt.Errorf("error marshaling %s event: %w", event.EventType(), err)
+	}
+	msg.value = data
+	msg.contentType = serializer.ContentType()
+
+	return msg, nil
+}
@@ -2,32 +2,64 @@ package publisher
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"slices"
 	"time"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-fraud-service/config"
+	"github.com/jeffleon2/draftea-fraud-service/internal/breaker"
+	"github.com/jeffleon2/draftea-fraud-service/internal/logging"
+	"github.com/jeffleon2/draftea-fraud-service/internal/metrics"
+	"github.com/jeffleon2/draftea-fraud-service/internal/tracing"
 	kafka "github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
 )
 
+// Keyed is implemented by events that carry a partition key. Messages with
+// the same key go to the same partition and are consumed in order.
+type Keyed interface {
+	MessageKey() string
+}
+
+// DeliveryCallback is called with every batch the writers deliver or fail to
+// deliver. In async mode it is the only way to learn about failed publishes.
+type DeliveryCallback func(topic string, messages []kafka.Message, err error)
+
 // KafkaPublisher manages Kafka message publishing with retry capabilities.
 // It maintains a pool of Kafka writers, one per topic, and implements
 // exponential backoff retry logic for failed publish attempts.
 type KafkaPublisher struct {
 	Writers     map[string]*kafka.Writer
 	RetryConfig config.RetryConfig
+	OnDelivery  DeliveryCallback
+	// Serializer encodes events; JSON unless configured otherwise.
+	Serializer contracts.Serializer
+	// Breaker stops publish attempts while Kafka keeps failing.
+	Breaker *breaker.Breaker
 }
 
-// NewKafkaPublisher creates a new KafkaPublisher with writers for the specified topics.
+// NewKafkaPublisher creates a new KafkaPublisher with writers for the specified topics,
+// connected to every broker through transport (TLS and SASL).
 // It initializes default retry configuration values if not provided:
 //   - MaxAttempts: 5
 //   - BaseDelay: 100ms
 //   - MaxDelay: 10s
 //
-// Each topic gets its own dedicated Kafka writer using LeastBytes balancing strategy.
-func NewKafkaPublisher(kafkaURL string, topics []string, retryConfig config.RetryConfig) *KafkaPublisher {
+// Each topic gets its own dedicated Kafka writer that partitions by message key (Hash balancer)
+// and batches, compresses and acknowledges messages as publisherConfig says.
+// Every write goes through cb.
+func NewKafkaPublisher(
+	brokers []string,
+	transport *kafka.Transport,
+	topics []string,
+	retryConfig config.RetryConfig,
+	publisherConfig config.PublisherConfig,
+	cb *breaker.Breaker,
+) *KafkaPublisher {
 	writers := make(map[string]*kafka.Writer)
 	if retryConfig.MaxAttempts == 0 {
 		retryConfig.MaxAttempts = 5
@@ -39,54 +71,161 @@ func NewKafkaPublisher(kafkaURL string, topics []string, retryConfig config.Retr
 		retryConfig.MaxDelay = 10 * time.Second
 	}
 
+	p := &KafkaPublisher{
+		Writers:     writers,
+		RetryConfig: retryConfig,
+		Breaker:     cb,
+		Serializer:  publisherConfig.Serializer,
+	}
+	if p.Serializer == nil {
+		p.Serializer = contracts.JSON
+	}
+
 	for _, t := range topics {
 		writers[t] = &kafka.Writer{
-			Addr:     kafka.TCP(kafkaURL),
-			Topic:    t,
-			Balancer: &kafka.LeastBytes{},
+			Addr:         kafka.TCP(brokers...),
+			Topic:        t,
+			Balancer:     &kafka.Hash{},
+			Transport:    transport,
+			BatchSize:    publisherConfig.BatchSize,
+			BatchTimeout: publisherConfig.BatchTimeout,
+			Compression:  publisherConfig.Compression,
+			RequiredAcks: publisherConfig.RequiredAcks,
+			Async:        publisherConfig.Async && !slices.Contains(publisherConfig.SyncTopics, t),
+			Completion:   p.completion(t),
 		}
 	}
 
-	return &KafkaPublisher{
-		Writers:     writers,
-		RetryConfig: retryConfig,
-	}
+	return p
 }
 
 // Publish sends a message to the specified Kafka topic with automatic retry on failure.
-// The message is marshaled to JSON before publishing. Returns an error if:
+// The message is marshaled to JSON before publishing and keyed when it implements
+// Keyed. Returns an error if:
 //   - No writer is configured for the topic
 //   - JSON marshaling fails
 //   - Publishing fails after all retry attempts
+//
+// On an async writer it returns as soon as the message is queued.
 func (p *KafkaPublisher) Publish(ctx context.Context, topic string, message interface{}) error {
 	writer, ok := p.Writers[topic]
 	if !ok {
 		return fmt.Errorf("error no writer configured for topic %s", topic)
 	}
 
-	data, err := json.Marshal(message)
+	msg, err := p.newMessage(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	return p.publishWithRetry(ctx, writer, topic, msg)
+}
+
+// PublishBatch sends several messages to topic in a single write, so the
+// writer can batch and compress them together. Either all of them are
+// accepted or the call fails.
+func (p *KafkaPublisher) PublishBatch(ctx context.Context, topic string, messages []interface{}) error {
+	writer, ok := p.Writers[topic]
+	if !ok {
+		return fmt.Errorf("error no writer configured for topic %s", topic)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	msgs := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		msg, err := p.newMessage(ctx, message)
+		if err != nil {
+			return err
+		}
+		msgs[i] = msg
+	}
+
+	return p.publishWithRetry(ctx, writer, topic, msgs...)
+}
+
+// PublishMessage sends a raw Kafka message, keeping its key and headers.
+// Used to move consumed messages to retry topics.
+func (p *KafkaPublisher) PublishMessage(ctx context.Context, topic string, msg kafka.Message) error {
+	writer, ok := p.Writers[topic]
+	if !ok {
+		return fmt.Errorf("error no writer configured for topic %s", topic)
+	}
+
+	return p.publishWithRetry(ctx, writer, topic, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: msg.Headers,
+		Time:    time.Now(),
+	})
+}
+
+// newMessage encodes message (see encode) as a Kafka message. The message
+// time marks when it was handed to the writer.
+func (p *KafkaPublisher) newMessage(ctx context.Context, message interface{}) (kafka.Message, error) {
+	encoded, err := encode(ctx, p.Serializer, message)
 	if err != nil {
-		return fmt.Errorf("error marshaling message: %w", err)
+		return kafka.Message{}, err
 	}
 
-	msg := kafka.Message{
-		Value: data,
+	msg := kafka.Message{Key: encoded.key, Value: encoded.value, Time: time.Now()}
+	if encoded.contentType != "" {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: contracts.HeaderContentType, Value: []byte(encoded.contentType)})
 	}
+	if encoded.traceID != "" {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: contracts.HeaderTraceID, Value: []byte(encoded.traceID)})
+	}
+	return msg, nil
+}
+
+// completion records the delivery metrics of topic's writer and reports
+// the batch to OnDelivery.
+func (p *KafkaPublisher) completion(topic string) func(messages []kafka.Message, err error) {
+	return func(messages []kafka.Message, err error) {
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+
+		metrics.PublishBatchSize.WithLabelValues(topic).Observe(float64(len(messages)))
+		metrics.PublishedMessagesTotal.WithLabelValues(topic, result).Add(float64(len(messages)))
+		for _, msg := range messages {
+			metrics.PublishDuration.WithLabelValues(topic, result).Observe(time.Since(msg.Time).Seconds())
+		}
 
-	return p.publishWithRetry(ctx, writer, msg, topic)
+		if p.OnDelivery != nil {
+			p.OnDelivery(topic, messages, err)
+		} else if err != nil && p.Writers[topic].Async {
+			logrus.WithField(logging.FieldTopic, topic).WithError(err).Errorf("Async delivery of %d messages failed", len(messages))
+		}
+	}
 }
 
-// publishWithRetry attempts to publish a message with exponential backoff retry logic.
-// It will retry up to MaxAttempts times, with increasing delays between attempts.
+// publishWithRetry attempts to publish messages with exponential backoff retry logic.
+// It will retry up to MaxAttempts times, with increasing delays between attempts,
+// and gives up at once when the circuit breaker is open.
 // Returns nil on successful publish, or an error if all attempts fail or context is cancelled.
-func (p *KafkaPublisher) publishWithRetry(ctx context.Context, writer *kafka.Writer, msg kafka.Message, topic string) error {
+// The publish runs in a producer span whose context goes in the headers of msgs.
+func (p *KafkaPublisher) publishWithRetry(ctx context.Context, writer *kafka.Writer, topic string, msgs ...kafka.Message) (err error) {
+	ctx, span := tracing.StartProducer(ctx, tracing.SystemKafka, topic)
+	defer func() { tracing.End(span, err) }()
+	for i := range msgs {
+		tracing.Inject(ctx, (*tracing.KafkaHeaders)(&msgs[i].Headers))
+	}
+
 	var lastErr error
 
 	for attempt := 0; attempt < p.RetryConfig.MaxAttempts; attempt++ {
-		err := writer.WriteMessages(ctx, msg)
+		err := p.Breaker.Execute(func() error {
+			return writer.WriteMessages(ctx, msgs...)
+		})
+		if errors.Is(err, breaker.ErrOpen) {
+			return fmt.Errorf("failed to publish message to topic '%s': %w", topic, err)
+		}
 		if err == nil {
 			if attempt > 0 {
-				fmt.Printf("[Kafka Publisher] Message successfully published to topic '%s' after %d attempts\n", topic, attempt+1)
+				logging.FromContext(ctx).WithField(logging.FieldTopic, topic).Infof("Message published after %d attempts", attempt+1)
 			}
 			return nil
 		}
@@ -97,10 +236,10 @@ func (p *KafkaPublisher) publishWithRetry(ctx context.Context, writer *kafka.Wri
 			break
 		}
 
-		delay := p.calculateBackoff(attempt)
+		delay := calculateBackoff(p.RetryConfig, attempt)
 
-		fmt.Printf("[Kafka Publisher] Retry %d/%d for topic '%s' after %v: %v\n",
-			attempt+1, p.RetryConfig.MaxAttempts, topic, delay, err)
+		logging.FromContext(ctx).WithField(logging.FieldTopic, topic).WithError(err).Warnf("Publish retry %d/%d in %v",
+			attempt+1, p.RetryConfig.MaxAttempts, delay)
 
 		select {
 		case <-time.After(delay):
@@ -117,14 +256,14 @@ func (p *KafkaPublisher) publishWithRetry(ctx context.Context, writer *kafka.Wri
 // calculateBackoff computes the delay for the next retry attempt using exponential backoff.
 // The delay is calculated as: 2^attempt * BaseDelay, capped at MaxDelay.
 // If Jitter is enabled, adds random variation (±30%) to prevent thundering herd.
-func (p *KafkaPublisher) calculateBackoff(attempt int) time.Duration {
-	delay := time.Duration(math.Pow(2, float64(attempt))) * p.RetryConfig.BaseDelay
+func calculateBackoff(cfg config.RetryConfig, attempt int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempt))) * cfg.BaseDelay
 
-	if delay > p.RetryConfig.MaxDelay {
-		delay = p.RetryConfig.MaxDelay
+	if delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
 	}
 
-	if p.RetryConfig.Jitter {
+	if cfg.Jitter {
 		jitter := time.Duration(rand.Float64() * float64(delay) * 0.3)
 		delay = delay + jitter - time.Duration(float64(delay)*0.15)
 	}
@@ -0,0 +1,114 @@
+package posgrest
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jeffleon2/draftea-fraud-service/internal/breaker"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// decisionRepository persists fraud decisions for auditing.
+type decisionRepository struct {
+	db      *gorm.DB
+	breaker *breaker.Breaker
+}
+
+// NewDecisionRepository creates a repository for the fraud decision audit log.
+// Every query goes through the circuit breaker cb.
+func NewDecisionRepository(db *gorm.DB, cb *breaker.Breaker) *decisionRepository {
+	return &decisionRepository{
+		db,
+		cb,
+	}
+}
+
+// IsFailure reports whether err means Postgres is failing. Missing records
+// and cancelled requests do not count against the circuit breaker: the first
+// is an answer from a healthy database and the second is the caller giving
+// up, so neither should open it and reject every other request.
+func IsFailure(err error) bool {
+	return !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, context.Canceled)
+}
+
+// Create inserts a new decision into the audit log.
+func (r *decisionRepository) Create(ctx context.Context, decision *models.FraudDecision) error {
+	return r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).Create(decision).Error
+	})
+}
+
+// GetByPaymentID returns every decision recorded for a payment, newest first.
+func (r *decisionRepository) GetByPaymentID(ctx context.Context, paymentID string) (*[]models.FraudDecision, error) {
+	var decisions []models.FraudDecision
+	err := r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).
+			Where("payment_id = ?", paymentID).
+			Order("evaluated_at DESC").
+			Find(&decisions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &decisions, nil
+}
+
+// GetByCustomerID returns a page of decisions recorded for a customer, newest first.
+func (r *decisionRepository) GetByCustomerID(ctx context.Context, customerID string, limit, offset int) (*[]models.FraudDecision, error) {
+	var decisions []models.FraudDecision
+	err := r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).
+			Where("customer_id = ?", customerID).
+			Order("evaluated_at DESC").
+			Limit(limit).
+			Offset(offset).
+			Find(&decisions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &decisions, nil
+}
+
+// GetBetween returns every decision evaluated in the [from, to) interval, oldest first.
+func (r *decisionRepository) GetBetween(ctx context.Context, from, to time.Time) (*[]models.FraudDecision, error) {
+	var decisions []models.FraudDecision
+	err := r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).
+			Where("evaluated_at >= ? AND evaluated_at < ?", from, to).
+			Order("evaluated_at ASC").
+			Find(&decisions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &decisions, nil
+}
+
+// GetCustomerProfile derives enrichment data for a customer from the audit log:
+// the days since the customer was first evaluated and the number of
+// evaluations in the 24 hours before now. Unknown customers get a zero profile.
+func (r *decisionRepository) GetCustomerProfile(ctx context.Context, customerID string, now time.Time) (*models.CustomerProfile, error) {
+	var stats struct {
+		FirstSeen *time.Time
+		Recent    int64
+	}
+	err := r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).
+			Model(&models.FraudDecision{}).
+			Select("MIN(evaluated_at) AS first_seen, COUNT(*) FILTER (WHERE evaluated_at >= ?) AS recent", now.Add(-24*time.Hour)).
+			Where("customer_id = ?", customerID).
+			Scan(&stats).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.CustomerProfile{Payments24h: stats.Recent}
+	if stats.FirstSeen != nil {
+		profile.AgeDays = int64(now.Sub(*stats.FirstSeen).Hours() / 24)
+	}
+	return profile, nil
+}
@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+)
+
+// costLimit bounds the work a single expression may do per evaluation.
+const costLimit = 10000
+
+// CompileError reports an expression that failed to parse or type-check.
+type CompileError struct {
+	Expression string
+	Issues     string
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("invalid expression %q: %s", e.Expression, e.Issues)
+}
+
+// newEnv declares the variables available to expressions. Payment fields are
+// exposed at the top level and enrichment data under customer, e.g.
+//
+//	amount > 5000.0 && method == "PAYPAL" && customer.age_days < 7
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		ext.NativeTypes(reflect.TypeOf(models.CustomerProfile{}), ext.ParseStructTag("json")),
+		cel.CrossTypeNumericComparisons(true),
+		cel.Variable("id", cel.StringType),
+		cel.Variable("amount", cel.DoubleType),
+		cel.Variable("currency", cel.StringType),
+		cel.Variable("status", cel.StringType),
+		cel.Variable("method", cel.StringType),
+		cel.Variable("customer_id", cel.StringType),
+		cel.Variable("customer_name", cel.StringType),
+		cel.Variable("customer_country", cel.StringType),
+		cel.Variable("created_at", cel.TimestampType),
+		cel.Variable("customer", cel.ObjectType("models.CustomerProfile")),
+	)
+}
+
+// Expression is a compiled, type-checked CEL condition.
+type Expression struct {
+	source  string
+	program cel.Program
+}
+
+// CompileExpression parses and type-checks a CEL expression.
+// The expression must evaluate to a bool; otherwise a *CompileError is returned.
+func CompileExpression(source string) (*Expression, error) {
+	env, err := newEnv()
+	if err != nil {
+		return nil, fmt.Errorf("error creating expression environment: %w", err)
+	}
+
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, &CompileError{Expression: source, Issues: issues.Err().Error()}
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, &CompileError{
+			Expression: source,
+			Issues:     fmt.Sprintf("expression must return bool, got %s", ast.OutputType()),
+		}
+	}
+
+	program, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, &CompileError{Expression: source, Issues: err.Error()}
+	}
+
+	return &Expression{source: source, program: program}, nil
+}
+
+// Matches evaluates the expression against the input.
+func (e *Expression) Matches(input Input) (bool, error) {
+	out, _, err := e.program.Eval(map[string]any{
+		"id":               input.Event.ID,
+		"amount":           input.Event.Amount,
+		"currency":         input.Event.Currency,
+		"status":           input.Event.Status,
+		"method":           input.Event.Method,
+		"customer_id":      input.Event.CustomerID,
+		"customer_name":    input.Event.CustomerName,
+		"customer_country": input.Event.CustomerCountry,
+		"created_at":       input.Event.CreatedAt,
+		"customer":         input.Customer,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error evaluating expression %q: %w", e.source, err)
+	}
+
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q returned %T, expected bool", e.source, out.Value())
+	}
+	return matched, nil
+}
+
+// ExpressionRule triggers when its CEL expression evaluates to true.
+// Evaluation errors never trigger the rule; they are recorded in the result instead.
+type ExpressionRule struct {
+	RuleName   string
+	Expression *Expression
+	Weight     float64
+	Action     string
+	Reason     string
+}
+
+// Name returns the rule identifier recorded in the audit log.
+func (r *ExpressionRule) Name() string {
+	return r.RuleName
+}
+
+// Evaluate runs the compiled expression against the input.
+func (r *ExpressionRule) Evaluate(input Input) models.RuleResult {
+	result := models.RuleResult{Rule: r.Name()}
+
+	matched, err := r.Expression.Matches(input)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if matched {
+		result.Triggered = true
+		result.Score = r.Weight
+		result.Action = r.Action
+		result.Reason = r.Reason
+		if result.Reason == "" {
+			result.Reason = fmt.Sprintf("Matched rule %s", r.RuleName)
+		}
+	}
+
+	return result
+}
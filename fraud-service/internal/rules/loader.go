@@ -0,0 +1,138 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+)
+
+const (
+	TypeAmountAbove = "amount_above"
+	TypeExpression  = "expression"
+)
+
+// File is the on-disk representation of a rule set.
+type File struct {
+	Version string       `json:"version"`
+	Rules   []Definition `json:"rules"`
+}
+
+// Definition describes a single configurable rule.
+// Shadow rules are evaluated and logged but never change the published decision.
+type Definition struct {
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Threshold  float64 `json:"threshold,omitempty"`
+	Expression string  `json:"expression,omitempty"`
+	Weight     float64 `json:"weight,omitempty"`
+	Action     string  `json:"action,omitempty"`
+	Reason     string  `json:"reason,omitempty"`
+	Shadow     bool    `json:"shadow,omitempty"`
+}
+
+// Load reads a JSON rule file from disk and builds the rule set it describes.
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rules file %s: %w", path, err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing rules file %s: %w", path, err)
+	}
+
+	return Build(file)
+}
+
+// Build validates every definition and assembles the rule set.
+// All invalid definitions are reported together so a broken file
+// can be fixed in one pass.
+func Build(file File) (*RuleSet, error) {
+	if file.Version == "" {
+		return nil, errors.New("rule set version is required")
+	}
+
+	ruleSet := &RuleSet{Version: file.Version}
+	seen := make(map[string]bool, len(file.Rules))
+	var errs []error
+
+	for i, def := range file.Rules {
+		if def.Name == "" {
+			errs = append(errs, fmt.Errorf("rule #%d: name is required", i))
+			continue
+		}
+		if seen[def.Name] {
+			errs = append(errs, fmt.Errorf("rule %q: duplicated name", def.Name))
+			continue
+		}
+		seen[def.Name] = true
+
+		rule, err := def.build()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", def.Name, err))
+			continue
+		}
+
+		if def.Shadow {
+			ruleSet.Shadow = append(ruleSet.Shadow, rule)
+		} else {
+			ruleSet.Rules = append(ruleSet.Rules, rule)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid rule set %s: %w", file.Version, errors.Join(errs...))
+	}
+
+	return ruleSet, nil
+}
+
+func (d Definition) build() (Rule, error) {
+	action := d.Action
+	if action == "" {
+		action = models.PaymentStatusDeclined
+	}
+	if action != models.PaymentStatusDeclined && action != models.PaymentStatusReview {
+		return nil, fmt.Errorf("unsupported action %q", d.Action)
+	}
+
+	weight := d.Weight
+	if weight == 0 {
+		weight = 1
+	}
+
+	switch d.Type {
+	case TypeAmountAbove:
+		if d.Threshold <= 0 {
+			return nil, errors.New("threshold must be greater than zero")
+		}
+		return &HighValueRule{
+			RuleName:  d.Name,
+			Threshold: d.Threshold,
+			Weight:    weight,
+			Action:    action,
+			Reason:    d.Reason,
+		}, nil
+	case TypeExpression:
+		if d.Expression == "" {
+			return nil, errors.New("expression is required")
+		}
+		expression, err := CompileExpression(d.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return &ExpressionRule{
+			RuleName:   d.Name,
+			Expression: expression,
+			Weight:     weight,
+			Action:     action,
+			Reason:     d.Reason,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule type %q", d.Type)
+	}
+}
@@ -0,0 +1,170 @@
+package rules
+
+import (
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+)
+
+// Rule evaluates a single fraud condition against a payment event.
+// Implementations must be safe for concurrent use.
+type Rule interface {
+	Name() string
+	Evaluate(input Input) models.RuleResult
+}
+
+// Input is what rules are evaluated against: the payment event
+// plus the enrichment data known about the customer.
+type Input struct {
+	Event    models.PaymentCreatedEvent `json:"event"`
+	Customer models.CustomerProfile     `json:"customer"`
+}
+
+// RuleSet is a versioned, ordered collection of rules.
+// The version is stored with every decision so audits can tell
+// which rules were in force when a payment was evaluated.
+//
+// Shadow rules are evaluated and recorded alongside the live ones
+// but never influence the live decision.
+type RuleSet struct {
+	Version string
+	Rules   []Rule
+	Shadow  []Rule
+}
+
+// Evaluation is the aggregated result of running a RuleSet over one event.
+// ShadowStatus and ShadowReason hold the decision that would have been
+// taken if the shadow rules were live.
+type Evaluation struct {
+	Status       string
+	Reason       string
+	Score        float64
+	Version      string
+	Results      []models.RuleResult
+	ShadowStatus string
+	ShadowReason string
+}
+
+// NewRuleSet creates a RuleSet with the given version and rules.
+func NewRuleSet(version string, rules ...Rule) *RuleSet {
+	return &RuleSet{
+		Version: version,
+		Rules:   rules,
+	}
+}
+
+// Default returns the built-in rule set, which flags transactions
+// above the given amount threshold.
+func Default(version string, highValueThreshold float64) *RuleSet {
+	return NewRuleSet(version, &HighValueRule{Threshold: highValueThreshold, Weight: 1})
+}
+
+// Evaluate runs every rule in order and aggregates the results.
+// The score is the sum of the weights of the triggered live rules. The most
+// severe action among the triggered live rules (DECLINED over REVIEW)
+// determines the final status; on ties the first rule provides the reason.
+// Shadow rules run afterwards and only affect the shadow decision.
+func (rs *RuleSet) Evaluate(input Input) Evaluation {
+	evaluation := Evaluation{
+		Status:  models.PaymentStatusApproved,
+		Version: rs.Version,
+		Results: make([]models.RuleResult, 0, len(rs.Rules)+len(rs.Shadow)),
+	}
+
+	for _, rule := range rs.Rules {
+		result := rule.Evaluate(input)
+		evaluation.Results = append(evaluation.Results, result)
+		if !result.Triggered {
+			continue
+		}
+
+		evaluation.Score += result.Score
+		if severity(result.Action) > severity(evaluation.Status) {
+			evaluation.Status = result.Action
+			evaluation.Reason = result.Reason
+		}
+	}
+
+	evaluation.ShadowStatus = evaluation.Status
+	evaluation.ShadowReason = evaluation.Reason
+	for _, rule := range rs.Shadow {
+		result := rule.Evaluate(input)
+		result.Shadow = true
+		evaluation.Results = append(evaluation.Results, result)
+		if result.Triggered && severity(result.Action) > severity(evaluation.ShadowStatus) {
+			evaluation.ShadowStatus = result.Action
+			evaluation.ShadowReason = result.Reason
+		}
+	}
+
+	return evaluation
+}
+
+// severity orders decisions so a stricter action always wins.
+func severity(status string) int {
+	switch status {
+	case models.PaymentStatusDeclined:
+		return 2
+	case models.PaymentStatusReview:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ShadowResults returns the results produced by shadow rules.
+func (e Evaluation) ShadowResults() []models.RuleResult {
+	var results []models.RuleResult
+	for _, result := range e.Results {
+		if result.Shadow {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// LiveResults returns the results produced by live rules.
+func (e Evaluation) LiveResults() []models.RuleResult {
+	var results []models.RuleResult
+	for _, result := range e.Results {
+		if !result.Shadow {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// HighValueRule declines payments whose amount exceeds Threshold.
+// RuleName, Action and Reason fall back to the built-in values when empty.
+type HighValueRule struct {
+	RuleName  string
+	Threshold float64
+	Weight    float64
+	Action    string
+	Reason    string
+}
+
+// Name returns the rule identifier recorded in the audit log.
+func (r *HighValueRule) Name() string {
+	if r.RuleName != "" {
+		return r.RuleName
+	}
+	return "high_value_amount"
+}
+
+// Evaluate flags the event when its amount is strictly above the threshold.
+func (r *HighValueRule) Evaluate(input Input) models.RuleResult {
+	result := models.RuleResult{Rule: r.Name()}
+	if input.Event.Amount > r.Threshold {
+		result.Triggered = true
+		result.Score = r.Weight
+		result.Action = r.Action
+		if result.Action == "" {
+			result.Action = models.PaymentStatusDeclined
+		}
+		result.Reason = r.Reason
+		if result.Reason == "" {
+			result.Reason = "High-value transaction suspicious"
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,191 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubRule struct {
+	name   string
+	result models.RuleResult
+}
+
+func (r stubRule) Name() string { return r.name }
+
+func (r stubRule) Evaluate(rules.Input) models.RuleResult { return r.result }
+
+func TestDefault_HighValueDeclined(t *testing.T) {
+	ruleSet := rules.Default("v1", 10000)
+
+	evaluation := ruleSet.Evaluate(rules.Input{Event: models.PaymentCreatedEvent{ID: "payment-1", Amount: 10000.01}})
+
+	assert.Equal(t, models.PaymentStatusDeclined, evaluation.Status)
+	assert.Equal(t, "High-value transaction suspicious", evaluation.Reason)
+	assert.Equal(t, "v1", evaluation.Version)
+	assert.Equal(t, 1.0, evaluation.Score)
+	assert.Len(t, evaluation.Results, 1)
+	assert.Equal(t, "high_value_amount", evaluation.Results[0].Rule)
+	assert.True(t, evaluation.Results[0].Triggered)
+}
+
+func TestDefault_ThresholdApproved(t *testing.T) {
+	ruleSet := rules.Default("v1", 10000)
+
+	evaluation := ruleSet.Evaluate(rules.Input{Event: models.PaymentCreatedEvent{ID: "payment-1", Amount: 10000}})
+
+	assert.Equal(t, models.PaymentStatusApproved, evaluation.Status)
+	assert.Empty(t, evaluation.Reason)
+	assert.Zero(t, evaluation.Score)
+	assert.Len(t, evaluation.Results, 1)
+	assert.False(t, evaluation.Results[0].Triggered)
+}
+
+func TestEvaluate_FirstTriggeredRuleDecidesAndScoresAdd(t *testing.T) {
+	ruleSet := rules.NewRuleSet("v2",
+		stubRule{name: "quiet", result: models.RuleResult{Rule: "quiet"}},
+		stubRule{name: "first", result: models.RuleResult{Rule: "first", Triggered: true, Score: 2, Action: models.PaymentStatusDeclined, Reason: "first reason"}},
+		stubRule{name: "second", result: models.RuleResult{Rule: "second", Triggered: true, Score: 3, Action: models.PaymentStatusDeclined, Reason: "second reason"}},
+	)
+
+	evaluation := ruleSet.Evaluate(rules.Input{})
+
+	assert.Equal(t, models.PaymentStatusDeclined, evaluation.Status)
+	assert.Equal(t, "first reason", evaluation.Reason)
+	assert.Equal(t, 5.0, evaluation.Score)
+	assert.Equal(t, "v2", evaluation.Version)
+	assert.Len(t, evaluation.Results, 3)
+}
+
+func TestEvaluate_ShadowRulesDoNotChangeLiveDecision(t *testing.T) {
+	ruleSet := rules.Default("v1", 10000)
+	ruleSet.Shadow = []rules.Rule{&rules.HighValueRule{RuleName: "high_value_5k", Threshold: 5000, Weight: 1}}
+
+	evaluation := ruleSet.Evaluate(rules.Input{Event: models.PaymentCreatedEvent{ID: "payment-1", Amount: 7000}})
+
+	assert.Equal(t, models.PaymentStatusApproved, evaluation.Status)
+	assert.Empty(t, evaluation.Reason)
+	assert.Zero(t, evaluation.Score)
+	assert.Equal(t, models.PaymentStatusDeclined, evaluation.ShadowStatus)
+	assert.Len(t, evaluation.Results, 2)
+
+	shadow := evaluation.ShadowResults()
+	assert.Len(t, shadow, 1)
+	assert.Equal(t, "high_value_5k", shadow[0].Rule)
+	assert.True(t, shadow[0].Triggered)
+	assert.True(t, shadow[0].Shadow)
+}
+
+func TestBuild_SplitsLiveAndShadowRules(t *testing.T) {
+	ruleSet, err := rules.Build(rules.File{
+		Version: "2025-01-15",
+		Rules: []rules.Definition{
+			{Name: "high_value_amount", Type: rules.TypeAmountAbove, Threshold: 10000},
+			{Name: "high_value_5k", Type: rules.TypeAmountAbove, Threshold: 5000, Shadow: true},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-01-15", ruleSet.Version)
+	assert.Len(t, ruleSet.Rules, 1)
+	assert.Len(t, ruleSet.Shadow, 1)
+	assert.Equal(t, "high_value_5k", ruleSet.Shadow[0].Name())
+}
+
+func TestBuild_ReportsAllInvalidRules(t *testing.T) {
+	_, err := rules.Build(rules.File{
+		Version: "broken",
+		Rules: []rules.Definition{
+			{Name: "no_threshold", Type: rules.TypeAmountAbove},
+			{Name: "unknown", Type: "velocity"},
+			{Name: "no_threshold", Type: rules.TypeAmountAbove, Threshold: 1},
+		},
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `rule "no_threshold": threshold must be greater than zero`)
+	assert.Contains(t, err.Error(), `rule "unknown": unknown rule type "velocity"`)
+	assert.Contains(t, err.Error(), `rule "no_threshold": duplicated name`)
+}
+
+func TestLoad_ExampleFile(t *testing.T) {
+	ruleSet, err := rules.Load("../../rules.example.json")
+
+	assert.NoError(t, err)
+	assert.Len(t, ruleSet.Rules, 1)
+	assert.Len(t, ruleSet.Shadow, 2)
+}
+
+func TestCompileExpression_MatchesEnrichedInput(t *testing.T) {
+	expression, err := rules.CompileExpression(`amount > 5000 && method == "PAYPAL" && customer.age_days < 7`)
+	assert.NoError(t, err)
+
+	matched, err := expression.Matches(rules.Input{
+		Event:    models.PaymentCreatedEvent{Amount: 5000.5, Method: "PAYPAL"},
+		Customer: models.CustomerProfile{AgeDays: 3, Payments24h: 1},
+	})
+	assert.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = expression.Matches(rules.Input{
+		Event:    models.PaymentCreatedEvent{Amount: 5000.5, Method: "credit_card"},
+		Customer: models.CustomerProfile{AgeDays: 3},
+	})
+	assert.NoError(t, err)
+	assert.False(t, matched)
+}
+
+func TestCompileExpression_RejectsInvalidExpressions(t *testing.T) {
+	for _, source := range []string{
+		`amount >`,
+		`amount + 1`,
+		`unknown_field == "x"`,
+		`method > 10`,
+	} {
+		_, err := rules.CompileExpression(source)
+
+		var compileErr *rules.CompileError
+		assert.ErrorAs(t, err, &compileErr, source)
+	}
+}
+
+func TestBuild_ExpressionRule(t *testing.T) {
+	ruleSet, err := rules.Build(rules.File{
+		Version: "2025-02-01",
+		Rules: []rules.Definition{
+			{Name: "new_customer_velocity", Type: rules.TypeExpression, Expression: `customer.payments_24h >= 5`, Reason: "Too many payments"},
+			{Name: "broken", Type: rules.TypeExpression, Expression: `customer.unknown`},
+		},
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, ruleSet)
+	assert.Contains(t, err.Error(), `rule "broken": invalid expression`)
+
+	ruleSet, err = rules.Build(rules.File{
+		Version: "2025-02-01",
+		Rules: []rules.Definition{
+			{Name: "new_customer_velocity", Type: rules.TypeExpression, Expression: `customer.payments_24h >= 5`, Reason: "Too many payments"},
+		},
+	})
+	assert.NoError(t, err)
+
+	evaluation := ruleSet.Evaluate(rules.Input{Customer: models.CustomerProfile{Payments24h: 5}})
+	assert.Equal(t, models.PaymentStatusDeclined, evaluation.Status)
+	assert.Equal(t, "Too many payments", evaluation.Reason)
+}
+
+func TestEvaluate_DeclineTakesPrecedenceOverReview(t *testing.T) {
+	ruleSet := rules.NewRuleSet("v3",
+		stubRule{name: "review", result: models.RuleResult{Rule: "review", Triggered: true, Score: 1, Action: models.PaymentStatusReview, Reason: "review reason"}},
+		stubRule{name: "decline", result: models.RuleResult{Rule: "decline", Triggered: true, Score: 1, Action: models.PaymentStatusDeclined, Reason: "decline reason"}},
+	)
+
+	evaluation := ruleSet.Evaluate(rules.Input{})
+
+	assert.Equal(t, models.PaymentStatusDeclined, evaluation.Status)
+	assert.Equal(t, "decline reason", evaluation.Reason)
+	assert.Equal(t, 2.0, evaluation.Score)
+}
@@ -0,0 +1,311 @@
+package screening
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+)
+
+// csvNull is the placeholder OFAC uses for empty fields in the CSV files.
+const csvNull = "-0-"
+
+// Entry is a single sanctioned party.
+type Entry struct {
+	UID       string   `json:"uid"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type,omitempty"`
+	Programs  []string `json:"programs,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+}
+
+// List is an immutable snapshot of a sanctions list.
+// The version is derived from the file contents, so reloading an
+// unchanged file yields the same version.
+type List struct {
+	Version     string
+	Source      string
+	PublishedAt string
+	LoadedAt    time.Time
+	Entries     []Entry
+
+	names []indexedName
+}
+
+// indexedName is a normalized entry name or alias ready for matching.
+type indexedName struct {
+	entry      int
+	name       string
+	normalized string
+}
+
+// Info summarizes the list for the HTTP API and logs.
+func (l *List) Info() models.SanctionsListInfo {
+	return models.SanctionsListInfo{
+		Version:     l.Version,
+		Source:      l.Source,
+		PublishedAt: l.PublishedAt,
+		LoadedAt:    l.LoadedAt,
+		Entries:     len(l.Entries),
+	}
+}
+
+// LoadFile reads an OFAC SDN list in CSV or XML format, chosen by extension.
+//
+// For CSV the path points to sdn.csv; the companion add.csv (addresses, used
+// for countries) and alt.csv (aliases) are read from the same directory when present.
+func LoadFile(path string) (*List, error) {
+	var (
+		list *List
+		err  error
+	)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		list, err = loadCSV(path)
+	case ".xml":
+		list, err = loadXML(path)
+	default:
+		return nil, fmt.Errorf("unsupported sanctions list format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Entries) == 0 {
+		return nil, fmt.Errorf("sanctions list %s has no entries", path)
+	}
+
+	list.Source = path
+	list.LoadedAt = time.Now()
+	list.index()
+	return list, nil
+}
+
+func loadCSV(path string) (*List, error) {
+	hash := sha256.New()
+
+	sdn, err := readCSV(path, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &List{}
+	byUID := make(map[string]int, len(sdn))
+	for _, record := range sdn {
+		// ent_num, SDN_Name, SDN_Type, Program, ...
+		if len(record) < 4 || csvValue(record[0]) == "" || csvValue(record[1]) == "" {
+			continue
+		}
+		byUID[csvValue(record[0])] = len(list.Entries)
+		list.Entries = append(list.Entries, Entry{
+			UID:      csvValue(record[0]),
+			Name:     csvValue(record[1]),
+			Type:     csvValue(record[2]),
+			Programs: splitPrograms(csvValue(record[3])),
+		})
+	}
+
+	dir := filepath.Dir(path)
+
+	// ent_num, Add_num, Address, City/State/Province/Postal Code, Country, Add_remarks
+	addresses, err := readOptionalCSV(filepath.Join(dir, "add.csv"), hash)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range addresses {
+		if len(record) < 5 {
+			continue
+		}
+		i, ok := byUID[csvValue(record[0])]
+		if country := csvValue(record[4]); ok && country != "" {
+			list.Entries[i].Countries = appendUnique(list.Entries[i].Countries, country)
+		}
+	}
+
+	// ent_num, alt_num, alt_type, alt_name, alt_remarks
+	aliases, err := readOptionalCSV(filepath.Join(dir, "alt.csv"), hash)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range aliases {
+		if len(record) < 4 {
+			continue
+		}
+		i, ok := byUID[csvValue(record[0])]
+		if alias := csvValue(record[3]); ok && alias != "" {
+			list.Entries[i].Aliases = appendUnique(list.Entries[i].Aliases, alias)
+		}
+	}
+
+	list.Version = version(hash)
+	return list, nil
+}
+
+func readOptionalCSV(path string, hash io.Writer) ([][]string, error) {
+	records, err := readCSV(path, hash)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return records, err
+}
+
+func readCSV(path string, hash io.Writer) ([][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sanctions file %s: %w", path, err)
+	}
+	hash.Write(data)
+
+	// The official files end with an EOF control character.
+	data = bytes.TrimRight(data, "\x1a\r\n ")
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sanctions file %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// sdnList mirrors the parts of the OFAC SDN.XML schema used for screening.
+type sdnList struct {
+	PublishDate string     `xml:"publshInformation>Publish_Date"`
+	Entries     []sdnEntry `xml:"sdnEntry"`
+}
+
+type sdnEntry struct {
+	UID           string       `xml:"uid"`
+	FirstName     string       `xml:"firstName"`
+	LastName      string       `xml:"lastName"`
+	Type          string       `xml:"sdnType"`
+	Programs      []string     `xml:"programList>program"`
+	Akas          []sdnName    `xml:"akaList>aka"`
+	Addresses     []sdnCountry `xml:"addressList>address"`
+	Nationalities []sdnCountry `xml:"nationalityList>nationality"`
+	Citizenships  []sdnCountry `xml:"citizenshipList>citizenship"`
+}
+
+type sdnName struct {
+	FirstName string `xml:"firstName"`
+	LastName  string `xml:"lastName"`
+}
+
+type sdnCountry struct {
+	Country string `xml:"country"`
+}
+
+func loadXML(path string) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sanctions file %s: %w", path, err)
+	}
+
+	var doc sdnList
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing sanctions file %s: %w", path, err)
+	}
+
+	hash := sha256.New()
+	hash.Write(data)
+	list := &List{Version: version(hash), PublishedAt: strings.TrimSpace(doc.PublishDate)}
+
+	for _, e := range doc.Entries {
+		name := sdnName{FirstName: e.FirstName, LastName: e.LastName}.String()
+		if strings.TrimSpace(e.UID) == "" || name == "" {
+			continue
+		}
+
+		entry := Entry{
+			UID:      strings.TrimSpace(e.UID),
+			Name:     name,
+			Type:     strings.TrimSpace(e.Type),
+			Programs: e.Programs,
+		}
+		for _, aka := range e.Akas {
+			if alias := aka.String(); alias != "" {
+				entry.Aliases = appendUnique(entry.Aliases, alias)
+			}
+		}
+		for _, group := range [][]sdnCountry{e.Addresses, e.Nationalities, e.Citizenships} {
+			for _, c := range group {
+				if country := strings.TrimSpace(c.Country); country != "" {
+					entry.Countries = appendUnique(entry.Countries, country)
+				}
+			}
+		}
+		list.Entries = append(list.Entries, entry)
+	}
+
+	return list, nil
+}
+
+// String formats the name the way the CSV files do: "LAST, First".
+func (n sdnName) String() string {
+	first, last := strings.TrimSpace(n.FirstName), strings.TrimSpace(n.LastName)
+	if first == "" {
+		return last
+	}
+	if last == "" {
+		return first
+	}
+	return last + ", " + first
+}
+
+// index precomputes the normalized form of every name and alias.
+func (l *List) index() {
+	l.names = l.names[:0]
+	for i, entry := range l.Entries {
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			if normalized := normalizeName(name); normalized != "" {
+				l.names = append(l.names, indexedName{entry: i, name: name, normalized: normalized})
+			}
+		}
+	}
+}
+
+func csvValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == csvNull {
+		return ""
+	}
+	return value
+}
+
+// splitPrograms parses the CSV program column, e.g. "SDGT] [IRGC".
+func splitPrograms(value string) []string {
+	var programs []string
+	for _, p := range strings.Split(value, "] [") {
+		if p = strings.Trim(p, "[] "); p != "" {
+			programs = append(programs, p)
+		}
+	}
+	return programs
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func version(hash interface{ Sum([]byte) []byte }) string {
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))[:12]
+}
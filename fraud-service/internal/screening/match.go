@@ -0,0 +1,136 @@
+package screening
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// candidate is the best-scoring list name for a screened customer.
+type candidate struct {
+	entry          *Entry
+	name           string
+	score          float64
+	countryMatched bool
+}
+
+// bestMatch returns the list name most similar to the customer name, or nil
+// when the list is empty or the name has no letters. Country is only used
+// to break ties between equally similar names.
+func (l *List) bestMatch(name, country string) *candidate {
+	normalized := normalizeName(name)
+	if normalized == "" {
+		return nil
+	}
+	country = normalizeName(country)
+
+	var best *candidate
+	for _, indexed := range l.names {
+		score := similarity(normalized, indexed.normalized)
+		if best != nil && score < best.score {
+			continue
+		}
+
+		entry := &l.Entries[indexed.entry]
+		countryMatched := hasCountry(entry, country)
+		if best != nil && score == best.score && (best.countryMatched || !countryMatched) {
+			continue
+		}
+
+		best = &candidate{entry: entry, name: indexed.name, score: score, countryMatched: countryMatched}
+	}
+
+	return best
+}
+
+// hasCountry reports whether the normalized country is listed on the entry.
+func hasCountry(entry *Entry, country string) bool {
+	if country == "" {
+		return false
+	}
+	for _, c := range entry.Countries {
+		if normalizeName(c) == country {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeName makes names comparable regardless of accents, case,
+// punctuation and word order: "Pérez, José" and "JOSE PEREZ" both become "JOSE PEREZ".
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// drop combining accents
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	tokens := strings.Fields(b.String())
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// similarity is the Jaro-Winkler similarity of two strings, from 0 to 1.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	window = max(window, 0)
+
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if matchedB[j] || ra[i] != rb[j] {
+				continue
+			}
+			matchedA[i], matchedB[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
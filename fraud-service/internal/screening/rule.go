@@ -0,0 +1,50 @@
+package screening
+
+import (
+	"fmt"
+
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
+)
+
+// RuleName identifies sanctions screening results in the audit log.
+const RuleName = "sanctions_screening"
+
+// Rule screens the customer carried on the payment event.
+// The matched entry is recorded in the rule result.
+type Rule struct {
+	Screener *Screener
+	Weight   float64
+}
+
+// NewRule creates a screening rule backed by the given screener.
+func NewRule(s *Screener) *Rule {
+	return &Rule{Screener: s, Weight: 1}
+}
+
+// Name returns the rule identifier recorded in the audit log.
+func (r *Rule) Name() string {
+	return RuleName
+}
+
+// Evaluate screens the customer name and country of the event.
+func (r *Rule) Evaluate(input rules.Input) models.RuleResult {
+	result := models.RuleResult{Rule: r.Name()}
+
+	action, match := r.Screener.Screen(input.Event.CustomerName, input.Event.CustomerCountry)
+	if match == nil {
+		return result
+	}
+
+	result.Triggered = true
+	result.Score = r.Weight
+	result.Action = action
+	result.Match = match
+	if action == models.PaymentStatusDeclined {
+		result.Reason = fmt.Sprintf("Sanctions list match: %s (%s)", match.EntryName, match.EntryUID)
+	} else {
+		result.Reason = fmt.Sprintf("Possible sanctions list match: %s (%s)", match.EntryName, match.EntryUID)
+	}
+
+	return result
+}
@@ -0,0 +1,136 @@
+package screening
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeffleon2/draftea-fraud-service/internal/metrics"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Screener matches customers against the current sanctions list.
+//
+// The list is swapped atomically on reload: in-flight screenings keep using
+// the snapshot they started with, and a list that fails to load never
+// replaces the one in use.
+type Screener struct {
+	path         string
+	declineScore float64
+	reviewScore  float64
+
+	list     atomic.Pointer[List]
+	reloadMu sync.Mutex
+}
+
+// NewScreener loads the list at path. Names scoring at least declineScore are
+// declined and those scoring at least reviewScore are sent to manual review.
+func NewScreener(path string, declineScore, reviewScore float64) (*Screener, error) {
+	if reviewScore <= 0 || reviewScore > declineScore || declineScore > 1 {
+		return nil, errors.New("sanctions scores must satisfy 0 < review <= decline <= 1")
+	}
+
+	list, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Screener{path: path, declineScore: declineScore, reviewScore: reviewScore}
+	s.store(list)
+	return s, nil
+}
+
+// List returns the snapshot currently in use.
+func (s *Screener) List() *List {
+	return s.list.Load()
+}
+
+// ListInfo describes the snapshot currently in use.
+func (s *Screener) ListInfo() models.SanctionsListInfo {
+	return s.List().Info()
+}
+
+// Reload reads the list file again and swaps it in when its version changed.
+// On error the current list is kept.
+func (s *Screener) Reload() (models.SanctionsListInfo, bool, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	list, err := LoadFile(s.path)
+	if err != nil {
+		metrics.SanctionsReloadsTotal.WithLabelValues("error").Inc()
+		return s.ListInfo(), false, err
+	}
+
+	if list.Version == s.List().Version {
+		metrics.SanctionsReloadsTotal.WithLabelValues("unchanged").Inc()
+		return s.ListInfo(), false, nil
+	}
+
+	s.store(list)
+	metrics.SanctionsReloadsTotal.WithLabelValues("loaded").Inc()
+	return list.Info(), true, nil
+}
+
+// Watch reloads the list every interval until ctx is done.
+func (s *Screener) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, changed, err := s.Reload()
+			if err != nil {
+				logrus.WithError(err).Errorf("Error reloading sanctions list, keeping %s", info.Version)
+				continue
+			}
+			if changed {
+				logrus.Infof("Loaded sanctions list %s (%d entries)", info.Version, info.Entries)
+			}
+		}
+	}
+}
+
+// Screen matches a customer name and country against the list.
+// It returns the action to take (DECLINED or REVIEW) and the matched entry,
+// or an empty action when the customer is not a likely match.
+//
+// A name scoring at or above the decline score is declined unless the entry
+// lists countries and the customer's country is known and not among them;
+// those hits, and names in the review band, are sent to manual review.
+func (s *Screener) Screen(name, country string) (string, *models.SanctionsMatch) {
+	list := s.List()
+
+	best := list.bestMatch(name, country)
+	if best == nil || best.score < s.reviewScore {
+		return "", nil
+	}
+
+	match := &models.SanctionsMatch{
+		ListVersion:    list.Version,
+		EntryUID:       best.entry.UID,
+		EntryName:      best.entry.Name,
+		MatchedName:    best.name,
+		Programs:       best.entry.Programs,
+		Countries:      best.entry.Countries,
+		Score:          best.score,
+		CountryMatched: best.countryMatched,
+	}
+
+	countryConflict := country != "" && len(best.entry.Countries) > 0 && !best.countryMatched
+	if best.score >= s.declineScore && !countryConflict {
+		return models.PaymentStatusDeclined, match
+	}
+	return models.PaymentStatusReview, match
+}
+
+func (s *Screener) store(list *List) {
+	s.list.Store(list)
+	metrics.SanctionsListEntries.Set(float64(len(list.Entries)))
+}
@@ -0,0 +1,135 @@
+package screening_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
+	"github.com/jeffleon2/draftea-fraud-service/internal/screening"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile_CSV(t *testing.T) {
+	list, err := screening.LoadFile("testdata/csv/sdn.csv")
+
+	require.NoError(t, err)
+	assert.Len(t, list.Entries, 3)
+	assert.Contains(t, list.Version, "sha256:")
+
+	zubaydah := list.Entries[1]
+	assert.Equal(t, "2674", zubaydah.UID)
+	assert.Equal(t, "ABU ZUBAYDAH, Muhammad Husayn", zubaydah.Name)
+	assert.Equal(t, []string{"SDGT", "IRGC"}, zubaydah.Programs)
+	assert.Equal(t, []string{"ZUBAYDAH, Abu"}, zubaydah.Aliases)
+	assert.Equal(t, []string{"Mexico"}, list.Entries[2].Countries)
+}
+
+func TestLoadFile_XML(t *testing.T) {
+	list, err := screening.LoadFile("testdata/sdn.xml")
+
+	require.NoError(t, err)
+	assert.Equal(t, "01/15/2025", list.PublishedAt)
+	assert.Len(t, list.Entries, 2)
+	assert.Equal(t, "PEREZ GARCIA, Jose Luis", list.Entries[0].Name)
+	assert.Equal(t, []string{"EL FLACO"}, list.Entries[0].Aliases)
+	assert.Equal(t, []string{"Mexico"}, list.Entries[0].Countries)
+}
+
+func TestLoadFile_UnsupportedFormat(t *testing.T) {
+	_, err := screening.LoadFile("testdata/sdn.json")
+
+	assert.Error(t, err)
+}
+
+func TestScreen(t *testing.T) {
+	screener, err := screening.NewScreener("testdata/csv/sdn.csv", 0.95, 0.85)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		customer string
+		country  string
+		action   string
+		entryUID string
+	}{
+		{name: "exact name in other order and accents", customer: "José Luis Pérez García", country: "Mexico", action: models.PaymentStatusDeclined, entryUID: "9001"},
+		{name: "country unknown", customer: "Jose Luis Perez Garcia", action: models.PaymentStatusDeclined, entryUID: "9001"},
+		{name: "country conflict", customer: "Jose Luis Perez Garcia", country: "Spain", action: models.PaymentStatusReview, entryUID: "9001"},
+		{name: "alias", customer: "Abu Zubaydah", action: models.PaymentStatusDeclined, entryUID: "2674"},
+		{name: "typo", customer: "Jose Luis Peres Garcia", country: "Mexico", action: models.PaymentStatusDeclined, entryUID: "9001"},
+		{name: "similar name", customer: "Juan Perez Garcia", country: "Mexico", action: models.PaymentStatusReview, entryUID: "9001"},
+		{name: "no match", customer: "Jane Doe", country: "Mexico"},
+		{name: "no name", customer: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, match := screener.Screen(tt.customer, tt.country)
+
+			assert.Equal(t, tt.action, action)
+			if tt.entryUID == "" {
+				assert.Nil(t, match)
+				return
+			}
+			require.NotNil(t, match)
+			assert.Equal(t, tt.entryUID, match.EntryUID)
+			assert.Equal(t, screener.List().Version, match.ListVersion)
+		})
+	}
+}
+
+func TestReload_SwapsOnlyValidChangedLists(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sdn.csv")
+	require.NoError(t, os.WriteFile(path, []byte(`1,"FIRST ENTITY",-0- ,"CUBA"`), 0o600))
+
+	screener, err := screening.NewScreener(path, 0.95, 0.85)
+	require.NoError(t, err)
+	original := screener.ListInfo()
+
+	info, changed, err := screener.Reload()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, original.Version, info.Version)
+
+	require.NoError(t, os.WriteFile(path, []byte(""), 0o600))
+	info, changed, err = screener.Reload()
+	assert.Error(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, original.Version, info.Version)
+
+	require.NoError(t, os.WriteFile(path, []byte("1,\"FIRST ENTITY\",-0- ,\"CUBA\"\n2,\"SECOND ENTITY\",-0- ,\"CUBA\""), 0o600))
+	info, changed, err = screener.Reload()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NotEqual(t, original.Version, info.Version)
+	assert.Equal(t, 2, info.Entries)
+
+	action, _ := screener.Screen("Second Entity", "")
+	assert.Equal(t, models.PaymentStatusDeclined, action)
+}
+
+func TestRule_RecordsMatchedEntry(t *testing.T) {
+	screener, err := screening.NewScreener("testdata/sdn.xml", 0.95, 0.85)
+	require.NoError(t, err)
+	ruleSet := rules.Default("v1", 10000)
+	ruleSet.Rules = append([]rules.Rule{screening.NewRule(screener)}, ruleSet.Rules...)
+
+	evaluation := ruleSet.Evaluate(rules.Input{Event: models.PaymentCreatedEvent{
+		ID:              "payment-1",
+		Amount:          100,
+		CustomerName:    "El Flaco",
+		CustomerCountry: "Mexico",
+	}})
+
+	assert.Equal(t, models.PaymentStatusDeclined, evaluation.Status)
+	result := evaluation.Results[0]
+	assert.Equal(t, screening.RuleName, result.Rule)
+	require.NotNil(t, result.Match)
+	assert.Equal(t, "9001", result.Match.EntryUID)
+	assert.Equal(t, "EL FLACO", result.Match.MatchedName)
+	assert.True(t, result.Match.CountryMatched)
+}
@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"sort"
 	"time"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-fraud-service/internal/logging"
+	"github.com/jeffleon2/draftea-fraud-service/internal/metrics"
 	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,48 +19,252 @@ type Publisher interface {
 	Publish(ctx context.Context, topic string, message interface{}) error
 }
 
+// DecisionRepo defines the interface for the fraud decision audit log.
+type DecisionRepo interface {
+	Create(ctx context.Context, decision *models.FraudDecision) error
+	GetByPaymentID(ctx context.Context, paymentID string) (*[]models.FraudDecision, error)
+	GetByCustomerID(ctx context.Context, customerID string, limit, offset int) (*[]models.FraudDecision, error)
+	GetBetween(ctx context.Context, from, to time.Time) (*[]models.FraudDecision, error)
+	GetCustomerProfile(ctx context.Context, customerID string, now time.Time) (*models.CustomerProfile, error)
+}
+
 // FraudService implements fraud detection logic for payment transactions.
-// It evaluates payments based on configurable rules and publishes the results
-// to Kafka for downstream processing.
+// It evaluates payments based on configurable rules, records every decision
+// in the audit log and publishes the results to Kafka for downstream processing.
 type FraudService struct {
 	Publisher Publisher
+	Rules     *rules.RuleSet
+	Decisions DecisionRepo
+	// AnalysisDelay simulates the fraud analysis processing time.
+	AnalysisDelay time.Duration
 }
 
-// NewFraudService creates a new FraudService with the provided publisher.
+// NewFraudService creates a new FraudService with the provided publisher, rule set and audit repository.
 // The publisher is used to send fraud check results to the payments.checked topic.
-func NewFraudService(p Publisher) *FraudService {
+func NewFraudService(p Publisher, r *rules.RuleSet, d DecisionRepo) *FraudService {
 	return &FraudService{
-		Publisher: p,
+		Publisher:     p,
+		Rules:         r,
+		Decisions:     d,
+		AnalysisDelay: 30 * time.Second,
 	}
 }
 
 // EvaluatePayment analyzes a payment for potential fraud.
-// Current implementation flags transactions over $10,000 as suspicious.
-// Results are published to the payments.checked topic with either APPROVED or DECLINED status.
+// The payment is run through the configured rule set, the resulting decision
+// is persisted to the audit log, and the outcome is published to the
+// payments.checked topic with either APPROVED or DECLINED status.
 //
-// The method includes a 30-second delay to simulate fraud analysis processing time.
+// The method waits AnalysisDelay (30 seconds by default) to simulate fraud analysis processing time.
 // In production, this would be replaced with actual fraud detection algorithms.
 func (s *FraudService) EvaluatePayment(ctx context.Context, event models.PaymentCreatedEvent) error {
-	log.Println("Evaluating fraud for payment:", event.ID)
-	var reason string
-	var status = models.PaymentStatusApproved
+	if event.TraceID == "" {
+		// Producers older than the trace header leave it to the message.
+		event.TraceID = contracts.TraceID(ctx)
+	}
+	logger := logging.FromContext(ctx).WithFields(logrus.Fields{
+		logging.FieldTraceID:   event.TraceID,
+		logging.FieldPaymentID: event.ID,
+	})
+	logger.Info("Evaluating fraud")
+	input, evaluation, err := s.evaluate(ctx, event)
+	if err != nil {
+		return err
+	}
 
-	if event.Amount > 10000 {
-		reason = "High-value transaction suspicious"
-		status = models.PaymentStatusDeclined
-		logrus.Errorf("High value payment detected - potential fraud %s", event.ID)
+	if evaluation.Status != models.PaymentStatusApproved {
+		logger.Warnf("Payment flagged by fraud rules: %s", evaluation.Reason)
 	}
+	s.recordShadowDecisions(event, evaluation)
 
+	checkedAt := time.Now()
 	approved := models.FraudCheckEvent{
 		ID:        event.ID,
 		TraceID:   event.TraceID,
-		CheckedAt: time.Now(),
-		Reason:    reason,
-		Status:    status,
+		CheckedAt: checkedAt,
+		Reason:    evaluation.Reason,
+		Status:    evaluation.Status,
 	}
 
-	time.Sleep(30 * time.Second)
+	time.Sleep(s.AnalysisDelay)
+
+	decision := &models.FraudDecision{
+		PaymentID:      event.ID,
+		CustomerID:     event.CustomerID,
+		TraceID:        event.TraceID,
+		Status:         evaluation.Status,
+		Reason:         evaluation.Reason,
+		ShadowStatus:   evaluation.ShadowStatus,
+		Score:          evaluation.Score,
+		RuleSetVersion: evaluation.Version,
+		Rules:          evaluation.Results,
+		Event:          event,
+		Customer:       input.Customer,
+		EvaluatedAt:    checkedAt,
+	}
+	if err := s.Decisions.Create(ctx, decision); err != nil {
+		return fmt.Errorf("error recording fraud decision: %w", err)
+	}
 
-	log.Println("✅ Fraud evaluation completed for:", event.ID)
+	logger.Infof("Fraud evaluation completed: %s", evaluation.Status)
 	return s.Publisher.Publish(ctx, models.TopicPaymentChecked, approved)
 }
+
+// PreCheck runs the payment through the same rule set as EvaluatePayment and
+// returns the decision synchronously. Pre-checks are non-binding: nothing is
+// published or written to the audit log, and shadow rules are not reported.
+// The outcome is only counted in the fraud_prechecks_total metric.
+func (s *FraudService) PreCheck(ctx context.Context, event models.PaymentCreatedEvent) (*models.PreCheckResult, error) {
+	_, evaluation, err := s.evaluate(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.PreChecksTotal.WithLabelValues(evaluation.Status).Inc()
+
+	return &models.PreCheckResult{
+		PaymentID:      event.ID,
+		CustomerID:     event.CustomerID,
+		Status:         evaluation.Status,
+		Reason:         evaluation.Reason,
+		Score:          evaluation.Score,
+		RuleSetVersion: evaluation.Version,
+		Rules:          evaluation.LiveResults(),
+		CheckedAt:      time.Now(),
+	}, nil
+}
+
+// GetDecisionsByPayment returns the audit trail of fraud decisions for a payment.
+func (s *FraudService) GetDecisionsByPayment(ctx context.Context, paymentID string) (*[]models.FraudDecision, error) {
+	return s.Decisions.GetByPaymentID(ctx, paymentID)
+}
+
+// GetDecisionsByCustomer returns a page of fraud decisions recorded for a customer.
+func (s *FraudService) GetDecisionsByCustomer(ctx context.Context, customerID string, limit, offset int) (*[]models.FraudDecision, error) {
+	return s.Decisions.GetByCustomerID(ctx, customerID, limit, offset)
+}
+
+// ShadowReport compares shadow rule decisions with the live decisions
+// recorded in the audit log between from and to.
+func (s *FraudService) ShadowReport(ctx context.Context, from, to time.Time) (*models.ShadowReport, error) {
+	decisions, err := s.Decisions.GetBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ShadowReport{
+		From:        from,
+		To:          to,
+		Evaluations: len(*decisions),
+		Rules:       []models.ShadowRuleReport{},
+	}
+	byRule := make(map[string]*models.ShadowRuleReport)
+
+	for _, decision := range *decisions {
+		if decision.ShadowStatus != "" && decision.ShadowStatus != decision.Status {
+			report.Changed++
+		}
+
+		for _, result := range decision.Rules {
+			if !result.Shadow {
+				continue
+			}
+
+			ruleReport, ok := byRule[result.Rule]
+			if !ok {
+				ruleReport = &models.ShadowRuleReport{Rule: result.Rule}
+				byRule[result.Rule] = ruleReport
+			}
+
+			ruleReport.Evaluated++
+			if result.Triggered {
+				ruleReport.Triggered++
+			}
+			if shadowDecision(result) == decision.Status {
+				ruleReport.Agreements++
+			} else {
+				ruleReport.Disagreements++
+			}
+			if result.Triggered && decision.Status == models.PaymentStatusApproved {
+				ruleReport.NewlyFlagged++
+			}
+		}
+	}
+
+	for _, ruleReport := range byRule {
+		report.Rules = append(report.Rules, *ruleReport)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		return report.Rules[i].Rule < report.Rules[j].Rule
+	})
+
+	return report, nil
+}
+
+// DryRunExpression compiles a CEL expression and evaluates it against sample
+// inputs without publishing or recording anything. Compilation failures are
+// returned as *rules.CompileError.
+func (s *FraudService) DryRunExpression(expression string, samples []rules.Input) ([]models.DryRunResult, error) {
+	compiled, err := rules.CompileExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.DryRunResult, 0, len(samples))
+	for _, sample := range samples {
+		result := models.DryRunResult{PaymentID: sample.Event.ID}
+		matched, err := compiled.Matches(sample)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Matched = matched
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// evaluate enriches the event and runs it through the live and shadow rules.
+func (s *FraudService) evaluate(ctx context.Context, event models.PaymentCreatedEvent) (rules.Input, rules.Evaluation, error) {
+	input, err := s.enrich(ctx, event)
+	if err != nil {
+		return rules.Input{}, rules.Evaluation{}, err
+	}
+
+	return input, s.Rules.Evaluate(input), nil
+}
+
+// enrich builds the rule input for an event, adding the customer profile
+// derived from the audit log.
+func (s *FraudService) enrich(ctx context.Context, event models.PaymentCreatedEvent) (rules.Input, error) {
+	profile, err := s.Decisions.GetCustomerProfile(ctx, event.CustomerID, time.Now())
+	if err != nil {
+		return rules.Input{}, fmt.Errorf("error loading customer profile: %w", err)
+	}
+
+	return rules.Input{Event: event, Customer: *profile}, nil
+}
+
+// recordShadowDecisions logs and exports the outcome of every shadow rule.
+// Shadow outcomes never change the published FraudCheckEvent.
+func (s *FraudService) recordShadowDecisions(event models.PaymentCreatedEvent, evaluation rules.Evaluation) {
+	for _, result := range evaluation.ShadowResults() {
+		decision := shadowDecision(result)
+		metrics.ShadowDecisionsTotal.WithLabelValues(result.Rule, decision, evaluation.Status).Inc()
+		if result.Triggered {
+			logrus.WithFields(logrus.Fields{
+				logging.FieldTraceID:   event.TraceID,
+				logging.FieldPaymentID: event.ID,
+			}).Infof("Shadow rule %s would mark payment as %s (live decision %s): %s",
+				result.Rule, decision, evaluation.Status, result.Reason)
+		}
+	}
+}
+
+// shadowDecision returns the decision a single rule would have produced on its own.
+func shadowDecision(result models.RuleResult) string {
+	if result.Triggered {
+		return result.Action
+	}
+	return models.PaymentStatusApproved
+}
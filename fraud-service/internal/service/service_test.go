@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/rules"
 	"github.com/jeffleon2/draftea-fraud-service/internal/service"
 	"github.com/jeffleon2/draftea-fraud-service/internal/service/mocks"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +17,8 @@ import (
 
 func TestEvaluatePayment_LowValuePayment_Approved(t *testing.T) {
 	mockPublisher := mocks.NewMockPublisher(t)
-	fraudService := service.NewFraudService(mockPublisher)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
 
 	ctx := context.Background()
 	event := models.PaymentCreatedEvent{
@@ -28,9 +31,20 @@ func TestEvaluatePayment_LowValuePayment_Approved(t *testing.T) {
 		TraceID:    "trace-789",
 	}
 
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	mockDecisions.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.FraudDecision")).
+		Return(nil).
+		Once()
+
 	mockPublisher.EXPECT().
 		Publish(ctx, models.TopicPaymentChecked, mock.MatchedBy(func(evt models.FraudCheckEvent) bool {
-			return evt.ID == event.ID &&
+			return contracts.ValidateEvent(evt) == nil &&
+				evt.ID == event.ID &&
 				evt.TraceID == event.TraceID &&
 				evt.Status == models.PaymentStatusApproved &&
 				evt.Reason == ""
@@ -46,7 +60,8 @@ func TestEvaluatePayment_LowValuePayment_Approved(t *testing.T) {
 
 func TestEvaluatePayment_HighValuePayment_Declined(t *testing.T) {
 	mockPublisher := mocks.NewMockPublisher(t)
-	fraudService := service.NewFraudService(mockPublisher)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
 
 	ctx := context.Background()
 	event := models.PaymentCreatedEvent{
@@ -59,9 +74,29 @@ func TestEvaluatePayment_HighValuePayment_Declined(t *testing.T) {
 		TraceID:    "trace-suspicious",
 	}
 
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	mockDecisions.EXPECT().
+		Create(ctx, mock.MatchedBy(func(d *models.FraudDecision) bool {
+			return d.PaymentID == event.ID &&
+				d.CustomerID == event.CustomerID &&
+				d.Status == models.PaymentStatusDeclined &&
+				d.RuleSetVersion == "v1" &&
+				d.Score == 1 &&
+				len(d.Rules) == 1 &&
+				d.Rules[0].Triggered &&
+				d.Event == event
+		})).
+		Return(nil).
+		Once()
+
 	mockPublisher.EXPECT().
 		Publish(ctx, models.TopicPaymentChecked, mock.MatchedBy(func(evt models.FraudCheckEvent) bool {
-			return evt.ID == event.ID &&
+			return contracts.ValidateEvent(evt) == nil &&
+				evt.ID == event.ID &&
 				evt.TraceID == event.TraceID &&
 				evt.Status == models.PaymentStatusDeclined &&
 				evt.Reason == "High-value transaction suspicious"
@@ -77,7 +112,8 @@ func TestEvaluatePayment_HighValuePayment_Declined(t *testing.T) {
 
 func TestEvaluatePayment_ExactThreshold_Approved(t *testing.T) {
 	mockPublisher := mocks.NewMockPublisher(t)
-	fraudService := service.NewFraudService(mockPublisher)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
 
 	ctx := context.Background()
 	event := models.PaymentCreatedEvent{
@@ -90,9 +126,20 @@ func TestEvaluatePayment_ExactThreshold_Approved(t *testing.T) {
 		TraceID:    "trace-edge",
 	}
 
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	mockDecisions.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.FraudDecision")).
+		Return(nil).
+		Once()
+
 	mockPublisher.EXPECT().
 		Publish(ctx, models.TopicPaymentChecked, mock.MatchedBy(func(evt models.FraudCheckEvent) bool {
-			return evt.ID == event.ID &&
+			return contracts.ValidateEvent(evt) == nil &&
+				evt.ID == event.ID &&
 				evt.Status == models.PaymentStatusApproved
 		})).
 		Return(nil).
@@ -106,7 +153,8 @@ func TestEvaluatePayment_ExactThreshold_Approved(t *testing.T) {
 
 func TestEvaluatePayment_PublisherError(t *testing.T) {
 	mockPublisher := mocks.NewMockPublisher(t)
-	fraudService := service.NewFraudService(mockPublisher)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
 
 	ctx := context.Background()
 	event := models.PaymentCreatedEvent{
@@ -121,6 +169,16 @@ func TestEvaluatePayment_PublisherError(t *testing.T) {
 
 	expectedError := errors.New("kafka publish failed")
 
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	mockDecisions.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.FraudDecision")).
+		Return(nil).
+		Once()
+
 	mockPublisher.EXPECT().
 		Publish(ctx, models.TopicPaymentChecked, mock.Anything).
 		Return(expectedError).
@@ -135,7 +193,8 @@ func TestEvaluatePayment_PublisherError(t *testing.T) {
 
 func TestEvaluatePayment_ZeroAmount_Approved(t *testing.T) {
 	mockPublisher := mocks.NewMockPublisher(t)
-	fraudService := service.NewFraudService(mockPublisher)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
 
 	ctx := context.Background()
 	event := models.PaymentCreatedEvent{
@@ -148,9 +207,20 @@ func TestEvaluatePayment_ZeroAmount_Approved(t *testing.T) {
 		TraceID:    "trace-zero",
 	}
 
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	mockDecisions.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.FraudDecision")).
+		Return(nil).
+		Once()
+
 	mockPublisher.EXPECT().
 		Publish(ctx, models.TopicPaymentChecked, mock.MatchedBy(func(evt models.FraudCheckEvent) bool {
-			return evt.Status == models.PaymentStatusApproved
+			return contracts.ValidateEvent(evt) == nil &&
+				evt.Status == models.PaymentStatusApproved
 		})).
 		Return(nil).
 		Once()
@@ -163,7 +233,8 @@ func TestEvaluatePayment_ZeroAmount_Approved(t *testing.T) {
 
 func TestEvaluatePayment_NegativeAmount_Approved(t *testing.T) {
 	mockPublisher := mocks.NewMockPublisher(t)
-	fraudService := service.NewFraudService(mockPublisher)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
 
 	ctx := context.Background()
 	event := models.PaymentCreatedEvent{
@@ -176,9 +247,20 @@ func TestEvaluatePayment_NegativeAmount_Approved(t *testing.T) {
 		TraceID:    "trace-refund",
 	}
 
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	mockDecisions.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.FraudDecision")).
+		Return(nil).
+		Once()
+
 	mockPublisher.EXPECT().
 		Publish(ctx, models.TopicPaymentChecked, mock.MatchedBy(func(evt models.FraudCheckEvent) bool {
-			return evt.Status == models.PaymentStatusApproved
+			return contracts.ValidateEvent(evt) == nil &&
+				evt.Status == models.PaymentStatusApproved
 		})).
 		Return(nil).
 		Once()
@@ -191,7 +273,8 @@ func TestEvaluatePayment_NegativeAmount_Approved(t *testing.T) {
 
 func TestEvaluatePayment_CheckedAtTimestamp(t *testing.T) {
 	mockPublisher := mocks.NewMockPublisher(t)
-	fraudService := service.NewFraudService(mockPublisher)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
 
 	ctx := context.Background()
 	event := models.PaymentCreatedEvent{
@@ -206,9 +289,20 @@ func TestEvaluatePayment_CheckedAtTimestamp(t *testing.T) {
 
 	beforeTime := time.Now()
 
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	mockDecisions.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.FraudDecision")).
+		Return(nil).
+		Once()
+
 	mockPublisher.EXPECT().
 		Publish(ctx, models.TopicPaymentChecked, mock.MatchedBy(func(evt models.FraudCheckEvent) bool {
-			return !evt.CheckedAt.IsZero() &&
+			return contracts.ValidateEvent(evt) == nil &&
+				!evt.CheckedAt.IsZero() &&
 				evt.CheckedAt.After(beforeTime) &&
 				evt.CheckedAt.Before(time.Now().Add(35*time.Second))
 		})).
@@ -221,11 +315,193 @@ func TestEvaluatePayment_CheckedAtTimestamp(t *testing.T) {
 	mockPublisher.AssertExpectations(t)
 }
 
+func TestEvaluatePayment_DecisionRepoError(t *testing.T) {
+	mockPublisher := mocks.NewMockPublisher(t)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
+
+	ctx := context.Background()
+	event := models.PaymentCreatedEvent{
+		ID:         "payment-audit-error",
+		Amount:     5000.0,
+		Currency:   "USD",
+		Status:     "PENDING",
+		Method:     "credit_card",
+		CustomerID: "customer-audit",
+		TraceID:    "trace-audit",
+	}
+
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	mockDecisions.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.FraudDecision")).
+		Return(errors.New("db unavailable")).
+		Once()
+
+	err := fraudService.EvaluatePayment(ctx, event)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "db unavailable")
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEvaluatePayment_CustomerProfileError(t *testing.T) {
+	mockPublisher := mocks.NewMockPublisher(t)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
+
+	ctx := context.Background()
+	event := models.PaymentCreatedEvent{ID: "payment-123", Amount: 100, CustomerID: "customer-profile"}
+
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(nil, errors.New("db down")).
+		Once()
+
+	err := fraudService.EvaluatePayment(ctx, event)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error loading customer profile")
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	mockDecisions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPreCheck_UsesLiveRulesWithoutPublishing(t *testing.T) {
+	mockPublisher := mocks.NewMockPublisher(t)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	ruleSet := rules.Default("v1", 10000)
+	ruleSet.Shadow = []rules.Rule{&rules.HighValueRule{RuleName: "high_value_5k", Threshold: 5000, Weight: 1}}
+	fraudService := service.NewFraudService(mockPublisher, ruleSet, mockDecisions)
+
+	ctx := context.Background()
+	event := models.PaymentCreatedEvent{Amount: 15000, Currency: "USD", Method: "credit_card", CustomerID: "customer-precheck"}
+
+	mockDecisions.EXPECT().
+		GetCustomerProfile(ctx, event.CustomerID, mock.AnythingOfType("time.Time")).
+		Return(&models.CustomerProfile{}, nil).
+		Once()
+
+	result, err := fraudService.PreCheck(ctx, event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentStatusDeclined, result.Status)
+	assert.Equal(t, "High-value transaction suspicious", result.Reason)
+	assert.Equal(t, "v1", result.RuleSetVersion)
+	assert.Len(t, result.Rules, 1)
+	assert.Equal(t, "high_value_amount", result.Rules[0].Rule)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	mockDecisions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestGetDecisionsByCustomer(t *testing.T) {
+	mockPublisher := mocks.NewMockPublisher(t)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
+
+	ctx := context.Background()
+	decisions := &[]models.FraudDecision{{PaymentID: "payment-1", CustomerID: "customer-1"}}
+
+	mockDecisions.EXPECT().
+		GetByCustomerID(ctx, "customer-1", 10, 20).
+		Return(decisions, nil).
+		Once()
+
+	result, err := fraudService.GetDecisionsByCustomer(ctx, "customer-1", 10, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, decisions, result)
+}
+
+func TestShadowReport(t *testing.T) {
+	mockPublisher := mocks.NewMockPublisher(t)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	fraudService := service.NewFraudService(mockPublisher, rules.Default("v1", 10000), mockDecisions)
+
+	ctx := context.Background()
+	to := time.Now()
+	from := to.Add(-time.Hour)
+
+	decisions := &[]models.FraudDecision{
+		{
+			Status:       models.PaymentStatusApproved,
+			ShadowStatus: models.PaymentStatusDeclined,
+			Rules: []models.RuleResult{
+				{Rule: "high_value_amount"},
+				{Rule: "high_value_5k", Shadow: true, Triggered: true, Action: models.PaymentStatusDeclined},
+			},
+		},
+		{
+			Status:       models.PaymentStatusDeclined,
+			ShadowStatus: models.PaymentStatusDeclined,
+			Rules: []models.RuleResult{
+				{Rule: "high_value_amount", Triggered: true, Action: models.PaymentStatusDeclined},
+				{Rule: "high_value_5k", Shadow: true, Triggered: true, Action: models.PaymentStatusDeclined},
+			},
+		},
+		{
+			Status:       models.PaymentStatusApproved,
+			ShadowStatus: models.PaymentStatusApproved,
+			Rules: []models.RuleResult{
+				{Rule: "high_value_amount"},
+				{Rule: "high_value_5k", Shadow: true},
+			},
+		},
+	}
+
+	mockDecisions.EXPECT().
+		GetBetween(ctx, from, to).
+		Return(decisions, nil).
+		Once()
+
+	report, err := fraudService.ShadowReport(ctx, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Evaluations)
+	assert.Equal(t, 1, report.Changed)
+	assert.Equal(t, []models.ShadowRuleReport{
+		{Rule: "high_value_5k", Evaluated: 3, Triggered: 2, Agreements: 2, Disagreements: 1, NewlyFlagged: 1},
+	}, report.Rules)
+}
+
+func TestDryRunExpression(t *testing.T) {
+	fraudService := service.NewFraudService(mocks.NewMockPublisher(t), rules.Default("v1", 10000), mocks.NewMockDecisionRepo(t))
+
+	results, err := fraudService.DryRunExpression(
+		`amount > 5000 && method == "PAYPAL" && customer.age_days < 7`,
+		[]rules.Input{
+			{Event: models.PaymentCreatedEvent{ID: "new-customer", Amount: 6000, Method: "PAYPAL"}, Customer: models.CustomerProfile{AgeDays: 2}},
+			{Event: models.PaymentCreatedEvent{ID: "old-customer", Amount: 6000, Method: "PAYPAL"}, Customer: models.CustomerProfile{AgeDays: 90}},
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "new-customer", results[0].PaymentID)
+	assert.True(t, results[0].Matched)
+	assert.False(t, results[1].Matched)
+}
+
+func TestDryRunExpression_CompileError(t *testing.T) {
+	fraudService := service.NewFraudService(mocks.NewMockPublisher(t), rules.Default("v1", 10000), mocks.NewMockDecisionRepo(t))
+
+	_, err := fraudService.DryRunExpression(`amount + 1`, nil)
+
+	var compileErr *rules.CompileError
+	assert.ErrorAs(t, err, &compileErr)
+}
+
 func TestNewFraudService(t *testing.T) {
 	mockPublisher := mocks.NewMockPublisher(t)
+	mockDecisions := mocks.NewMockDecisionRepo(t)
+	ruleSet := rules.Default("v1", 10000)
 
-	fraudService := service.NewFraudService(mockPublisher)
+	fraudService := service.NewFraudService(mockPublisher, ruleSet, mockDecisions)
 
 	assert.NotNil(t, fraudService)
 	assert.Equal(t, mockPublisher, fraudService.Publisher)
+	assert.Equal(t, ruleSet, fraudService.Rules)
+	assert.Equal(t, mockDecisions, fraudService.Decisions)
 }
@@ -0,0 +1,129 @@
+package subscriber
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jeffleon2/draftea-fraud-service/config"
+	"github.com/segmentio/kafka-go"
+)
+
+// messageCommitter commits the offsets of consumed messages; *kafka.Reader
+// implements it.
+type messageCommitter interface {
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// offsetCommitter batches offset commits for a single reader.
+// Messages are handled concurrently, so each partition only commits up to its
+// oldest message still in flight: a crash re-delivers at most the messages
+// fetched after that one, never skips one.
+type offsetCommitter struct {
+	reader messageCommitter
+	config config.CommitConfig
+
+	mu         sync.Mutex
+	inFlight   map[int][]int64
+	handled    map[int]map[int64]kafka.Message
+	pending    []kafka.Message
+	lastCommit time.Time
+}
+
+func newOffsetCommitter(reader messageCommitter, cfg config.CommitConfig) *offsetCommitter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1
+	}
+
+	return &offsetCommitter{
+		reader:     reader,
+		config:     cfg,
+		inFlight:   map[int][]int64{},
+		handled:    map[int]map[int64]kafka.Message{},
+		lastCommit: time.Now(),
+	}
+}
+
+// Track registers a fetched message before it is handed to a worker.
+func (c *offsetCommitter) Track(msg kafka.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight[msg.Partition] = append(c.inFlight[msg.Partition], msg.Offset)
+}
+
+// Done marks msg as handled. Once every earlier message of its partition is
+// handled too, it joins the batch, which is committed when full or when the
+// commit interval has elapsed.
+func (c *offsetCommitter) Done(ctx context.Context, msg kafka.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.handled[msg.Partition] == nil {
+		c.handled[msg.Partition] = map[int64]kafka.Message{}
+	}
+	c.handled[msg.Partition][msg.Offset] = msg
+
+	offsets := c.inFlight[msg.Partition]
+	for len(offsets) > 0 {
+		next, ok := c.handled[msg.Partition][offsets[0]]
+		if !ok {
+			break
+		}
+		delete(c.handled[msg.Partition], offsets[0])
+		c.pending = append(c.pending, next)
+		offsets = offsets[1:]
+	}
+	c.inFlight[msg.Partition] = slices.Clip(offsets)
+
+	if len(c.pending) >= c.config.BatchSize || time.Since(c.lastCommit) >= c.config.Interval {
+		return c.flush(ctx)
+	}
+	return nil
+}
+
+// Flush commits every pending message. On error the batch is kept
+// so the next flush retries it.
+func (c *offsetCommitter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.flush(ctx)
+}
+
+// FlushEvery commits the pending batch every commit interval until ctx is done,
+// so offsets are committed even when the topic goes quiet.
+func (c *offsetCommitter) FlushEvery(ctx context.Context, onError func(error)) {
+	if c.config.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Flush(ctx); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (c *offsetCommitter) flush(ctx context.Context) error {
+	if len(c.pending) == 0 {
+		return nil
+	}
+
+	if err := c.reader.CommitMessages(ctx, c.pending...); err != nil {
+		return err
+	}
+
+	c.pending = c.pending[:0]
+	c.lastCommit = time.Now()
+	return nil
+}
@@ -0,0 +1,318 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-fraud-service/config"
+	"github.com/jeffleon2/draftea-fraud-service/internal/logging"
+	"github.com/jeffleon2/draftea-fraud-service/internal/metrics"
+	"github.com/jeffleon2/draftea-fraud-service/internal/models"
+	"github.com/jeffleon2/draftea-fraud-service/internal/publisher"
+	"github.com/jeffleon2/draftea-fraud-service/internal/tracing"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+// NATSConsumer consumes topics from JetStream behind the same Listen contract
+// as KafkaConsumer. Every topic has a durable pull consumer named after the
+// group, so the instances of a service share its messages and a restarted
+// instance resumes where the group left off.
+//
+// JetStream redelivers failed messages itself: a transient error naks the
+// message with the delay of its tier (see RetryConfig.Delays) instead of
+// moving it to a retry topic, and permanent errors or exhausted tiers go to
+// the DLQ before the message is acknowledged.
+type NATSConsumer struct {
+	Consumers    map[string]jetstream.Consumer
+	GroupID      string
+	DLQPublisher *publisher.NATSPublisher
+	RetryConfig  config.RetryConfig
+	AckWait      time.Duration
+	Workers      int
+	// Serializers decode messages by their content-type header.
+	Serializers contracts.Serializers
+
+	wg sync.WaitGroup
+}
+
+// NewNATSConsumer creates or updates the durable consumer of groupID on the
+// stream of every topic.
+func NewNATSConsumer(
+	ctx context.Context,
+	js jetstream.JetStream,
+	topics []string,
+	groupID string,
+	publisher *publisher.NATSPublisher,
+	retryConfig config.RetryConfig,
+	natsConfig config.NATS,
+	workers int,
+) (*NATSConsumer, error) {
+	consumers := make(map[string]jetstream.Consumer, len(topics))
+	for _, topic := range topics {
+		consumer, err := js.CreateOrUpdateConsumer(ctx, config.StreamName(topic), jetstream.ConsumerConfig{
+			Durable:       groupID,
+			FilterSubject: topic,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       natsConfig.AckWait,
+			MaxAckPending: natsConfig.MaxAckPending,
+			// Redeliveries are bounded by RetryConfig.Delays, not by JetStream.
+			MaxDeliver: -1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating consumer for topic %s: %w", topic, err)
+		}
+		consumers[topic] = consumer
+	}
+
+	return &NATSConsumer{
+		Consumers:    consumers,
+		GroupID:      groupID,
+		DLQPublisher: publisher,
+		RetryConfig:  retryConfig,
+		AckWait:      natsConfig.AckWait,
+		Workers:      workers,
+		Serializers:  contracts.DefaultSerializers,
+	}, nil
+}
+
+// Listen consumes every topic with at-least-once semantics: each message is
+// handled (or naked for a later retry, or sent to the DLQ) and only then
+// acknowledged. Each topic is handled by Workers goroutines with strict
+// ordering per message key. The handler's context carries the message's
+// trace ID.
+func (c *NATSConsumer) Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	for topic, consumer := range c.Consumers {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.consume(ctx, topic, consumer, handler)
+		}()
+	}
+}
+
+// Close waits for the listeners to stop. The context given to Listen must be
+// cancelled first. Messages fetched but not acknowledged are redelivered
+// after AckWait.
+func (c *NATSConsumer) Close() error {
+	c.wg.Wait()
+	return nil
+}
+
+// consume fetches the messages of consumer and hands them to Workers
+// goroutines, routing by key so messages with the same key are handled in
+// order.
+func (c *NATSConsumer) consume(ctx context.Context, topic string, consumer jetstream.Consumer, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	messages, err := consumer.Messages()
+	if err != nil {
+		logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to consume topic")
+		return
+	}
+	defer messages.Stop()
+
+	queues := make([]chan jetstream.Msg, max(c.Workers, 1))
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan jetstream.Msg, workerQueueSize)
+		workers.Add(1)
+		go func(queue <-chan jetstream.Msg) {
+			defer workers.Done()
+			c.work(ctx, queue, handler)
+		}(queues[i])
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		workers.Wait()
+	}()
+
+	for {
+		msg, err := messages.Next(jetstream.NextContext(ctx))
+		if ctx.Err() != nil || errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+			return
+		}
+		if err != nil {
+			logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to fetch message")
+			continue
+		}
+
+		// Keyless messages share one worker, which keeps their stream order.
+		key := []byte(msg.Headers().Get(contracts.HeaderMessageKey))
+		select {
+		case queues[workerForKey(key, len(queues))] <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// work handles the messages of one worker queue in order until it is closed or
+// ctx is done. Cancelling ctx only stops taking new messages: the message in
+// flight is handled and settled (see processMessage). A message that could not
+// be settled is left to JetStream to redeliver.
+func (c *NATSConsumer) work(ctx context.Context, queue <-chan jetstream.Msg, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	for msg := range queue {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := c.processMessage(ctx, msg, handler); err != nil {
+			// The message was not settled, e.g. its ack failed or the DLQ
+			// publish was cut short by a shutdown. JetStream redelivers it
+			// after AckWait, so the worker moves on unless it is stopping.
+			logrus.WithField(logging.FieldTopic, msg.Subject()).WithError(err).Error("Failed to settle message")
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// processMessage runs the handler once and settles the message. When it fails
+// with a transient error the message is naked with the delay of its tier;
+// permanent errors (see IsPermanent) and messages that exhausted every tier go
+// to the DLQ. Messages that break their event contract never reach the
+// handler and are permanent errors. It only returns an error when the message
+// could not be settled, e.g. the DLQ publish did not succeed before ctx was
+// cancelled. The handler and each publish attempt run to the end even if ctx
+// is cancelled meanwhile, so a shutdown does not abort them halfway. The
+// message is processed in a consumer span that continues the trace of its
+// producer.
+func (c *NATSConsumer) processMessage(ctx context.Context, msg jetstream.Msg, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) error {
+	stop := ctx
+	meta, err := msg.Metadata()
+	if err != nil {
+		return fmt.Errorf("error reading message metadata: %w", err)
+	}
+	topic := msg.Subject()
+	key := msg.Headers().Get(contracts.HeaderMessageKey)
+	ctx, span := tracing.StartConsumer(context.WithoutCancel(ctx), propagation.HeaderCarrier(msg.Headers()), tracing.SystemNATS, topic,
+		semconv.MessagingConsumerGroupName(c.GroupID),
+	)
+	defer span.End()
+	// A message redelivered after AckWait also counts as an attempt.
+	info := retryInfo{
+		originalTopic:  topic,
+		originalOffset: int64(meta.Sequence.Stream),
+		attempts:       int(meta.NumDelivered) - 1,
+	}
+
+	envelope, err := decode(c.Serializers, msg.Headers().Get(contracts.HeaderContentType), msg.Data())
+	traceID := contracts.MessageTraceID(msg.Headers().Get(contracts.HeaderTraceID), envelope)
+	if err != nil {
+		err = Permanent(fmt.Errorf("message breaks its event contract: %w", err))
+	} else {
+		stop := c.keepInProgress(msg)
+		handlerCtx := logging.WithFields(contracts.WithTraceID(ctx, traceID), logrus.Fields{
+			logging.FieldTopic:  topic,
+			logging.FieldOffset: meta.Sequence.Stream,
+		})
+		err = handler(handlerCtx, topic, envelope)
+		stop()
+	}
+	if err == nil {
+		return msg.Ack()
+	}
+	tracing.RecordError(span, err)
+	logger := logrus.WithFields(logrus.Fields{
+		logging.FieldTopic:   topic,
+		logging.FieldOffset:  meta.Sequence.Stream,
+		logging.FieldTraceID: traceID,
+		logging.FieldKey:     key,
+	})
+
+	errorClass := ErrorClass(err)
+	metrics.ConsumerErrorsTotal.WithLabelValues(topic, errorClass).Inc()
+	if c.DLQPublisher == nil {
+		logger.WithError(err).Error("Message failed")
+		return msg.Ack()
+	}
+
+	if !IsPermanent(err) && info.attempts < len(c.RetryConfig.Delays) {
+		delay := c.RetryConfig.Delays[info.attempts]
+
+		logger.WithError(err).Warnf("Handler error, attempt %d. Redelivering in %v", info.attempts+1, delay)
+		if err := msg.NakWithDelay(delay); err != nil {
+			return err
+		}
+		// Labelled by tier, like the messages moved to a Kafka retry topic.
+		metrics.ConsumerRetriesTotal.WithLabelValues(topic, c.RetryConfig.RetryTopic(info.attempts)).Inc()
+		return nil
+	}
+
+	if IsPermanent(err) {
+		logger.WithError(err).Warn("Permanent handler error, skipping retries")
+	}
+	logger.WithError(err).Errorf("Message failed after %d attempts", info.attempts+1)
+
+	dlqMessage := models.DLQMessage{
+		OriginalTopic: topic,
+		Key:           key,
+		TraceID:       traceID,
+		Timestamp:     time.Now().UTC(),
+		Attempts:      info.attempts + 1,
+		Error:         err.Error(),
+		ErrorClass:    errorClass,
+		Service:       models.ServiceName,
+		ConsumerGroup: c.GroupID,
+		Offset:        info.originalOffset,
+		Headers:       natsHeadersMap(msg.Headers()),
+	}
+	dlqMessage.SetValue(msg.Data())
+	err = publishUntilDone(stop, c.RetryConfig, func() error {
+		if err := c.DLQPublisher.Publish(contracts.WithTraceID(ctx, traceID), models.PaymentsDLQTopic, dlqMessage); err != nil {
+			return err
+		}
+		metrics.DLQMessagesTotal.WithLabelValues(topic, errorClass).Inc()
+		logger.Info("Message sent to DLQ")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return msg.Ack()
+}
+
+// keepInProgress keeps JetStream from redelivering msg while a slow handler
+// is still running, until the returned function is called.
+func (c *NATSConsumer) keepInProgress(msg jetstream.Msg) func() {
+	if c.AckWait <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.AckWait / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				msg.InProgress()
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func natsHeadersMap(headers nats.Header) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(headers))
+	for key := range headers {
+		m[key] = headers.Get(key)
+	}
+	return m
+}
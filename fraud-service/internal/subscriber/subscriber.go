@@ -2,34 +2,64 @@ package subscriber
 
 import (
 	"context"
-	"log"
-	"math"
-	"math/rand/v2"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-fraud-service/config"
+	"github.com/jeffleon2/draftea-fraud-service/internal/logging"
+	"github.com/jeffleon2/draftea-fraud-service/internal/metrics"
 	"github.com/jeffleon2/draftea-fraud-service/internal/models"
 	"github.com/jeffleon2/draftea-fraud-service/internal/publisher"
+	"github.com/jeffleon2/draftea-fraud-service/internal/tracing"
 	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
 )
 
+// Reader fetches and commits the messages of one topic for a consumer group;
+// *kafka.Reader implements it.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Config() kafka.ReaderConfig
+	Close() error
+}
+
 type KafkaConsumer struct {
-	Readers      []*kafka.Reader
+	Readers      []Reader
+	GroupID      string
 	DQLPublisher *publisher.KafkaPublisher
 	RetryConfig  config.RetryConfig
+	CommitConfig config.CommitConfig
+	Workers      int
+	// Serializers decode messages by their content-type header.
+	Serializers contracts.Serializers
+
+	wg sync.WaitGroup
 }
 
 func NewMultiTopicConsumer(
 	brokers []string,
+	dialer *kafka.Dialer,
 	topics []string,
 	groupID string,
 	publisher *publisher.KafkaPublisher,
 	retryConfig config.RetryConfig,
+	commitConfig config.CommitConfig,
+	workers int,
 ) *KafkaConsumer {
-	readers := make([]*kafka.Reader, len(topics))
+	// Each service consumes its own retry tiers with the same group.
+	topics = append(topics, retryConfig.RetryTopics()...)
+
+	readers := make([]Reader, len(topics))
 	for i, topic := range topics {
 		readers[i] = kafka.NewReader(kafka.ReaderConfig{
 			Brokers:  brokers,
+			Dialer:   dialer,
 			GroupID:  groupID,
 			Topic:    topic,
 			MinBytes: 1,
@@ -39,67 +69,215 @@ func NewMultiTopicConsumer(
 
 	return &KafkaConsumer{
 		Readers:      readers,
+		GroupID:      groupID,
 		DQLPublisher: publisher,
 		RetryConfig:  retryConfig,
+		CommitConfig: commitConfig,
+		Workers:      workers,
+		Serializers:  contracts.DefaultSerializers,
 	}
 }
 
-func (c *KafkaConsumer) Listen(ctx context.Context, handler func(topic string, value []byte) error) {
+// Listen consumes every topic with at-least-once semantics: each message is
+// fetched, handled (or moved to a retry topic or the DLQ) and only then its
+// offset is committed, in batches as configured by CommitConfig. Each topic is
+// handled by Workers goroutines with strict ordering per message key.
+// The handler's context carries the message's trace ID.
+func (c *KafkaConsumer) Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
 	for _, reader := range c.Readers {
-		go func(r *kafka.Reader) {
-			for {
-				msg, err := r.ReadMessage(ctx)
-				if err != nil {
-					log.Println("Kafka error:", err)
-					continue
-				}
-				c.processMessage(ctx, msg, handler)
-			}
+		c.wg.Add(1)
+		go func(r Reader) {
+			defer c.wg.Done()
+			c.consume(ctx, r, handler)
 		}(reader)
 	}
 }
 
-func (c *KafkaConsumer) processMessage(ctx context.Context, msg kafka.Message, handler func(topic string, value []byte) error) {
-	for attempt := 0; attempt < c.RetryConfig.MaxAttempts; attempt++ {
-		err := handler(msg.Topic, msg.Value)
-		if err == nil {
+// Topics returns the topics the consumer reads.
+func (c *KafkaConsumer) Topics() []string {
+	topics := make([]string, len(c.Readers))
+	for i, reader := range c.Readers {
+		topics[i] = reader.Config().Topic
+	}
+	return topics
+}
+
+// Close waits for the listeners to commit their last batch and closes the readers.
+// The context given to Listen must be cancelled first.
+func (c *KafkaConsumer) Close() error {
+	c.wg.Wait()
+
+	var errs []error
+	for _, reader := range c.Readers {
+		errs = append(errs, reader.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// consume fetches the messages of r and hands them to Workers goroutines,
+// routing by key so messages with the same key are handled in order.
+func (c *KafkaConsumer) consume(ctx context.Context, r Reader, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	topic := r.Config().Topic
+	committer := newOffsetCommitter(r, c.CommitConfig)
+	go committer.FlushEvery(ctx, func(err error) {
+		logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to commit offsets")
+	})
+
+	queues := make([]chan kafka.Message, max(c.Workers, 1))
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, workerQueueSize)
+		workers.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer workers.Done()
+			c.work(ctx, queue, committer, handler)
+		}(queues[i])
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		workers.Wait()
+
+		// ctx is already cancelled here; commit what was handled before stopping.
+		if err := committer.Flush(context.Background()); err != nil {
+			logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to commit offsets on shutdown")
+		}
+	}()
+
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if ctx.Err() != nil {
 			return
 		}
+		if err != nil {
+			logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to fetch message")
+			continue
+		}
 
-		backoff := c.calculateBackoff(attempt)
-		log.Printf("Handler error, attempt %d/%d: %v. Retrying in %v", attempt+1, c.RetryConfig.MaxAttempts, err, backoff)
-		time.Sleep(backoff)
+		// Messages of a retry tier wait for their delay; the tier's later
+		// messages are due later still, so nothing else is held back.
+		if notBefore := readRetryInfo(msg).notBefore; time.Until(notBefore) > 0 {
+			if waitUntil(ctx, notBefore) != nil {
+				return
+			}
+		}
+
+		committer.Track(msg)
+		select {
+		case queues[workerFor(msg, len(queues))] <- msg:
+		case <-ctx.Done():
+			return
+		}
 	}
+}
 
-	log.Printf("Message failed after %d retries: topic=%s, key=%s", c.RetryConfig.MaxAttempts, msg.Topic, string(msg.Key))
-	if c.DQLPublisher != nil {
-		dlqMessage := models.DLQMessage{
-			OriginalTopic: msg.Topic,
-			Key:           string(msg.Key),
-			Value:         string(msg.Value),
-			Timestamp:     time.Now().UTC(),
-			Attempts:      c.RetryConfig.MaxAttempts,
+// work handles the messages of one worker queue in order until it is closed or
+// ctx is done. Cancelling ctx only stops taking new messages: the message in
+// flight is handled and its offset committed with a context that is not
+// cancelled, so a shutdown does not abort its database writes and publishes.
+func (c *KafkaConsumer) work(ctx context.Context, queue <-chan kafka.Message, committer *offsetCommitter, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	for msg := range queue {
+		if ctx.Err() != nil {
+			return
 		}
-		err := c.DQLPublisher.Publish(ctx, models.PaymentsDLQTopic, dlqMessage)
-		if err != nil {
-			log.Printf("Failed to send message to DLQ: %v", err)
-		} else {
-			log.Printf("Message sent to DLQ: original topic=%s, key=%s", msg.Topic, string(msg.Key))
+
+		if err := c.processMessage(ctx, msg, handler); err != nil {
+			// The message was neither handled nor dead-lettered; its offset, and
+			// every later one of its partition, must not be committed.
+			logrus.WithFields(logging.Message(msg.Topic, msg.Partition, msg.Offset)).WithError(err).Error("Stopping worker without committing")
+			return
+		}
+
+		if err := committer.Done(context.WithoutCancel(ctx), msg); err != nil {
+			logrus.WithFields(logging.Message(msg.Topic, msg.Partition, msg.Offset)).WithError(err).Error("Failed to commit offsets")
 		}
 	}
 }
 
-func (c *KafkaConsumer) calculateBackoff(attempt int) time.Duration {
-	delay := time.Duration(math.Pow(2, float64(attempt))) * c.RetryConfig.BaseDelay
+// processMessage runs the handler once. When it fails with a transient error
+// the message is moved to the next retry topic (see RetryConfig.Delays) so the
+// partition is not blocked; permanent errors (see IsPermanent) and messages
+// that exhausted every tier go to the DLQ. Messages that break their event
+// contract never reach the handler and are permanent errors. It only returns
+// an error when the message could not be published before ctx was cancelled.
+// The handler and each publish attempt run to the end even if ctx is
+// cancelled meanwhile. The message is processed in a consumer span that
+// continues the trace of its producer.
+func (c *KafkaConsumer) processMessage(ctx context.Context, msg kafka.Message, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) error {
+	stop := ctx
+	info := readRetryInfo(msg)
+	ctx, span := tracing.StartConsumer(context.WithoutCancel(ctx), (*tracing.KafkaHeaders)(&msg.Headers), tracing.SystemKafka, info.originalTopic,
+		semconv.MessagingConsumerGroupName(c.GroupID),
+		semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+		semconv.MessagingKafkaOffset(int(msg.Offset)),
+	)
+	defer span.End()
 
-	if delay > c.RetryConfig.MaxDelay {
-		delay = c.RetryConfig.MaxDelay
+	envelope, err := decode(c.Serializers, headersMap(msg.Headers)[contracts.HeaderContentType], msg.Value)
+	traceID := contracts.MessageTraceID(headersMap(msg.Headers)[contracts.HeaderTraceID], envelope)
+	if err != nil {
+		err = Permanent(fmt.Errorf("message breaks its event contract: %w", err))
+	} else {
+		handlerCtx := logging.WithFields(contracts.WithTraceID(ctx, traceID), logging.Message(msg.Topic, msg.Partition, msg.Offset))
+		err = handler(handlerCtx, info.originalTopic, envelope)
+	}
+	if err == nil {
+		return nil
 	}
+	tracing.RecordError(span, err)
+	logger := logrus.WithFields(logging.Message(msg.Topic, msg.Partition, msg.Offset)).WithFields(logrus.Fields{
+		logging.FieldTraceID: traceID,
+		logging.FieldKey:     string(msg.Key),
+	})
 
-	if c.RetryConfig.Jitter {
-		jitter := time.Duration(rand.Float64() * float64(delay) * 0.3)
-		delay = delay + jitter - time.Duration(float64(delay)*0.15)
+	errorClass := ErrorClass(err)
+	metrics.ConsumerErrorsTotal.WithLabelValues(info.originalTopic, errorClass).Inc()
+	if c.DQLPublisher == nil {
+		logger.WithError(err).Error("Message failed")
+		return nil
 	}
 
-	return delay
+	if retryTopic, delay, ok := nextRetryTier(c.RetryConfig, info, err); ok {
+		retry := retryMessage(msg, info, err, delay)
+
+		logger.WithError(err).Warnf("Handler error, attempt %d. Retrying in %v via %s", info.attempts+1, delay, retryTopic)
+		return publishUntilDone(stop, c.RetryConfig, func() error {
+			if err := c.DQLPublisher.PublishMessage(ctx, retryTopic, retry); err != nil {
+				return err
+			}
+			metrics.ConsumerRetriesTotal.WithLabelValues(info.originalTopic, retryTopic).Inc()
+			return nil
+		})
+	}
+
+	if IsPermanent(err) {
+		logger.WithError(err).Warn("Permanent handler error, skipping retries")
+	}
+	logger.WithError(err).Errorf("Message failed after %d attempts", info.attempts+1)
+
+	dlqMessage := models.DLQMessage{
+		OriginalTopic: info.originalTopic,
+		Key:           string(msg.Key),
+		TraceID:       traceID,
+		Timestamp:     time.Now().UTC(),
+		Attempts:      info.attempts + 1,
+		Error:         err.Error(),
+		ErrorClass:    errorClass,
+		Service:       models.ServiceName,
+		ConsumerGroup: c.GroupID,
+		Partition:     info.originalPartition,
+		Offset:        info.originalOffset,
+		Headers:       headersMap(msg.Headers),
+	}
+	dlqMessage.SetValue(msg.Value)
+	return publishUntilDone(stop, c.RetryConfig, func() error {
+		if err := c.DQLPublisher.Publish(contracts.WithTraceID(ctx, traceID), models.PaymentsDLQTopic, dlqMessage); err != nil {
+			return err
+		}
+		metrics.DLQMessagesTotal.WithLabelValues(info.originalTopic, errorClass).Inc()
+		logger.Infof("Message sent to DLQ from %s", info.originalTopic)
+		return nil
+	})
 }
@@ -0,0 +1,52 @@
+package subscriber
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerForKeepsKeysOnOneWorker(t *testing.T) {
+	const workers = 8
+
+	for i := range 100 {
+		key := []byte(fmt.Sprintf("payment-%d", i))
+		want := workerFor(kafka.Message{Key: key, Partition: 0}, workers)
+
+		for partition := range 3 {
+			got := workerFor(kafka.Message{Key: key, Partition: partition, Offset: int64(i)}, workers)
+			assert.Equal(t, want, got, "key %s moved to another worker", key)
+		}
+		assert.Less(t, want, workers)
+	}
+}
+
+func TestWorkerForSpreadsKeys(t *testing.T) {
+	const workers = 8
+
+	used := map[int]bool{}
+	for i := range 100 {
+		used[workerFor(kafka.Message{Key: []byte(fmt.Sprintf("payment-%d", i))}, workers)] = true
+	}
+
+	assert.Len(t, used, workers, "100 keys should reach every worker")
+}
+
+func TestWorkerForRoutesKeylessMessagesByPartition(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    kafka.Message
+		workers int
+	}{
+		{name: "same partition", a: kafka.Message{Partition: 3, Offset: 1}, b: kafka.Message{Partition: 3, Offset: 9}, workers: 8},
+		{name: "single worker", a: kafka.Message{Key: []byte("payment-1")}, b: kafka.Message{Partition: 5}, workers: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, workerFor(tt.a, tt.workers), workerFor(tt.b, tt.workers))
+		})
+	}
+}
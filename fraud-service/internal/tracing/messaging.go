@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Messaging systems of the spans.
+const (
+	SystemKafka = "kafka"
+	SystemNATS  = "nats"
+)
+
+// StartProducer starts the span of a publish to topic. Its context must be
+// injected (see Inject) into the headers of the published messages.
+func StartProducer(ctx context.Context, system, topic string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "send "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingOperationTypeSend,
+		),
+	)
+}
+
+// StartConsumer starts the span that processes a message of topic, as a
+// child of the producer span whose context travels in the message headers.
+func StartConsumer(ctx context.Context, headers propagation.TextMapCarrier, system, topic string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headers)
+	return tracer().Start(ctx, "process "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingOperationTypeProcess,
+		),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// Inject writes the trace context of ctx into headers.
+func Inject(ctx context.Context, headers propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+}
+
+// KafkaHeaders adapts the headers of a Kafka message to
+// propagation.TextMapCarrier.
+type KafkaHeaders []kafka.Header
+
+// Get returns the value of the header key, or "".
+func (h *KafkaHeaders) Get(key string) string {
+	for _, header := range *h {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces the header key, or adds it.
+func (h *KafkaHeaders) Set(key, value string) {
+	for i, header := range *h {
+		if header.Key == key {
+			(*h)[i].Value = []byte(value)
+			return
+		}
+	}
+	*h = append(*h, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys returns the keys of every header.
+func (h *KafkaHeaders) Keys() []string {
+	keys := make([]string, len(*h))
+	for i, header := range *h {
+		keys[i] = header.Key
+	}
+	return keys
+}
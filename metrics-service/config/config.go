@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 	"github.com/joho/godotenv"
@@ -25,6 +26,9 @@ type Config struct {
 	APP
 	DB
 	Kafka
+	NATS
+	Tracing
+	Logging
 }
 
 type DB struct {
@@ -38,10 +42,45 @@ type DB struct {
 
 type APP struct {
 	PORT string `env:"APP_PORT" envDefault:"8080"`
+	// Transport is the message broker the events go through: kafka or nats.
+	Transport string `env:"MESSAGE_TRANSPORT" envDefault:"kafka"`
+	// HealthCheckTimeout bounds the dependency checks of /readyz.
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	// ShutdownTimeout bounds the graceful shutdown after SIGTERM; it must be
+	// shorter than the grace period of the orchestrator.
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"25s"`
 }
 
 type Kafka struct {
 	Brokers          string `env:"KAFKA_BROKERS" envDefault:"localhost:9092"`
 	ConsumerGroup    string `env:"KAFKA_SUBSCRIBER_GROUP_ID" envDefault:"metric-service"`
 	SubscriberTopics string `env:"KAFKA_SUBSCRIBER_TOPICS" envDefault:"payments.created,payments.checked,wallet.funds.verified,wallet.debit.requested"`
+
+	TLSEnabled            bool   `env:"KAFKA_TLS_ENABLED" envDefault:"false"`
+	TLSCAFile             string `env:"KAFKA_TLS_CA_FILE"`
+	TLSCertFile           string `env:"KAFKA_TLS_CERT_FILE"`
+	TLSKeyFile            string `env:"KAFKA_TLS_KEY_FILE"`
+	TLSInsecureSkipVerify bool   `env:"KAFKA_TLS_INSECURE_SKIP_VERIFY" envDefault:"false"`
+	SASLMechanism         string `env:"KAFKA_SASL_MECHANISM"`
+	SASLUsername          string `env:"KAFKA_SASL_USERNAME"`
+	SASLPassword          string `env:"KAFKA_SASL_PASSWORD"`
+
+	CommitBatchSize int           `env:"KAFKA_COMMIT_BATCH_SIZE" envDefault:"100"`
+	CommitInterval  time.Duration `env:"KAFKA_COMMIT_INTERVAL" envDefault:"1s"`
+
+	// ConsumerWorkers is the number of goroutines handling each topic.
+	// Messages with the same key are always handled by the same one, in order.
+	ConsumerWorkers int `env:"KAFKA_CONSUMER_WORKERS" envDefault:"8"`
+}
+
+type CommitConfig struct {
+	BatchSize int
+	Interval  time.Duration
+}
+
+func (k Kafka) GetCommitConfig() CommitConfig {
+	return CommitConfig{
+		BatchSize: k.CommitBatchSize,
+		Interval:  k.CommitInterval,
+	}
 }
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate writes a self-signed certificate and its key as PEM files
+// in a temporary directory.
+func writeCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kafka-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestKafkaTLSConfig(t *testing.T) {
+	certFile, keyFile := writeCertificate(t)
+	notPEM := filepath.Join(t.TempDir(), "ca.txt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		name      string
+		kafka     Kafka
+		wantNil   bool
+		wantCA    bool
+		wantCerts int
+		wantErr   string
+	}{
+		{name: "disabled", kafka: Kafka{TLSEnabled: false, TLSCAFile: "/missing.pem"}, wantNil: true},
+		{name: "system roots", kafka: Kafka{TLSEnabled: true}},
+		{name: "custom CA", kafka: Kafka{TLSEnabled: true, TLSCAFile: certFile}, wantCA: true},
+		{name: "missing CA file", kafka: Kafka{TLSEnabled: true, TLSCAFile: "/missing.pem"}, wantErr: "KAFKA_TLS_CA_FILE"},
+		{name: "CA file without certificates", kafka: Kafka{TLSEnabled: true, TLSCAFile: notPEM}, wantErr: "has no PEM certificates"},
+		{name: "client certificate", kafka: Kafka{TLSEnabled: true, TLSCertFile: certFile, TLSKeyFile: keyFile}, wantCerts: 1},
+		{name: "certificate without key", kafka: Kafka{TLSEnabled: true, TLSCertFile: certFile}, wantErr: "must be set together"},
+		{name: "key without certificate", kafka: Kafka{TLSEnabled: true, TLSKeyFile: keyFile}, wantErr: "must be set together"},
+		{name: "certificate and key swapped", kafka: Kafka{TLSEnabled: true, TLSCertFile: keyFile, TLSKeyFile: certFile}, wantErr: "client certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.kafka.tlsConfig()
+
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, tlsConfig)
+				return
+			}
+			require.NotNil(t, tlsConfig)
+			assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+			assert.Equal(t, tt.wantCA, tlsConfig.RootCAs != nil)
+			assert.Len(t, tlsConfig.Certificates, tt.wantCerts)
+		})
+	}
+}
+
+func TestKafkaSASLMechanism(t *testing.T) {
+	tests := []struct {
+		name     string
+		kafka    Kafka
+		wantName string
+		wantErr  string
+	}{
+		{name: "disabled", kafka: Kafka{SASLUsername: "user"}},
+		{name: "plain", kafka: Kafka{SASLMechanism: "PLAIN", SASLUsername: "user", SASLPassword: "secret"}, wantName: SASLPlain},
+		{name: "scram sha-256 in lower case", kafka: Kafka{SASLMechanism: " scram-sha-256 ", SASLUsername: "user", SASLPassword: "secret"}, wantName: SASLScramSHA256},
+		{name: "scram sha-512", kafka: Kafka{SASLMechanism: "SCRAM-SHA-512", SASLUsername: "user", SASLPassword: "secret"}, wantName: SASLScramSHA512},
+		{name: "unknown mechanism", kafka: Kafka{SASLMechanism: "GSSAPI", SASLUsername: "user", SASLPassword: "secret"}, wantErr: "unsupported KAFKA_SASL_MECHANISM"},
+		{name: "missing username", kafka: Kafka{SASLMechanism: "PLAIN", SASLPassword: "secret"}, wantErr: "KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD are required"},
+		{name: "missing password", kafka: Kafka{SASLMechanism: "SCRAM-SHA-512", SASLUsername: "user"}, wantErr: "KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD are required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mechanism, err := tt.kafka.saslMechanism()
+
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantName == "" {
+				assert.Nil(t, mechanism)
+				return
+			}
+			require.NotNil(t, mechanism)
+			assert.Equal(t, tt.wantName, mechanism.Name())
+		})
+	}
+}
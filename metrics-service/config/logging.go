@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Supported values of LOG_FORMAT.
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Logging configures the logger of the service. Level is a logrus level
+// (debug, info, warn, error); Format is json for log aggregation or text for
+// reading the logs in a terminal.
+type Logging struct {
+	Level  string `env:"LOG_LEVEL" envDefault:"info"`
+	Format string `env:"LOG_FORMAT" envDefault:"json"`
+}
+
+// LogLevel returns the configured LOG_LEVEL.
+func (l Logging) LogLevel() (logrus.Level, error) {
+	level, err := logrus.ParseLevel(strings.TrimSpace(l.Level))
+	if err != nil {
+		return 0, fmt.Errorf("unsupported LOG_LEVEL %q (use debug, info, warn or error)", l.Level)
+	}
+	return level, nil
+}
+
+// LogFormat returns the configured LOG_FORMAT.
+func (l Logging) LogFormat() (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(l.Format)); format {
+	case "", LogFormatJSON:
+		return LogFormatJSON, nil
+	case LogFormatText:
+		return LogFormatText, nil
+	default:
+		return "", fmt.Errorf("unsupported LOG_FORMAT %q (use %s or %s)", l.Format, LogFormatJSON, LogFormatText)
+	}
+}
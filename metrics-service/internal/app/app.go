@@ -2,55 +2,191 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-metric-service/config"
 	"github.com/jeffleon2/draftea-metric-service/internal/handler"
+	"github.com/jeffleon2/draftea-metric-service/internal/health"
+	"github.com/jeffleon2/draftea-metric-service/internal/logging"
 	"github.com/jeffleon2/draftea-metric-service/internal/metrics"
 	"github.com/jeffleon2/draftea-metric-service/internal/subscriber"
+	"github.com/nats-io/nats.go"
 	"github.com/sirupsen/logrus"
 )
 
 type App struct {
 	config *config.Config
 	Router *gin.Engine
+	Health *health.Checker
+
+	server   *http.Server
+	consumer eventConsumer
+	// natsConn is the NATS connection, nil with Kafka.
+	natsConn *nats.Conn
+	// stop cancels the context of the consumers.
+	stop context.CancelFunc
 }
 
 func (a *App) Initialize(cfg *config.Config) {
 	a.config = cfg
+	a.Health = health.NewChecker(cfg.APP.HealthCheckTimeout)
+	ctx, stop := context.WithCancel(context.Background())
+	a.stop = stop
 
 	metrics.RegisterMetrics()
 	metricHandler := handler.NewMetricHandler()
-	a.Router = gin.Default()
+	a.Router = gin.New()
 	a.Router.Use(gin.Recovery())
 	a.RegisterRoutes()
-	a.initSubscribers(metricHandler)
+	a.server = &http.Server{Addr: fmt.Sprintf(":%s", cfg.APP.PORT), Handler: a.Router}
+	a.initSubscribers(ctx, metricHandler)
+	a.Health.Ready()
 }
 
-func (a *App) initSubscribers(metricsHandler *handler.MetricsHandler) {
-	brokers := strings.Split(a.config.Kafka.Brokers, ",")
-	topics := strings.Split(a.config.Kafka.SubscriberTopics, ",")
-	groupID := a.config.Kafka.ConsumerGroup
+// Run serves HTTP until ctx is done or the server fails, and then shuts the
+// service down within APP.ShutdownTimeout.
+func (a *App) Run(ctx context.Context) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- a.server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		logrus.Info("Shutting down")
+	case serveErr := <-served:
+		err = fmt.Errorf("serving http: %w", serveErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.APP.ShutdownTimeout)
+	defer cancel()
+	return errors.Join(err, a.Shutdown(shutdownCtx))
+}
+
+// Shutdown stops the service without losing work in flight. It takes the
+// service out of readiness, stops serving HTTP, stops fetching messages and
+// waits for the handlers in flight and the commit of their offsets, and then
+// closes the NATS connection. Steps not done when ctx is done are abandoned.
+func (a *App) Shutdown(ctx context.Context) error {
+	a.Health.Stopping()
+
+	var errs []error
+	if err := a.server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("stopping http server: %w", err))
+	}
+
+	a.stop()
+	if err := within(ctx, a.consumer.Close); err != nil {
+		errs = append(errs, fmt.Errorf("draining consumer: %w", err))
+	}
+	if a.natsConn != nil {
+		if err := within(ctx, a.natsConn.Drain); err != nil {
+			errs = append(errs, fmt.Errorf("closing NATS connection: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// within runs fn unless ctx is already done and waits for it until ctx is done.
+func within(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// eventConsumer is the multi-topic consumer of the configured transport.
+type eventConsumer interface {
+	Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope))
+	Close() error
+}
+
+// initSubscribers connects to the configured transport and consumes until
+// ctx is cancelled.
+func (a *App) initSubscribers(ctx context.Context, metricsHandler *handler.MetricsHandler) {
+	transport, err := a.config.APP.MessageTransport()
+	if err != nil {
+		logrus.Fatalf("invalid configuration: %v", err)
+	}
 
-	consumer := subscriber.NewMultiTopicConsumer(brokers, topics, groupID)
+	switch transport {
+	case config.TransportNATS:
+		a.consumer = a.natsConsumer(ctx)
+	default:
+		a.consumer = a.kafkaConsumer(ctx)
+	}
 
-	ctx := context.Background()
-	go consumer.Listen(ctx, func(topic string, value []byte) {
-		log.Printf("📩 Received message → topic=%s value=%s\n", topic, string(value))
-		err := metricsHandler.HandleEvents(ctx, topic, value)
-		if err != nil {
-			logrus.Error(err.Error())
+	a.consumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) {
+		logger := logging.FromContext(ctx)
+		logger.Infof("Received %s event %s", envelope.Type, envelope.ID)
+		if err := metricsHandler.HandleEvents(ctx, topic, envelope); err != nil {
+			logger.Error(err.Error())
 		}
 	})
+}
+
+func (a *App) kafkaConsumer(ctx context.Context) *subscriber.KafkaConsumer {
+	dialer, err := a.config.Kafka.Dialer()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	if err := a.config.Kafka.CheckConnection(ctx, dialer); err != nil {
+		logrus.Fatalf("failed to connect to Kafka: %v", err)
+	}
+
+	transport, err := a.config.Kafka.Transport()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
 
+	brokers := a.config.Kafka.BrokerList()
+	topics := strings.Split(a.config.Kafka.SubscriberTopics, ",")
+	groupID := a.config.Kafka.ConsumerGroup
+
+	consumer := subscriber.NewMultiTopicConsumer(brokers, dialer, topics, groupID, a.config.Kafka.GetCommitConfig(), a.config.Kafka.ConsumerWorkers)
+	a.Health.Critical("kafka", func(ctx context.Context) error {
+		return a.config.Kafka.CheckConnection(ctx, dialer)
+	})
+	a.Health.Optional("kafka_consumer_group", func(ctx context.Context) error {
+		return a.config.Kafka.CheckConsumerGroup(ctx, transport, groupID, consumer.Topics())
+	})
+	return consumer
 }
 
-func (a *App) Run() {
-	err := a.Router.Run(fmt.Sprintf(":%s", a.config.APP.PORT))
+// natsConsumer connects to NATS, creates the streams of the subscribed topics
+// and returns their JetStream consumer. Topics and consumer group are the
+// KAFKA_* settings.
+func (a *App) natsConsumer(ctx context.Context) *subscriber.NATSConsumer {
+	conn, js, err := a.config.NATS.Connect()
+	if err != nil {
+		logrus.Fatalf("failed to connect to NATS: %v", err)
+	}
+	a.Health.Critical("nats", health.NATS(conn))
+	a.natsConn = conn
+
+	topics := strings.Split(a.config.Kafka.SubscriberTopics, ",")
+	if err := a.config.NATS.EnsureStreams(ctx, js, topics); err != nil {
+		logrus.Fatalf("failed to create NATS streams: %v", err)
+	}
+
+	consumer, err := subscriber.NewNATSConsumer(ctx, js, topics, a.config.Kafka.ConsumerGroup, a.config.NATS, a.config.Kafka.ConsumerWorkers)
 	if err != nil {
-		panic(err)
+		logrus.Fatalf("failed to create NATS consumers: %v", err)
 	}
+	return consumer
 }
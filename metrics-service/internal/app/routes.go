@@ -9,4 +9,6 @@ func (a *App) RegisterRoutes() {
 	app := a.Router.Group("/metrics")
 	app.GET("", gin.WrapH(promhttp.Handler()))
 
+	a.Router.GET("/healthz", gin.WrapF(a.Health.Liveness))
+	a.Router.GET("/readyz", gin.WrapF(a.Health.Readiness))
 }
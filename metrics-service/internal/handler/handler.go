@@ -2,9 +2,9 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
-	"log"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-metric-service/internal/logging"
 	"github.com/jeffleon2/draftea-metric-service/internal/metrics"
 	"github.com/jeffleon2/draftea-metric-service/internal/models"
 )
@@ -16,22 +16,26 @@ func NewMetricHandler() *MetricsHandler {
 	return &MetricsHandler{}
 }
 
-func (h *MetricsHandler) HandleEvents(ctx context.Context, topic string, value []byte) error {
-	switch topic {
-	case models.TopicPaymentsCreated:
+// HandleEvents records the metrics of an event envelope, already decoded and
+// validated by the subscriber whatever its format.
+func (h *MetricsHandler) HandleEvents(ctx context.Context, topic string, envelope contracts.Envelope) error {
+	logger := logging.FromContext(ctx)
+
+	switch envelope.Type {
+	case models.EventTypePaymentCreated:
 		var evt models.PaymentCreatedEvent
-		if err := json.Unmarshal(value, &evt); err != nil {
-			log.Println("Error unmarshaling PaymentCreatedEvent:", err)
+		if err := envelope.Decode(&evt); err != nil {
+			logger.Errorf("Error unmarshaling PaymentCreatedEvent: %v", err)
 			return err
 		}
 
 		metrics.PaymentsTotal.WithLabelValues("created").Inc()
 		metrics.PaymentAmounts.WithLabelValues(evt.Currency).Observe(evt.Amount)
 
-	case models.TopicPaymentsChecked:
+	case models.EventTypePaymentChecked:
 		var evt models.FraudCheckEvent
-		if err := json.Unmarshal(value, &evt); err != nil {
-			log.Println("Error unmarshaling FraudCheckEvent:", err)
+		if err := envelope.Decode(&evt); err != nil {
+			logger.Errorf("Error unmarshaling FraudCheckEvent: %v", err)
 			return err
 		}
 
@@ -42,27 +46,27 @@ func (h *MetricsHandler) HandleEvents(ctx context.Context, topic string, value [
 
 		metrics.FraudChecksTotal.WithLabelValues(statusLabel).Inc()
 
-	case models.TopicWalletResponse:
+	case models.EventTypeWalletFundsVerified:
 		var evt models.WalletResponseEvent
-		if err := json.Unmarshal(value, &evt); err != nil {
-			log.Println("Error unmarshaling WalletResponseEvent:", err)
+		if err := envelope.Decode(&evt); err != nil {
+			logger.Errorf("Error unmarshaling WalletResponseEvent: %v", err)
 			return err
 		}
 
-		metrics.WalletResponsesTotal.WithLabelValues(evt.Status).Inc()
+		metrics.WalletResponsesTotal.WithLabelValues(string(evt.Status)).Inc()
 		metrics.WalletAmounts.WithLabelValues(evt.UserID).Observe(evt.Amount)
 
-	case models.TopicWalletDebitRequested:
+	case models.EventTypeWalletDebitRequested:
 		var evt models.WalletDebitRequestedEvent
-		if err := json.Unmarshal(value, &evt); err != nil {
-			log.Println("Error unmarshaling WalletDebitRequestedEvent:", err)
+		if err := envelope.Decode(&evt); err != nil {
+			logger.Errorf("Error unmarshaling WalletDebitRequestedEvent: %v", err)
 			return err
 		}
 
 		metrics.WalletDebits.WithLabelValues(evt.UserID).Observe(evt.Amount)
 
 	default:
-		log.Println("Evento desconocido:", topic)
+		logger.WithField(logging.FieldTopic, topic).Warnf("Unknown event type %s", envelope.Type)
 	}
 
 	return nil
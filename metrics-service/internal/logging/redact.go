@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretKeys are the parts of the field names and key=value pairs whose
+// value is never logged.
+var secretKeys = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey", "dsn"}
+
+// customerKeys are the fields that identify a customer. Message keys are
+// among them because wallet events are keyed by user.
+var customerKeys = map[string]bool{
+	FieldCustomerID: true,
+	"user_id":       true,
+	"userid":        true,
+	"customerid":    true,
+	FieldKey:        true,
+}
+
+var (
+	secretPattern   = regexp.MustCompile(`(?i)\b(password|passwd|secret|token|authorization|api_?key)("?\s*[=:]\s*"?)([^\s"',}&]+)`)
+	customerPattern = regexp.MustCompile(`(?i)\b(customer_?id|user_?id)("?\s*[=:]\s*"?)([^\s"',}&]+)`)
+)
+
+// Redact masks the values of the secrets and customer identifiers written
+// as key=value or "key":"value" pairs in text.
+func Redact(text string) string {
+	text = secretPattern.ReplaceAllString(text, "${1}${2}"+redacted)
+	return customerPattern.ReplaceAllStringFunc(text, func(pair string) string {
+		match := customerPattern.FindStringSubmatch(pair)
+		return match[1] + match[2] + MaskCustomerID(match[3])
+	})
+}
+
+// MaskCustomerID replaces id by a short digest, so the entries of one
+// customer can still be correlated without exposing who the customer is.
+func MaskCustomerID(id string) string {
+	if id == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(id))
+	return "cust-" + hex.EncodeToString(sum[:4])
+}
+
+func redactField(key string, value any) any {
+	name := strings.ToLower(key)
+	if customerKeys[name] {
+		return MaskCustomerID(fmt.Sprint(value))
+	}
+	for _, secret := range secretKeys {
+		if strings.Contains(name, secret) {
+			return redacted
+		}
+	}
+
+	switch value := value.(type) {
+	case string:
+		return Redact(value)
+	case error:
+		return Redact(value.Error())
+	case fmt.Stringer:
+		return Redact(value.String())
+	default:
+		return value
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+import contracts "github.com/jeffleon2/draftea-event-contracts"
+
+// Event types carried in the envelope (see contracts.Envelope). Each one
+// matches the topic the event is published to.
+const (
+	EventTypePaymentCreated       = contracts.EventTypePaymentCreated
+	EventTypePaymentChecked       = contracts.EventTypePaymentChecked
+	EventTypeWalletFundsVerified  = contracts.EventTypeWalletFundsVerified
+	EventTypeWalletDebitRequested = contracts.EventTypeWalletDebitRequested
+)
@@ -0,0 +1,165 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-metric-service/config"
+	"github.com/jeffleon2/draftea-metric-service/internal/logging"
+	"github.com/jeffleon2/draftea-metric-service/internal/tracing"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+// NATSConsumer consumes topics from JetStream behind the same Listen contract
+// as KafkaConsumer. Every topic has a durable pull consumer named after the
+// group, so the instances of the service share its messages and a restarted
+// instance resumes where the group left off.
+type NATSConsumer struct {
+	Consumers map[string]jetstream.Consumer
+	GroupID   string
+	Workers   int
+	// Serializers decode messages by their content-type header.
+	Serializers contracts.Serializers
+
+	wg sync.WaitGroup
+}
+
+// NewNATSConsumer creates or updates the durable consumer of groupID on the
+// stream of every topic.
+func NewNATSConsumer(ctx context.Context, js jetstream.JetStream, topics []string, groupID string, natsConfig config.NATS, workers int) (*NATSConsumer, error) {
+	consumers := make(map[string]jetstream.Consumer, len(topics))
+	for _, topic := range topics {
+		consumer, err := js.CreateOrUpdateConsumer(ctx, config.StreamName(topic), jetstream.ConsumerConfig{
+			Durable:       groupID,
+			FilterSubject: topic,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       natsConfig.AckWait,
+			MaxAckPending: natsConfig.MaxAckPending,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating consumer for topic %s: %w", topic, err)
+		}
+		consumers[topic] = consumer
+	}
+
+	return &NATSConsumer{Consumers: consumers, GroupID: groupID, Workers: workers, Serializers: contracts.DefaultSerializers}, nil
+}
+
+// Listen consumes every topic with at-least-once semantics: messages are only
+// acknowledged after the handler returns. Each topic is handled by Workers
+// goroutines with strict ordering per message key. The handler's context
+// carries the message's trace ID.
+func (c *NATSConsumer) Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope)) {
+	for topic, consumer := range c.Consumers {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.consume(ctx, topic, consumer, handler)
+		}()
+	}
+}
+
+// Close waits for the listeners to stop. The context given to Listen must be
+// cancelled first. Messages fetched but not acknowledged are redelivered
+// after AckWait.
+func (c *NATSConsumer) Close() error {
+	c.wg.Wait()
+	return nil
+}
+
+// consume fetches the messages of consumer and hands them to Workers
+// goroutines, routing by key so messages with the same key are handled in
+// order.
+func (c *NATSConsumer) consume(ctx context.Context, topic string, consumer jetstream.Consumer, handler func(ctx context.Context, topic string, envelope contracts.Envelope)) {
+	messages, err := consumer.Messages()
+	if err != nil {
+		logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to consume topic")
+		return
+	}
+	defer messages.Stop()
+
+	queues := make([]chan jetstream.Msg, max(c.Workers, 1))
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan jetstream.Msg, workerQueueSize)
+		workers.Add(1)
+		go func(queue <-chan jetstream.Msg) {
+			defer workers.Done()
+			c.work(ctx, queue, handler)
+		}(queues[i])
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		workers.Wait()
+	}()
+
+	for {
+		msg, err := messages.Next(jetstream.NextContext(ctx))
+		if ctx.Err() != nil || errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+			return
+		}
+		if err != nil {
+			logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to fetch message")
+			continue
+		}
+
+		// Keyless messages share one worker, which keeps their stream order.
+		key := []byte(msg.Headers().Get(contracts.HeaderMessageKey))
+		select {
+		case queues[workerForKey(key, len(queues))] <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// work handles the messages of one worker queue in order until it is closed or
+// ctx is done. Cancelling ctx only stops taking new messages: the message in
+// flight is handled, with a context that is not cancelled, and acknowledged.
+func (c *NATSConsumer) work(ctx context.Context, queue <-chan jetstream.Msg, handler func(ctx context.Context, topic string, envelope contracts.Envelope)) {
+	for msg := range queue {
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.processMessage(context.WithoutCancel(ctx), msg, handler)
+
+		if err := msg.Ack(); err != nil {
+			logrus.WithField(logging.FieldTopic, msg.Subject()).WithError(err).Error("Failed to acknowledge message")
+		}
+	}
+}
+
+// processMessage runs the handler once in a consumer span that continues the
+// trace of the producer of msg. Messages that break their event contract are
+// logged and skipped.
+func (c *NATSConsumer) processMessage(ctx context.Context, msg jetstream.Msg, handler func(ctx context.Context, topic string, envelope contracts.Envelope)) {
+	ctx, span := tracing.StartConsumer(ctx, propagation.HeaderCarrier(msg.Headers()), tracing.SystemNATS, msg.Subject(),
+		semconv.MessagingConsumerGroupName(c.GroupID),
+	)
+	defer span.End()
+
+	fields := logrus.Fields{logging.FieldTopic: msg.Subject()}
+	if meta, err := msg.Metadata(); err == nil {
+		fields[logging.FieldOffset] = meta.Sequence.Stream
+	}
+
+	envelope, err := decode(c.Serializers, msg.Headers().Get(contracts.HeaderContentType), msg.Data())
+	if err != nil {
+		logrus.WithFields(fields).
+			WithField(logging.FieldTraceID, msg.Headers().Get(contracts.HeaderTraceID)).
+			WithError(err).Warn("Skipping message that breaks its event contract")
+		return
+	}
+	traceID := contracts.MessageTraceID(msg.Headers().Get(contracts.HeaderTraceID), envelope)
+	handler(logging.WithFields(contracts.WithTraceID(ctx, traceID), fields), msg.Subject(), envelope)
+}
@@ -2,20 +2,45 @@ package subscriber
 
 import (
 	"context"
-	"log"
+	"errors"
+	"strconv"
+	"sync"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-metric-service/config"
+	"github.com/jeffleon2/draftea-metric-service/internal/logging"
+	"github.com/jeffleon2/draftea-metric-service/internal/tracing"
 	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
 )
 
+// Reader fetches and commits the messages of one topic for a consumer group;
+// *kafka.Reader implements it.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Config() kafka.ReaderConfig
+	Close() error
+}
+
 type KafkaConsumer struct {
-	Readers []*kafka.Reader
+	Readers      []Reader
+	GroupID      string
+	CommitConfig config.CommitConfig
+	Workers      int
+	// Serializers decode messages by their content-type header.
+	Serializers contracts.Serializers
+
+	wg sync.WaitGroup
 }
 
-func NewMultiTopicConsumer(brokers []string, topics []string, groupID string) *KafkaConsumer {
-	readers := make([]*kafka.Reader, len(topics))
+func NewMultiTopicConsumer(brokers []string, dialer *kafka.Dialer, topics []string, groupID string, commitConfig config.CommitConfig, workers int) *KafkaConsumer {
+	readers := make([]Reader, len(topics))
 	for i, topic := range topics {
 		readers[i] = kafka.NewReader(kafka.ReaderConfig{
 			Brokers:  brokers,
+			Dialer:   dialer,
 			GroupID:  groupID,
 			Topic:    topic,
 			MinBytes: 1,
@@ -23,20 +48,158 @@ func NewMultiTopicConsumer(brokers []string, topics []string, groupID string) *K
 		})
 	}
 
-	return &KafkaConsumer{Readers: readers}
+	return &KafkaConsumer{Readers: readers, GroupID: groupID, CommitConfig: commitConfig, Workers: workers, Serializers: contracts.DefaultSerializers}
 }
 
-func (c *KafkaConsumer) Listen(ctx context.Context, handler func(topic string, value []byte)) {
+// Listen consumes every topic with at-least-once semantics: offsets are only
+// committed after the handler returns, in batches as configured by CommitConfig.
+// Each topic is handled by Workers goroutines with strict ordering per message key.
+// The handler's context carries the message's trace ID.
+func (c *KafkaConsumer) Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope)) {
 	for _, reader := range c.Readers {
-		go func(r *kafka.Reader) {
-			for {
-				msg, err := r.ReadMessage(ctx)
-				if err != nil {
-					log.Println("Kafka error:", err)
-					continue
-				}
-				handler(msg.Topic, msg.Value)
-			}
+		c.wg.Add(1)
+		go func(r Reader) {
+			defer c.wg.Done()
+			c.consume(ctx, r, handler)
 		}(reader)
 	}
 }
+
+// Topics returns the topics the consumer reads.
+func (c *KafkaConsumer) Topics() []string {
+	topics := make([]string, len(c.Readers))
+	for i, reader := range c.Readers {
+		topics[i] = reader.Config().Topic
+	}
+	return topics
+}
+
+// Close waits for the listeners to commit their last batch and closes the readers.
+// The context given to Listen must be cancelled first.
+func (c *KafkaConsumer) Close() error {
+	c.wg.Wait()
+
+	var errs []error
+	for _, reader := range c.Readers {
+		errs = append(errs, reader.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// consume fetches the messages of r and hands them to Workers goroutines,
+// routing by key so messages with the same key are handled in order.
+func (c *KafkaConsumer) consume(ctx context.Context, r Reader, handler func(ctx context.Context, topic string, envelope contracts.Envelope)) {
+	topic := r.Config().Topic
+	committer := newOffsetCommitter(r, c.CommitConfig)
+	go committer.FlushEvery(ctx, func(err error) {
+		logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to commit offsets")
+	})
+
+	queues := make([]chan kafka.Message, max(c.Workers, 1))
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, workerQueueSize)
+		workers.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer workers.Done()
+			c.work(ctx, queue, committer, handler)
+		}(queues[i])
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		workers.Wait()
+
+		// ctx is already cancelled here; commit what was handled before stopping.
+		if err := committer.Flush(context.Background()); err != nil {
+			logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to commit offsets on shutdown")
+		}
+	}()
+
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logrus.WithField(logging.FieldTopic, topic).WithError(err).Error("Failed to fetch message")
+			continue
+		}
+
+		committer.Track(msg)
+		select {
+		case queues[workerFor(msg, len(queues))] <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// work handles the messages of one worker queue in order until it is closed or
+// ctx is done. Cancelling ctx only stops taking new messages: the message in
+// flight is handled and its offset committed with a context that is not
+// cancelled, so a shutdown does not cut the handler short.
+func (c *KafkaConsumer) work(ctx context.Context, queue <-chan kafka.Message, committer *offsetCommitter, handler func(ctx context.Context, topic string, envelope contracts.Envelope)) {
+	for msg := range queue {
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.processMessage(context.WithoutCancel(ctx), msg, handler)
+
+		if err := committer.Done(context.WithoutCancel(ctx), msg); err != nil {
+			logrus.WithFields(logging.Message(msg.Topic, msg.Partition, msg.Offset)).WithError(err).Error("Failed to commit offsets")
+		}
+	}
+}
+
+// processMessage runs the handler once in a consumer span that continues the
+// trace of the producer of msg. Messages that break their event contract are
+// logged and skipped.
+func (c *KafkaConsumer) processMessage(ctx context.Context, msg kafka.Message, handler func(ctx context.Context, topic string, envelope contracts.Envelope)) {
+	ctx, span := tracing.StartConsumer(ctx, (*tracing.KafkaHeaders)(&msg.Headers), tracing.SystemKafka, msg.Topic,
+		semconv.MessagingConsumerGroupName(c.GroupID),
+		semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+		semconv.MessagingKafkaOffset(int(msg.Offset)),
+	)
+	defer span.End()
+
+	envelope, err := decode(c.Serializers, header(msg, contracts.HeaderContentType), msg.Value)
+	if err != nil {
+		logrus.WithFields(logging.Message(msg.Topic, msg.Partition, msg.Offset)).
+			WithField(logging.FieldTraceID, header(msg, contracts.HeaderTraceID)).
+			WithError(err).Warn("Skipping message that breaks its event contract")
+		return
+	}
+	traceID := contracts.MessageTraceID(header(msg, contracts.HeaderTraceID), envelope)
+	handler(logging.WithFields(contracts.WithTraceID(ctx, traceID), logging.Message(msg.Topic, msg.Partition, msg.Offset)), msg.Topic, envelope)
+}
+
+// decode reads value with the serializer of contentType and validates it
+// against its event contract.
+func decode(serializers contracts.Serializers, contentType string, value []byte) (contracts.Envelope, error) {
+	serializer, err := serializers.For(contentType)
+	if err != nil {
+		return contracts.Envelope{}, err
+	}
+	envelope, err := serializer.Unmarshal(value)
+	if err != nil {
+		return contracts.Envelope{}, err
+	}
+	if err := envelope.Validate(); err != nil {
+		return contracts.Envelope{}, err
+	}
+	return envelope, nil
+}
+
+// header returns the value of the header key of msg, or "".
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+// Package tracing sets up OpenTelemetry for the service and carries the
+// consumes the trace context of every payment from the message headers, so
+// the events of a payment join its distributed trace.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jeffleon2/draftea-metric-service/config"
+	"github.com/jeffleon2/draftea-metric-service/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/jeffleon2/draftea-metric-service"
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installs the global tracer provider of the configured exporter and
+// the W3C trace context propagator. The returned function flushes the
+// buffered spans and must be called on shutdown. With the none exporter the
+// context is still propagated, but no span is recorded.
+func Setup(ctx context.Context, cfg config.Tracing) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	name, err := cfg.TracesExporter()
+	if err != nil {
+		return nil, err
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch name {
+	case config.TracesExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case config.TracesExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s trace exporter: %w", name, err)
+	}
+
+	res, err := resource.New(ctx, resource.WithFromEnv(), resource.WithTelemetrySDK(), resource.WithAttributes(semconv.ServiceName(models.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,68 @@
+// Package metricstest runs metrics-service in process for whole-system tests:
+// its event handler consumes from a membroker.Broker instead of Kafka. The
+// configuration is read from the environment, with the service defaults.
+// The metrics are recorded but not registered with Prometheus.
+package metricstest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/caarlos0/env/v6"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-event-contracts/membroker"
+	"github.com/jeffleon2/draftea-metric-service/config"
+	"github.com/jeffleon2/draftea-metric-service/internal/handler"
+	"github.com/sirupsen/logrus"
+)
+
+// Service is a running metrics-service.
+type Service struct {
+	Consumer *membroker.Consumer
+
+	mu      sync.Mutex
+	handled map[string]int
+}
+
+// Start starts consuming the service's topics from broker until ctx is
+// cancelled.
+func Start(ctx context.Context, broker *membroker.Broker) (*Service, error) {
+	var cfg config.Config
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	s := &Service{handled: make(map[string]int)}
+	metricsHandler := handler.NewMetricHandler()
+
+	s.Consumer = broker.Consumer(cfg.Kafka.ConsumerGroup, strings.Split(cfg.Kafka.SubscriberTopics, ",")...)
+	s.Consumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		// Like the Kafka subscriber, failed events are logged and skipped.
+		if err := metricsHandler.HandleEvents(ctx, topic, envelope); err != nil {
+			logrus.Error(err.Error())
+			return nil
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.handled[envelope.Type]++
+		return nil
+	})
+
+	return s, nil
+}
+
+// Handled returns how many events of eventType were recorded.
+func (s *Service) Handled(eventType string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.handled[eventType]
+}
+
+// Close waits for the consumer to stop. The context given to Start must be
+// cancelled first.
+func (s *Service) Close() error {
+	return s.Consumer.Close()
+}
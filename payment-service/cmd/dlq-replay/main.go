@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/dlq"
+	"github.com/jeffleon2/draftea-payment-service/internal/logging"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	// TLS and SASL settings come from the same KAFKA_* variables as the services.
+	var kafkaConfig config.Kafka
+	if err := env.Parse(&kafkaConfig); err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	var loggingConfig config.Logging
+	if err := env.Parse(&loggingConfig); err != nil {
+		logrus.Fatalf("invalid logging configuration: %v", err)
+	}
+	if err := logging.Setup(loggingConfig); err != nil {
+		logrus.Fatalf("invalid logging configuration: %v", err)
+	}
+
+	brokers := flag.String("brokers", kafkaConfig.Brokers, "comma separated Kafka brokers (default KAFKA_BROKERS)")
+	dlqTopic := flag.String("dlq", models.PaymentsDLQTopic, "DLQ topic to read (payments.dlq or wallet.dlq)")
+	topic := flag.String("topic", "", "only replay messages from this original topic")
+	key := flag.String("key", "", "only replay messages with this key")
+	errorText := flag.String("error", "", "only replay messages whose error contains this text (case insensitive)")
+	from := flag.String("from", "", "only replay messages dead-lettered at or after this time (RFC 3339)")
+	to := flag.String("to", "", "only replay messages dead-lettered before this time (RFC 3339)")
+	checkpointPath := flag.String("checkpoint", "", "checkpoint file (default <dlq>.<filter hash>.checkpoint.json)")
+	dryRun := flag.Bool("dry-run", false, "list the matching messages without republishing them")
+	flag.Parse()
+
+	filter := dlq.Filter{OriginalTopic: *topic, Key: *key, ErrorContains: *errorText}
+	var err error
+	if filter.From, err = parseTime(*from); err != nil {
+		logrus.Fatalf("invalid -from: %v", err)
+	}
+	if filter.To, err = parseTime(*to); err != nil {
+		logrus.Fatalf("invalid -to: %v", err)
+	}
+
+	if *checkpointPath == "" {
+		*checkpointPath = dlq.DefaultCheckpointPath(*dlqTopic, filter)
+	}
+	checkpoint, err := dlq.LoadCheckpoint(*checkpointPath, *dlqTopic, filter)
+	if err != nil {
+		logrus.Fatalf("failed to load checkpoint: %v", err)
+	}
+
+	kafkaConfig.Brokers = *brokers
+	dialer, err := kafkaConfig.Dialer()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	transport, err := kafkaConfig.Transport()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := kafkaConfig.CheckConnection(ctx, dialer); err != nil {
+		logrus.Fatalf("failed to connect to Kafka: %v", err)
+	}
+
+	brokerList := kafkaConfig.BrokerList()
+	writer := &kafka.Writer{
+		Addr:      kafka.TCP(brokerList...),
+		Balancer:  &kafka.Hash{},
+		Transport: transport,
+	}
+	defer writer.Close()
+
+	replayer := dlq.NewReplayer(dlq.NewKafkaSource(brokerList, dialer, *dlqTopic), writer, filter, checkpoint, *dryRun)
+	stats, err := replayer.Replay(ctx)
+	logrus.Infof("Read %d, matched %d, replayed %d, invalid %d", stats.Read, stats.Matched, stats.Replayed, stats.Invalid)
+	if err != nil {
+		logrus.Fatalf("replay stopped: %v (checkpoint %s keeps the progress)", err, *checkpointPath)
+	}
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -1,20 +1,40 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/jeffleon2/draftea-payment-service/config"
 	"github.com/jeffleon2/draftea-payment-service/internal/app"
+	"github.com/jeffleon2/draftea-payment-service/internal/logging"
+	"github.com/jeffleon2/draftea-payment-service/internal/tracing"
+	"github.com/sirupsen/logrus"
 )
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.New()
 	if err != nil {
-		fmt.Println("Error reading config file", err)
-		os.Exit(1)
+		logrus.Fatalf("Error reading config file: %v", err)
+	}
+	if err := logging.Setup(cfg.Logging); err != nil {
+		logrus.Fatalf("Error setting up logging: %v", err)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logrus.Fatalf("Error setting up tracing: %v", err)
 	}
+
 	myApp := &app.App{}
 	myApp.Initialize(cfg)
-	myApp.Run()
+	err = myApp.Run(ctx)
+	shutdownTracing(context.Background())
+	if err != nil {
+		logrus.Fatalf("Payment service stopped with errors: %v", err)
+	}
+	logrus.Info("Payment service stopped")
 }
@@ -1,11 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/caarlos0/env/v6"
 	"github.com/joho/godotenv"
+	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 )
 
@@ -26,6 +28,11 @@ type Config struct {
 	APP
 	DB
 	Kafka
+	CircuitBreaker
+	Inbox
+	NATS
+	Tracing
+	Logging
 }
 
 type DB struct {
@@ -37,8 +44,31 @@ type DB struct {
 	SSLMODE  string `env:"DB_SSLMODE"`
 }
 
+// CircuitBreaker holds the thresholds of the breakers around Kafka and Postgres.
+// After MaxFailures consecutive failures the breaker opens and rejects calls
+// for OpenTimeout; then HalfOpenMaxRequests probes decide whether it closes.
+type CircuitBreaker struct {
+	MaxFailures         int           `env:"CIRCUIT_BREAKER_MAX_FAILURES" envDefault:"5"`
+	OpenTimeout         time.Duration `env:"CIRCUIT_BREAKER_OPEN_TIMEOUT" envDefault:"60s"`
+	HalfOpenMaxRequests int           `env:"CIRCUIT_BREAKER_HALF_OPEN_MAX_REQUESTS" envDefault:"1"`
+}
+
+// Inbox configures the processed-events table used to skip redelivered events.
+// Entries older than TTL are deleted every CleanupInterval.
+type Inbox struct {
+	TTL             time.Duration `env:"INBOX_TTL" envDefault:"168h"`
+	CleanupInterval time.Duration `env:"INBOX_CLEANUP_INTERVAL" envDefault:"1h"`
+}
+
 type APP struct {
 	PORT string `env:"APP_PORT" envDefault:"8080"`
+	// Transport is the message broker the events go through: kafka or nats.
+	Transport string `env:"MESSAGE_TRANSPORT" envDefault:"kafka"`
+	// HealthCheckTimeout bounds the dependency checks of /readyz.
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	// ShutdownTimeout bounds the graceful shutdown after SIGTERM; it must be
+	// shorter than the grace period of the orchestrator.
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"25s"`
 }
 
 type Kafka struct {
@@ -46,18 +76,52 @@ type Kafka struct {
 	PaymentConsumerGroup string `env:"KAFKA_PAYMENT_GROUP_ID" envDefault:"payment-service"`
 	PublishTopics        string `env:"KAFKA_PUBLISH_TOPICS" envDefault:"payments.created,wallet.debit.requested,payments.dlq"`
 	SubscriberTopics     string `env:"KAFKA_SUBSCRIBER_TOPICS" envDefault:"payments.checked,wallet.funds.verified"`
+	DLQTopics            string `env:"KAFKA_DLQ_TOPICS" envDefault:"payments.dlq,wallet.dlq"`
+	// DLQReadLimit is the number of newest messages per partition the DLQ API reads.
+	DLQReadLimit int `env:"KAFKA_DLQ_READ_LIMIT" envDefault:"1000"`
+
+	TLSEnabled            bool   `env:"KAFKA_TLS_ENABLED" envDefault:"false"`
+	TLSCAFile             string `env:"KAFKA_TLS_CA_FILE"`
+	TLSCertFile           string `env:"KAFKA_TLS_CERT_FILE"`
+	TLSKeyFile            string `env:"KAFKA_TLS_KEY_FILE"`
+	TLSInsecureSkipVerify bool   `env:"KAFKA_TLS_INSECURE_SKIP_VERIFY" envDefault:"false"`
+	SASLMechanism         string `env:"KAFKA_SASL_MECHANISM"`
+	SASLUsername          string `env:"KAFKA_SASL_USERNAME"`
+	SASLPassword          string `env:"KAFKA_SASL_PASSWORD"`
+
+	RetryMaxAttempts int             `env:"KAFKA_RETRY_MAX_ATTEMPTS" envDefault:"5"`
+	RetryBaseDelay   time.Duration   `env:"KAFKA_RETRY_BASE_DELAY" envDefault:"100ms"`
+	RetryMaxDelay    time.Duration   `env:"KAFKA_RETRY_MAX_DELAY" envDefault:"10s"`
+	RetryJitter      bool            `env:"KAFKA_RETRY_JITTER" envDefault:"true"`
+	RetryDelays      []time.Duration `env:"KAFKA_RETRY_DELAYS" envDefault:"1s,30s,5m" envSeparator:","`
+	RetryTopicPrefix string          `env:"KAFKA_RETRY_TOPIC_PREFIX" envDefault:"payments.retry"`
 
-	RetryMaxAttempts int           `env:"KAFKA_RETRY_MAX_ATTEMPTS" envDefault:"5"`
-	RetryBaseDelay   time.Duration `env:"KAFKA_RETRY_BASE_DELAY" envDefault:"100ms"`
-	RetryMaxDelay    time.Duration `env:"KAFKA_RETRY_MAX_DELAY" envDefault:"10s"`
-	RetryJitter      bool          `env:"KAFKA_RETRY_JITTER" envDefault:"true"`
+	PublishBatchSize    int                `env:"KAFKA_PUBLISH_BATCH_SIZE" envDefault:"100"`
+	PublishBatchTimeout time.Duration      `env:"KAFKA_PUBLISH_BATCH_TIMEOUT" envDefault:"10ms"`
+	PublishCompression  string             `env:"KAFKA_PUBLISH_COMPRESSION" envDefault:"none"`
+	PublishRequiredAcks kafka.RequiredAcks `env:"KAFKA_PUBLISH_REQUIRED_ACKS" envDefault:"all"`
+	PublishAsync        bool               `env:"KAFKA_PUBLISH_ASYNC" envDefault:"false"`
+	PublishFormat       string             `env:"KAFKA_PUBLISH_FORMAT" envDefault:"json"`
+
+	CommitBatchSize int           `env:"KAFKA_COMMIT_BATCH_SIZE" envDefault:"100"`
+	CommitInterval  time.Duration `env:"KAFKA_COMMIT_INTERVAL" envDefault:"1s"`
+
+	// ConsumerWorkers is the number of goroutines handling each topic.
+	// Messages with the same key are always handled by the same one, in order.
+	ConsumerWorkers int `env:"KAFKA_CONSUMER_WORKERS" envDefault:"8"`
 }
 
+// RetryConfig configures publish retries and the consumer retry tiers.
+// A failed message is moved to one retry topic per entry in Delays,
+// e.g. payments.retry.1s, payments.retry.30s and payments.retry.5m,
+// before it goes to the DLQ.
 type RetryConfig struct {
 	MaxAttempts int
 	BaseDelay   time.Duration
 	MaxDelay    time.Duration
 	Jitter      bool
+	Delays      []time.Duration
+	TopicPrefix string
 }
 
 func (k Kafka) GetRetryConfig() RetryConfig {
@@ -66,5 +130,47 @@ func (k Kafka) GetRetryConfig() RetryConfig {
 		BaseDelay:   k.RetryBaseDelay,
 		MaxDelay:    k.RetryMaxDelay,
 		Jitter:      k.RetryJitter,
+		Delays:      k.RetryDelays,
+		TopicPrefix: k.RetryTopicPrefix,
+	}
+}
+
+// RetryTopic returns the topic of the given retry tier, starting at 0.
+func (r RetryConfig) RetryTopic(tier int) string {
+	return fmt.Sprintf("%s.%s", r.TopicPrefix, formatDelay(r.Delays[tier]))
+}
+
+// RetryTopics returns the topics of every retry tier in order.
+func (r RetryConfig) RetryTopics() []string {
+	topics := make([]string, len(r.Delays))
+	for i := range r.Delays {
+		topics[i] = r.RetryTopic(i)
+	}
+	return topics
+}
+
+// formatDelay renders a delay in its largest whole unit: 1s, 30s, 5m, 2h.
+func formatDelay(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	default:
+		return fmt.Sprintf("%dms", d/time.Millisecond)
+	}
+}
+
+type CommitConfig struct {
+	BatchSize int
+	Interval  time.Duration
+}
+
+func (k Kafka) GetCommitConfig() CommitConfig {
+	return CommitConfig{
+		BatchSize: k.CommitBatchSize,
+		Interval:  k.CommitInterval,
 	}
 }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Supported values of MESSAGE_TRANSPORT.
+const (
+	TransportKafka = "kafka"
+	TransportNATS  = "nats"
+)
+
+// NATS configures the JetStream transport used when MESSAGE_TRANSPORT=nats.
+// Topics keep their Kafka names and are used as subjects, each one stored in
+// its own stream; consumer groups become durable consumers.
+type NATS struct {
+	URL      string        `env:"NATS_URL" envDefault:"nats://localhost:4222"`
+	Replicas int           `env:"NATS_STREAM_REPLICAS" envDefault:"1"`
+	MaxAge   time.Duration `env:"NATS_STREAM_MAX_AGE" envDefault:"168h"`
+	// AckWait is how long a message may be handled before JetStream delivers
+	// it again.
+	AckWait       time.Duration `env:"NATS_ACK_WAIT" envDefault:"30s"`
+	MaxAckPending int           `env:"NATS_MAX_ACK_PENDING" envDefault:"1000"`
+}
+
+// MessageTransport returns the configured MESSAGE_TRANSPORT.
+func (a APP) MessageTransport() (string, error) {
+	switch transport := strings.ToLower(strings.TrimSpace(a.Transport)); transport {
+	case "", TransportKafka:
+		return TransportKafka, nil
+	case TransportNATS:
+		return TransportNATS, nil
+	default:
+		return "", fmt.Errorf("unsupported MESSAGE_TRANSPORT %q (use %s or %s)", a.Transport, TransportKafka, TransportNATS)
+	}
+}
+
+// Connect connects to NATS and returns its JetStream context.
+func (n NATS) Connect() (*nats.Conn, jetstream.JetStream, error) {
+	nc, err := nats.Connect(n.URL, nats.Name("payment-service"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, nil, fmt.Errorf("error opening JetStream: %w", err)
+	}
+	return nc, js, nil
+}
+
+// EnsureStreams creates the stream of every topic, or updates it to the
+// configured settings.
+func (n NATS) EnsureStreams(ctx context.Context, js jetstream.JetStream, topics []string) error {
+	for _, topic := range topics {
+		_, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:     StreamName(topic),
+			Subjects: []string{topic},
+			Storage:  jetstream.FileStorage,
+			Replicas: n.Replicas,
+			MaxAge:   n.MaxAge,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating stream for topic %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// StreamName returns the name of the stream holding topic, e.g.
+// PAYMENTS_CREATED for payments.created.
+func StreamName(topic string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "*", "_", ">", "_").Replace(topic))
+}
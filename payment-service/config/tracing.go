@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported values of OTEL_TRACES_EXPORTER.
+const (
+	TracesExporterNone   = "none"
+	TracesExporterOTLP   = "otlp"
+	TracesExporterStdout = "stdout"
+)
+
+// Tracing configures the OpenTelemetry traces of the service. The otlp
+// exporter sends spans over OTLP/HTTP to OTLPEndpoint, stdout prints them
+// and none disables tracing. SampleRatio is the fraction of new traces that
+// are recorded; traces started by other services keep their decision.
+type Tracing struct {
+	Exporter     string  `env:"OTEL_TRACES_EXPORTER" envDefault:"none"`
+	OTLPEndpoint string  `env:"OTEL_EXPORTER_OTLP_ENDPOINT" envDefault:"http://localhost:4318"`
+	SampleRatio  float64 `env:"OTEL_TRACES_SAMPLER_RATIO" envDefault:"1"`
+}
+
+// TracesExporter returns the configured OTEL_TRACES_EXPORTER.
+func (t Tracing) TracesExporter() (string, error) {
+	switch exporter := strings.ToLower(strings.TrimSpace(t.Exporter)); exporter {
+	case "", TracesExporterNone:
+		return TracesExporterNone, nil
+	case TracesExporterOTLP, TracesExporterStdout:
+		return exporter, nil
+	default:
+		return "", fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q (use %s, %s or %s)", t.Exporter, TracesExporterOTLP, TracesExporterStdout, TracesExporterNone)
+	}
+}
@@ -2,73 +2,286 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
+	"github.com/jeffleon2/draftea-payment-service/internal/dlq"
 	handlers "github.com/jeffleon2/draftea-payment-service/internal/handlers"
+	"github.com/jeffleon2/draftea-payment-service/internal/health"
+	"github.com/jeffleon2/draftea-payment-service/internal/logging"
+	"github.com/jeffleon2/draftea-payment-service/internal/metrics"
 	"github.com/jeffleon2/draftea-payment-service/internal/models"
 	"github.com/jeffleon2/draftea-payment-service/internal/publisher"
 	"github.com/jeffleon2/draftea-payment-service/internal/repository/posgrest"
 	"github.com/jeffleon2/draftea-payment-service/internal/service"
 	"github.com/jeffleon2/draftea-payment-service/internal/subscriber"
+	"github.com/jeffleon2/draftea-payment-service/internal/tracing"
+	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"gorm.io/gorm"
 )
 
 type App struct {
 	config *config.Config
 	Router *gin.Engine
+	Health *health.Checker
+
+	server    *http.Server
+	consumer  eventConsumer
+	publisher eventPublisher
+	db        *gorm.DB
+	// stop cancels the context of the consumers and the background jobs.
+	stop context.CancelFunc
 }
 
 func (a *App) Initialize(cfg *config.Config) {
 	a.config = cfg
+	a.Health = health.NewChecker(cfg.APP.HealthCheckTimeout)
+	ctx, stop := context.WithCancel(context.Background())
+	a.stop = stop
+
 	db, err := cfg.DB.GormConnect()
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		logrus.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		logrus.Fatalf("failed to instrument database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Payment{}); err != nil {
-		log.Fatalf("failed to auto migrate: %v", err)
+	if err := db.AutoMigrate(&models.Payment{}, &models.ProcessedEvent{}); err != nil {
+		logrus.Fatalf("failed to auto migrate: %v", err)
 	}
+	a.Health.Critical("postgres", health.Database(db))
+	a.db = db
 
-	paymentRepo := posgrest.New[models.Payment](db)
-	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
-	publisher := publisher.NewKafkaPublisher(cfg.Kafka.Brokers, publishTopics, a.config.GetRetryConfig())
-	paymentService := service.NewPaymentService(paymentRepo, publisher)
+	transport, err := cfg.APP.MessageTransport()
+	if err != nil {
+		logrus.Fatalf("invalid configuration: %v", err)
+	}
+
+	metrics.RegisterMetrics()
+	dbBreaker := breaker.New("postgres", cfg.CircuitBreaker)
+	dbBreaker.IsFailure = posgrest.IsFailure
+	paymentRepo := posgrest.New[models.Payment](db, dbBreaker)
+	publisherConfig, err := cfg.Kafka.GetPublisherConfig()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+
+	var dlqHandler *handlers.DLQHandler
+	var kafkaPublisher *publisher.KafkaPublisher
+	switch transport {
+	case config.TransportNATS:
+		a.publisher, a.consumer = a.initNATS(publisherConfig)
+	default:
+		var dlqService *service.DLQService
+		kafkaPublisher, a.consumer, dlqService = a.initKafka(publisherConfig)
+		a.publisher = kafkaPublisher
+		dlqHandler = handlers.NewDLQHandler(dlqService)
+	}
+
+	inbox := posgrest.NewInbox(db, dbBreaker)
+	go inbox.CleanupEvery(ctx, cfg.Inbox.TTL, cfg.Inbox.CleanupInterval)
+	paymentService := service.NewPaymentService(paymentRepo, a.publisher, inbox)
+	if kafkaPublisher != nil {
+		kafkaPublisher.OnDelivery = failUndelivered(kafkaPublisher, paymentService)
+	}
 	paymentHandler := handlers.NewPaymentHandler(paymentService)
 
-	a.Router = gin.Default()
-	a.Router.Use(gin.Recovery())
-	a.RegisterRoutes(paymentHandler)
+	a.Router = gin.New()
+	a.Router.Use(gin.Recovery(), otelgin.Middleware(models.ServiceName, otelgin.WithGinFilter(observedRoute)), logging.Requests(observedRoute))
+	a.RegisterRoutes(paymentHandler, dlqHandler)
+	a.server = &http.Server{Addr: fmt.Sprintf(":%s", cfg.APP.PORT), Handler: a.Router}
 
-	a.initSubscribers(paymentHandler, publisher, a.config.GetRetryConfig())
+	a.initSubscribers(ctx, paymentHandler)
+	a.Health.Ready()
 }
 
-func (a *App) Run() {
-	err := a.Router.Run(fmt.Sprintf(":%s", a.config.APP.PORT))
-	if err != nil {
-		panic(err)
+// Run serves HTTP until ctx is done or the server fails, and then shuts the
+// service down within APP.ShutdownTimeout.
+func (a *App) Run(ctx context.Context) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- a.server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		logrus.Info("Shutting down")
+	case serveErr := <-served:
+		err = fmt.Errorf("serving http: %w", serveErr)
 	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.APP.ShutdownTimeout)
+	defer cancel()
+	return errors.Join(err, a.Shutdown(shutdownCtx))
 }
 
-func (a *App) initSubscribers(paymentHandler *handlers.PaymentHandler, publisher *publisher.KafkaPublisher, config config.RetryConfig) {
-	brokers := strings.Split(a.config.Kafka.Brokers, ",")
-	topics := strings.Split(a.config.Kafka.SubscriberTopics, ",")
-	groupID := a.config.Kafka.PaymentConsumerGroup
+// Shutdown stops the service without losing work in flight. It takes the
+// service out of readiness, stops accepting HTTP requests and waits for the
+// ones in flight, stops fetching messages and waits for the handlers in
+// flight and the commit of their offsets, flushes the publisher and closes
+// the database pool. Steps not done when ctx is done are abandoned.
+func (a *App) Shutdown(ctx context.Context) error {
+	a.Health.Stopping()
 
-	consumer := subscriber.NewMultiTopicConsumer(brokers, topics, groupID, publisher, config)
+	var errs []error
+	if err := a.server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("stopping http server: %w", err))
+	}
 
-	ctx, _ := context.WithCancel(context.Background())
-	go consumer.Listen(ctx, func(topic string, value []byte) error {
-		log.Printf("📩 Received message → topic=%s value=%s\n", topic, string(value))
-		ctx := context.Background()
-		err := paymentHandler.HandleEvents(ctx, topic, value)
-		if err != nil {
-			logrus.Error(err.Error())
+	a.stop()
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"draining consumer", a.consumer.Close},
+		{"flushing publisher", a.publisher.Close},
+		{"closing database", a.closeDB},
+	}
+	for _, step := range steps {
+		if err := within(ctx, step.run); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
 		}
-		return nil
+	}
+	return errors.Join(errs...)
+}
+
+func (a *App) closeDB() error {
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// within runs fn unless ctx is already done and waits for it until ctx is done.
+func within(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// eventConsumer is the multi-topic consumer of the configured transport.
+type eventConsumer interface {
+	Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error)
+	Close() error
+}
+
+// eventPublisher is the publisher of the configured transport. Close flushes
+// what it has not delivered yet.
+type eventPublisher interface {
+	service.Publisher
+	Close() error
+}
+
+// initKafka connects to Kafka and returns its publisher, the consumer of the
+// subscribed topics and the DLQ browser.
+func (a *App) initKafka(publisherConfig config.PublisherConfig) (*publisher.KafkaPublisher, *subscriber.KafkaConsumer, *service.DLQService) {
+	cfg := a.config
+	dialer, err := cfg.Kafka.Dialer()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	transport, err := cfg.Kafka.Transport()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	if err := cfg.Kafka.CheckConnection(context.Background(), dialer); err != nil {
+		logrus.Fatalf("failed to connect to Kafka: %v", err)
+	}
+
+	brokers := cfg.Kafka.BrokerList()
+	retryConfig := cfg.Kafka.GetRetryConfig()
+	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
+	publishTopics = append(publishTopics, retryConfig.RetryTopics()...)
+	publisherConfig.SyncTopics = append(retryConfig.RetryTopics(), models.PaymentsDLQTopic, models.WalletDebitEventTopic)
+	publisher := publisher.NewKafkaPublisher(brokers, transport, publishTopics, retryConfig, publisherConfig, breaker.New("kafka", cfg.CircuitBreaker))
+
+	topics := strings.Split(cfg.Kafka.SubscriberTopics, ",")
+	consumer := subscriber.NewMultiTopicConsumer(brokers, dialer, topics, cfg.Kafka.PaymentConsumerGroup, publisher, retryConfig, cfg.Kafka.GetCommitConfig(), cfg.Kafka.ConsumerWorkers)
+
+	a.Health.Critical("kafka", func(ctx context.Context) error {
+		return cfg.Kafka.CheckConnection(ctx, dialer)
+	})
+	a.Health.Optional("kafka_consumer_group", func(ctx context.Context) error {
+		return cfg.Kafka.CheckConsumerGroup(ctx, transport, cfg.Kafka.PaymentConsumerGroup, consumer.Topics())
 	})
 
+	dlqService := service.NewDLQService(dlq.NewBrowser(brokers, dialer), strings.Split(cfg.Kafka.DLQTopics, ","), cfg.Kafka.DLQReadLimit)
+	return publisher, consumer, dlqService
+}
+
+// failUndelivered is the delivery callback of the Kafka publisher. Async
+// writes report their errors only here, so it logs them, and it fails the
+// payments whose payments.created event was lost: nothing would ever check
+// them otherwise (see PaymentService.FailUndelivered).
+func failUndelivered(p *publisher.KafkaPublisher, payments *service.PaymentService) publisher.DeliveryCallback {
+	return func(topic string, messages []kafka.Message, err error) {
+		if err == nil || !p.Writers[topic].Async {
+			return
+		}
+		logrus.WithField(logging.FieldTopic, topic).WithError(err).Errorf("Async delivery of %d messages failed", len(messages))
+		if topic != models.PaymentCreatedEventTopic {
+			return
+		}
+		for _, msg := range messages {
+			if failErr := payments.FailUndelivered(context.Background(), string(msg.Key), err); failErr != nil {
+				logrus.WithField(logging.FieldPaymentID, string(msg.Key)).WithError(failErr).Error("Failed to mark undelivered payment as failed")
+			}
+		}
+	}
+}
+
+// initNATS connects to NATS, creates the streams of every topic the service
+// publishes or consumes and returns the JetStream publisher and consumer.
+// Topics, consumer group and retry delays are the KAFKA_* settings.
+func (a *App) initNATS(publisherConfig config.PublisherConfig) (*publisher.NATSPublisher, *subscriber.NATSConsumer) {
+	cfg := a.config
+	conn, js, err := cfg.NATS.Connect()
+	if err != nil {
+		logrus.Fatalf("failed to connect to NATS: %v", err)
+	}
+	a.Health.Critical("nats", health.NATS(conn))
+
+	ctx := context.Background()
+	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
+	topics := strings.Split(cfg.Kafka.SubscriberTopics, ",")
+	if err := cfg.NATS.EnsureStreams(ctx, js, append(publishTopics, topics...)); err != nil {
+		logrus.Fatalf("failed to create NATS streams: %v", err)
+	}
+
+	retryConfig := cfg.Kafka.GetRetryConfig()
+	publisher := publisher.NewNATSPublisher(js, retryConfig, publisherConfig, breaker.New("nats", cfg.CircuitBreaker))
+	consumer, err := subscriber.NewNATSConsumer(ctx, js, topics, cfg.Kafka.PaymentConsumerGroup, publisher, retryConfig, cfg.NATS, cfg.Kafka.ConsumerWorkers)
+	if err != nil {
+		logrus.Fatalf("failed to create NATS consumers: %v", err)
+	}
+	return publisher, consumer
+}
+
+// initSubscribers starts consuming until ctx is cancelled.
+func (a *App) initSubscribers(ctx context.Context, paymentHandler *handlers.PaymentHandler) {
+	a.consumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		logging.FromContext(ctx).Infof("Received %s event %s", envelope.Type, envelope.ID)
+		return paymentHandler.HandleEvents(ctx, topic, envelope)
+	})
 }
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
+	"github.com/jeffleon2/draftea-payment-service/internal/health"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/jeffleon2/draftea-payment-service/internal/publisher"
+	"github.com/jeffleon2/draftea-payment-service/internal/service"
+	"github.com/jeffleon2/draftea-payment-service/internal/service/mocks"
+	"github.com/jeffleon2/draftea-payment-service/internal/subscriber"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// steps records the shutdown steps in the order they happen.
+type steps struct {
+	mu   sync.Mutex
+	done []string
+}
+
+func (s *steps) add(step string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = append(s.done, step)
+}
+
+func (s *steps) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.done...)
+}
+
+type fakeConsumer struct {
+	steps   *steps
+	ctx     context.Context
+	release chan struct{}
+}
+
+func (c *fakeConsumer) Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	c.ctx = ctx
+}
+
+// Close stands for the handlers in flight: it returns once release is closed.
+func (c *fakeConsumer) Close() error {
+	if c.ctx.Err() == nil {
+		c.steps.add("consumer closed while fetching")
+	}
+	<-c.release
+	c.steps.add("consumer")
+	return nil
+}
+
+type fakePublisher struct {
+	steps *steps
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, topic string, message interface{}) error {
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.steps.add("publisher")
+	return nil
+}
+
+// newTestApp returns an App whose consumer and publisher record in steps and
+// whose HTTP server serves a request that lasts requestTime.
+func newTestApp(t *testing.T, s *steps, requestTime time.Duration) (*App, *fakeConsumer, *httptest.Server) {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	ctx, stop := context.WithCancel(context.Background())
+	consumer := &fakeConsumer{steps: s, release: make(chan struct{})}
+	consumer.Listen(ctx, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(requestTime)
+		s.add("request")
+	}))
+	t.Cleanup(server.Close)
+
+	a := &App{
+		Health:    health.NewChecker(time.Second),
+		server:    server.Config,
+		consumer:  consumer,
+		publisher: &fakePublisher{steps: s},
+		db:        db,
+		stop:      stop,
+	}
+	a.Health.Ready()
+	return a, consumer, server
+}
+
+func TestShutdownDrainsInOrder(t *testing.T) {
+	s := &steps{}
+	a, consumer, server := newTestApp(t, s, 50*time.Millisecond)
+
+	responded := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		responded <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(consumer.release)
+
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.NoError(t, <-responded, "the request in flight must be served")
+
+	assert.Equal(t, []string{"request", "consumer", "publisher"}, s.list())
+	sqlDB, err := a.db.DB()
+	require.NoError(t, err)
+	assert.ErrorContains(t, sqlDB.Ping(), "database is closed")
+
+	rec := httptest.NewRecorder()
+	a.Health.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestShutdownGivesUpAtTheTimeout(t *testing.T) {
+	s := &steps{}
+	a, consumer, _ := newTestApp(t, s, 0)
+	t.Cleanup(func() { close(consumer.release) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := a.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "draining consumer")
+	assert.NotContains(t, s.list(), "publisher", "nothing is flushed while handlers may still publish")
+}
+
+// fakeReader serves the messages sent to it and records the committed offsets.
+type fakeReader struct {
+	messages chan kafka.Message
+
+	mu        sync.Mutex
+	committed []int64
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case msg := <-r.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Config() kafka.ReaderConfig {
+	return kafka.ReaderConfig{Topic: models.FraudTopic2Subscribe}
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+func (r *fakeReader) commits() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+func TestShutdownLetsHandlersInFlightFinish(t *testing.T) {
+	s := &steps{}
+	a, _, _ := newTestApp(t, s, 0)
+
+	reader := &fakeReader{messages: make(chan kafka.Message, 1)}
+	consumer := &subscriber.KafkaConsumer{
+		Readers:      []subscriber.Reader{reader},
+		GroupID:      "payment-service",
+		CommitConfig: config.CommitConfig{BatchSize: 100, Interval: time.Hour},
+		Workers:      1,
+		Serializers:  contracts.DefaultSerializers,
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	a.consumer, a.stop = consumer, stop
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handled := make(chan error, 1)
+	consumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		close(started)
+		<-release
+		// Stands for the database writes and publishes of the handler, which
+		// fail once their context is cancelled.
+		handled <- ctx.Err()
+		return ctx.Err()
+	})
+
+	envelope, err := contracts.New(models.ServiceName, models.FraudCheckEvent{ID: "pay_1", TraceID: "trace-pay_1", Status: models.PaymentStatusApproved, CheckedAt: time.Now()})
+	require.NoError(t, err)
+	value, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	reader.messages <- kafka.Message{Topic: models.FraudTopic2Subscribe, Key: []byte("pay_1"), Offset: 0, Value: value}
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- a.Shutdown(context.Background()) }()
+	<-ctx.Done()
+	close(release)
+
+	require.NoError(t, <-shutdown)
+	assert.NoError(t, <-handled, "the handler in flight keeps a live context")
+	assert.Equal(t, []int64{0}, reader.commits(), "its offset is committed before the consumer closes")
+}
+
+func TestFailUndeliveredFailsPaymentsWhoseEventWasLost(t *testing.T) {
+	kafkaPublisher := publisher.NewKafkaPublisher([]string{"localhost:9092"}, nil,
+		[]string{models.PaymentCreatedEventTopic, models.WalletDebitEventTopic},
+		config.RetryConfig{}, config.PublisherConfig{Async: true, SyncTopics: []string{models.WalletDebitEventTopic}},
+		breaker.New("kafka-test", config.CircuitBreaker{}))
+	t.Cleanup(func() { kafkaPublisher.Close() })
+	repo := mocks.NewMockPaymentRepo(t)
+	onDelivery := failUndelivered(kafkaPublisher, service.NewPaymentService(repo, mocks.NewMockPublisher(t), mocks.NewMockInbox(t)))
+
+	repo.EXPECT().
+		GetByID(mock.Anything, "pay_1").
+		Return(&models.Payment{ID: "pay_1", Status: models.StatusPending}, nil).
+		Once()
+	repo.EXPECT().
+		Update(mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.StatusFailed && p.FailedReason == "payments.created could not be delivered: broker unavailable"
+		}), "pay_1").
+		Return(nil).
+		Once()
+
+	unavailable := errors.New("broker unavailable")
+	onDelivery(models.PaymentCreatedEventTopic, []kafka.Message{{Key: []byte("pay_1")}}, unavailable)
+	onDelivery(models.PaymentCreatedEventTopic, []kafka.Message{{Key: []byte("pay_2")}}, nil)
+	// Sync writes already return their errors to the caller.
+	onDelivery(models.WalletDebitEventTopic, []kafka.Message{{Key: []byte("pay_3")}}, unavailable)
+}
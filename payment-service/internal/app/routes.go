@@ -1,8 +1,35 @@
 package app
 
-import handlers "github.com/jeffleon2/draftea-payment-service/internal/handlers"
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/jeffleon2/draftea-payment-service/internal/handlers"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
 
-func (a *App) RegisterRoutes(h *handlers.PaymentHandler) {
-	app := a.Router.Group("/payments")
+func (a *App) RegisterRoutes(h *handlers.PaymentHandler, dlqHandler *handlers.DLQHandler) {
+	a.Router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	a.Router.GET("/healthz", gin.WrapF(a.Health.Liveness))
+	a.Router.GET("/readyz", gin.WrapF(a.Health.Readiness))
+
+	app := a.Router.Group("/payments", handlers.TraceID())
 	app.POST("", h.CreatePayment)
+	app.POST("/:id/review", h.ResolveReview)
+
+	// The DLQ browser reads Kafka topics; it is not served with NATS.
+	if dlqHandler == nil {
+		return
+	}
+	dlq := a.Router.Group("/dlq/:topic")
+	dlq.GET("/messages", dlqHandler.ListMessages)
+	dlq.GET("/summary", dlqHandler.Summary)
+}
+
+// observedRoute leaves the Prometheus scrapes and the probes out of the
+// traces and the request logs.
+func observedRoute(c *gin.Context) bool {
+	switch c.FullPath() {
+	case "/metrics", "/healthz", "/readyz":
+		return false
+	}
+	return true
 }
@@ -0,0 +1,57 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// Browser reads DLQ topics for the inspection API.
+type Browser struct {
+	Brokers []string
+	Dialer  *kafka.Dialer
+}
+
+func NewBrowser(brokers []string, dialer *kafka.Dialer) *Browser {
+	return &Browser{Brokers: brokers, Dialer: dialer}
+}
+
+// ReadRecent returns the newest messages stored in topic, at most limit per
+// partition, so a request never reads a whole topic. truncated reports that
+// older messages were left out.
+func (b *Browser) ReadRecent(ctx context.Context, topic string, limit int) (entries []models.DLQEntry, truncated bool, err error) {
+	source := NewKafkaSource(b.Brokers, b.Dialer, topic)
+
+	partitions, err := source.Partitions(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, partition := range partitions {
+		first, end, err := source.Offsets(ctx, partition)
+		if err != nil {
+			return nil, false, err
+		}
+		start := max(first, end-int64(limit))
+		if start > first {
+			truncated = true
+		}
+
+		err = source.ReadPartition(ctx, partition, start, func(msg kafka.Message) error {
+			entry := models.DLQEntry{Partition: msg.Partition, Offset: msg.Offset}
+			if err := json.Unmarshal(msg.Value, &entry.Message); err != nil || entry.Message.OriginalTopic == "" {
+				entry.Message = models.DLQMessage{}
+				entry.Raw = string(msg.Value)
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	return entries, truncated, nil
+}
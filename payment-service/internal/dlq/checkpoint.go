@@ -0,0 +1,109 @@
+package dlq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint records, per partition, the next DLQ offset to read so an
+// interrupted replay resumes where it stopped instead of republishing
+// messages twice. An empty path keeps the checkpoint in memory only.
+//
+// The replay advances past the messages its filter rejects, so a checkpoint
+// is only valid for the filter that wrote it: reusing it with another filter
+// would skip messages that filter never looked at.
+type Checkpoint struct {
+	DLQTopic string        `json:"dlq_topic"`
+	Filter   string        `json:"filter"`
+	Offsets  map[int]int64 `json:"offsets"`
+
+	path string
+}
+
+// DefaultCheckpointPath returns the checkpoint file of a replay of dlqTopic
+// with filter, so replays with different filters never share progress.
+func DefaultCheckpointPath(dlqTopic string, filter Filter) string {
+	return fmt.Sprintf("%s.%s.checkpoint.json", dlqTopic, filter.Hash())
+}
+
+// LoadCheckpoint reads the checkpoint at path, or starts a new one for
+// dlqTopic and filter when the file does not exist yet. It fails if the file
+// was written for another topic or filter.
+func LoadCheckpoint(path, dlqTopic string, filter Filter) (*Checkpoint, error) {
+	cp := &Checkpoint{DLQTopic: dlqTopic, Filter: filter.String(), Offsets: map[int]int64{}, path: path}
+	if path == "" {
+		return cp, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("error decoding checkpoint %s: %w", path, err)
+	}
+	if cp.DLQTopic != dlqTopic {
+		return nil, fmt.Errorf("checkpoint %s belongs to topic %s, not %s", path, cp.DLQTopic, dlqTopic)
+	}
+	if cp.Filter != filter.String() {
+		return nil, fmt.Errorf("checkpoint %s was written with filter %s, not %s; use another checkpoint file", path, cp.Filter, filter)
+	}
+	if cp.Offsets == nil {
+		cp.Offsets = map[int]int64{}
+	}
+
+	return cp, nil
+}
+
+// Offset returns the next offset to read from partition, or -1 when the
+// partition has not been read yet.
+func (c *Checkpoint) Offset(partition int) int64 {
+	offset, ok := c.Offsets[partition]
+	if !ok {
+		return -1
+	}
+	return offset
+}
+
+// Advance marks every offset of partition up to and including offset as done.
+func (c *Checkpoint) Advance(partition int, offset int64) {
+	c.Offsets[partition] = offset + 1
+}
+
+// Save writes the checkpoint atomically so a crash never leaves a partial file.
+func (c *Checkpoint) Save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package dlq_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/internal/dlq"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	partitions map[int][]kafka.Message
+}
+
+func (s *fakeSource) Partitions(ctx context.Context) ([]int, error) {
+	return []int{0, 1}, nil
+}
+
+func (s *fakeSource) ReadPartition(ctx context.Context, partition int, offset int64, fn func(kafka.Message) error) error {
+	for _, msg := range s.partitions[partition] {
+		if msg.Offset < offset {
+			continue
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeWriter struct {
+	messages []kafka.Message
+	failOn   string
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		if string(msg.Key) == w.failOn {
+			return errors.New("broker unavailable")
+		}
+		w.messages = append(w.messages, msg)
+	}
+	return nil
+}
+
+var deadLetteredAt = time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func dlqMessage(t *testing.T, partition int, offset int64, topic, key, errText string) kafka.Message {
+	value, err := json.Marshal(models.DLQMessage{
+		OriginalTopic: topic,
+		Key:           key,
+		Value:         `{"payment_id":"` + key + `"}`,
+		Timestamp:     deadLetteredAt.Add(time.Duration(offset) * time.Hour),
+		Attempts:      4,
+		Error:         errText,
+	})
+	require.NoError(t, err)
+	return kafka.Message{Topic: models.PaymentsDLQTopic, Partition: partition, Offset: offset, Value: value}
+}
+
+func newSource(t *testing.T) *fakeSource {
+	return &fakeSource{partitions: map[int][]kafka.Message{
+		0: {
+			dlqMessage(t, 0, 0, "payments.checked", "payment-1", "database connection timeout"),
+			{Topic: models.PaymentsDLQTopic, Partition: 0, Offset: 1, Value: []byte("not json")},
+			dlqMessage(t, 0, 2, "wallet.funds.verified", "payment-2", "database connection timeout"),
+		},
+		1: {
+			dlqMessage(t, 1, 0, "payments.checked", "payment-3", "payment not found"),
+			dlqMessage(t, 1, 1, "payments.checked", "payment-4", "Database connection refused"),
+		},
+	}}
+}
+
+func TestFilter_Match(t *testing.T) {
+	msg := models.DLQMessage{
+		OriginalTopic: "payments.checked",
+		Key:           "payment-1",
+		Error:         "Database connection timeout",
+		Timestamp:     deadLetteredAt,
+	}
+
+	tests := []struct {
+		name   string
+		filter dlq.Filter
+		match  bool
+	}{
+		{name: "empty filter", filter: dlq.Filter{}, match: true},
+		{name: "topic", filter: dlq.Filter{OriginalTopic: "payments.checked"}, match: true},
+		{name: "other topic", filter: dlq.Filter{OriginalTopic: "wallet.funds.verified"}},
+		{name: "key", filter: dlq.Filter{Key: "payment-1"}, match: true},
+		{name: "other key", filter: dlq.Filter{Key: "payment-2"}},
+		{name: "error text ignores case", filter: dlq.Filter{ErrorContains: "connection TIMEOUT"}, match: true},
+		{name: "other error", filter: dlq.Filter{ErrorContains: "not found"}},
+		{name: "from is inclusive", filter: dlq.Filter{From: deadLetteredAt}, match: true},
+		{name: "to is exclusive", filter: dlq.Filter{To: deadLetteredAt}},
+		{name: "inside range", filter: dlq.Filter{From: deadLetteredAt.Add(-time.Hour), To: deadLetteredAt.Add(time.Hour)}, match: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, tt.filter.Match(msg))
+		})
+	}
+}
+
+func TestReplay_RepublishesMatchingMessages(t *testing.T) {
+	checkpoint, err := dlq.LoadCheckpoint(filepath.Join(t.TempDir(), "cp.json"), models.PaymentsDLQTopic, dlq.Filter{ErrorContains: "database"})
+	require.NoError(t, err)
+	writer := &fakeWriter{}
+	replayer := dlq.NewReplayer(newSource(t), writer, dlq.Filter{ErrorContains: "database"}, checkpoint, false)
+
+	stats, err := replayer.Replay(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, dlq.Stats{Read: 5, Matched: 3, Replayed: 3, Invalid: 1}, stats)
+	require.Len(t, writer.messages, 3)
+
+	replayed := writer.messages[0]
+	assert.Equal(t, "payments.checked", replayed.Topic)
+	assert.Equal(t, "payment-1", string(replayed.Key))
+	assert.JSONEq(t, `{"payment_id":"payment-1"}`, string(replayed.Value))
+	assert.Equal(t, dlq.HeaderReplay, replayed.Headers[0].Key)
+	assert.Equal(t, "payments.dlq/0/0", string(replayed.Headers[0].Value))
+
+	assert.Equal(t, int64(3), checkpoint.Offset(0))
+	assert.Equal(t, int64(2), checkpoint.Offset(1))
+}
+
+func TestReplay_RestoresBinaryValueAndContentType(t *testing.T) {
+	original := models.DLQMessage{
+		OriginalTopic: "payments.checked",
+		Key:           "payment-1",
+		Headers:       map[string]string{contracts.HeaderContentType: contracts.ProtobufContentType},
+	}
+	original.SetValue([]byte{0x0a, 0xff, 0xfe, 0x00})
+	value, err := json.Marshal(original)
+	require.NoError(t, err)
+	source := &fakeSource{partitions: map[int][]kafka.Message{
+		0: {{Topic: models.PaymentsDLQTopic, Partition: 0, Offset: 0, Value: value}},
+	}}
+	checkpoint, err := dlq.LoadCheckpoint(filepath.Join(t.TempDir(), "cp.json"), models.PaymentsDLQTopic, dlq.Filter{})
+	require.NoError(t, err)
+	writer := &fakeWriter{}
+
+	_, err = dlq.NewReplayer(source, writer, dlq.Filter{}, checkpoint, false).Replay(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, writer.messages, 1)
+	assert.Equal(t, models.DLQValueBase64, original.ValueEncoding)
+	assert.Equal(t, []byte{0x0a, 0xff, 0xfe, 0x00}, writer.messages[0].Value)
+	assert.Contains(t, writer.messages[0].Headers, kafka.Header{Key: contracts.HeaderContentType, Value: []byte(contracts.ProtobufContentType)})
+}
+
+func TestReplay_DryRunPublishesNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp.json")
+	checkpoint, err := dlq.LoadCheckpoint(path, models.PaymentsDLQTopic, dlq.Filter{OriginalTopic: "payments.checked"})
+	require.NoError(t, err)
+	writer := &fakeWriter{}
+	replayer := dlq.NewReplayer(newSource(t), writer, dlq.Filter{OriginalTopic: "payments.checked"}, checkpoint, true)
+
+	stats, err := replayer.Replay(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Matched)
+	assert.Zero(t, stats.Replayed)
+	assert.Empty(t, writer.messages)
+	assert.NoFileExists(t, path)
+}
+
+func TestReplay_ResumesFromCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp.json")
+	checkpoint, err := dlq.LoadCheckpoint(path, models.PaymentsDLQTopic, dlq.Filter{})
+	require.NoError(t, err)
+	source := newSource(t)
+
+	failing := &fakeWriter{failOn: "payment-4"}
+	_, err = dlq.NewReplayer(source, failing, dlq.Filter{}, checkpoint, false).Replay(context.Background())
+	require.Error(t, err)
+	assert.Len(t, failing.messages, 3)
+
+	resumed, err := dlq.LoadCheckpoint(path, models.PaymentsDLQTopic, dlq.Filter{})
+	require.NoError(t, err)
+	writer := &fakeWriter{}
+	stats, err := dlq.NewReplayer(source, writer, dlq.Filter{}, resumed, false).Replay(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Replayed)
+	require.Len(t, writer.messages, 1)
+	assert.Equal(t, "payment-4", string(writer.messages[0].Key))
+}
+
+func TestLoadCheckpoint_RejectsOtherTopic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp.json")
+	checkpoint, err := dlq.LoadCheckpoint(path, models.PaymentsDLQTopic, dlq.Filter{})
+	require.NoError(t, err)
+	checkpoint.Advance(0, 10)
+	require.NoError(t, checkpoint.Save())
+
+	_, err = dlq.LoadCheckpoint(path, "wallet.dlq", dlq.Filter{})
+
+	assert.Error(t, err)
+}
+
+func TestReplay_DifferentFiltersKeepTheirOwnProgress(t *testing.T) {
+	dir := t.TempDir()
+	source := newSource(t)
+	replay := func(filter dlq.Filter) *fakeWriter {
+		t.Helper()
+		path := filepath.Join(dir, dlq.DefaultCheckpointPath(models.PaymentsDLQTopic, filter))
+		checkpoint, err := dlq.LoadCheckpoint(path, models.PaymentsDLQTopic, filter)
+		require.NoError(t, err)
+		writer := &fakeWriter{}
+		_, err = dlq.NewReplayer(source, writer, filter, checkpoint, false).Replay(context.Background())
+		require.NoError(t, err)
+		return writer
+	}
+
+	checked := replay(dlq.Filter{OriginalTopic: "payments.checked"})
+	assert.Len(t, checked.messages, 3)
+
+	wallet := replay(dlq.Filter{OriginalTopic: "wallet.funds.verified"})
+	require.Len(t, wallet.messages, 1, "the first run must not consume the wallet entries")
+	assert.Equal(t, "payment-2", string(wallet.messages[0].Key))
+
+	again := replay(dlq.Filter{OriginalTopic: "payments.checked"})
+	assert.Empty(t, again.messages, "each filter resumes from its own checkpoint")
+}
+
+func TestLoadCheckpoint_RejectsOtherFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp.json")
+	checkpoint, err := dlq.LoadCheckpoint(path, models.PaymentsDLQTopic, dlq.Filter{OriginalTopic: "payments.checked"})
+	require.NoError(t, err)
+	checkpoint.Advance(0, 10)
+	require.NoError(t, checkpoint.Save())
+
+	_, err = dlq.LoadCheckpoint(path, models.PaymentsDLQTopic, dlq.Filter{OriginalTopic: "wallet.funds.verified"})
+
+	assert.ErrorContains(t, err, "filter")
+}
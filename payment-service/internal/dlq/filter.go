@@ -0,0 +1,62 @@
+package dlq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+)
+
+// Filter selects the DLQ messages to replay. Empty fields match everything.
+type Filter struct {
+	OriginalTopic string
+	Key           string
+	ErrorContains string
+	From          time.Time
+	To            time.Time
+}
+
+// Match reports whether msg passes every criterion of the filter.
+// From is inclusive and To exclusive; both apply to the time the message
+// was dead-lettered.
+func (f Filter) Match(msg models.DLQMessage) bool {
+	if f.OriginalTopic != "" && msg.OriginalTopic != f.OriginalTopic {
+		return false
+	}
+	if f.Key != "" && msg.Key != f.Key {
+		return false
+	}
+	if f.ErrorContains != "" && !strings.Contains(strings.ToLower(msg.Error), strings.ToLower(f.ErrorContains)) {
+		return false
+	}
+	if !f.From.IsZero() && msg.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !msg.Timestamp.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// String renders every criterion of the filter. A checkpoint records it so
+// a replay only resumes with the filter that wrote it.
+func (f Filter) String() string {
+	return fmt.Sprintf("original_topic=%q key=%q error=%q from=%q to=%q",
+		f.OriginalTopic, f.Key, f.ErrorContains, formatTime(f.From), formatTime(f.To))
+}
+
+// Hash returns a short digest of the filter, used to name its checkpoint.
+func (f Filter) Hash() string {
+	sum := sha256.Sum256([]byte(f.String()))
+	return hex.EncodeToString(sum[:4])
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
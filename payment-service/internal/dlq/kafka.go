@@ -0,0 +1,147 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// defaultIdleTimeout is how long a read waits for the next message before the
+// rest of the partition is taken as unreadable.
+const defaultIdleTimeout = 5 * time.Second
+
+// KafkaSource reads a DLQ topic directly by partition, without a consumer
+// group: progress is tracked by the replay checkpoint, not committed offsets.
+type KafkaSource struct {
+	Brokers []string
+	Dialer  *kafka.Dialer
+	Topic   string
+	// IdleTimeout ends the read of a partition when no message arrives for
+	// that long before its end offset. The offsets left hold no records a
+	// reader can return, e.g. transaction markers or compacted records.
+	IdleTimeout time.Duration
+}
+
+func NewKafkaSource(brokers []string, dialer *kafka.Dialer, topic string) *KafkaSource {
+	return &KafkaSource{Brokers: brokers, Dialer: dialer, Topic: topic, IdleTimeout: defaultIdleTimeout}
+}
+
+func (s *KafkaSource) Partitions(ctx context.Context) ([]int, error) {
+	conn, err := s.dial(func(broker string) (*kafka.Conn, error) {
+		return s.Dialer.DialContext(ctx, "tcp", broker)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(s.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(partitions))
+	for i, p := range partitions {
+		ids[i] = p.ID
+	}
+	return ids, nil
+}
+
+// ReadPartition implements Source. It never waits for messages produced after
+// the call, nor longer than IdleTimeout for the next one.
+func (s *KafkaSource) ReadPartition(ctx context.Context, partition int, offset int64, fn func(kafka.Message) error) error {
+	first, end, err := s.Offsets(ctx, partition)
+	if err != nil {
+		return err
+	}
+
+	if offset < first {
+		offset = first
+	}
+	if offset >= end {
+		return nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   s.Brokers,
+		Dialer:    s.Dialer,
+		Topic:     s.Topic,
+		Partition: partition,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(offset); err != nil {
+		return err
+	}
+
+	if err := readRange(ctx, reader, end, s.IdleTimeout, fn); err != nil {
+		return fmt.Errorf("error reading partition %d: %w", partition, err)
+	}
+	return nil
+}
+
+// Offsets returns the first offset of partition and the offset after its last message.
+func (s *KafkaSource) Offsets(ctx context.Context, partition int) (first, end int64, err error) {
+	conn, err := s.dial(func(broker string) (*kafka.Conn, error) {
+		return s.Dialer.DialLeader(ctx, "tcp", broker, s.Topic, partition)
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("error connecting to partition %d leader: %w", partition, err)
+	}
+	defer conn.Close()
+
+	first, end, err = conn.ReadOffsets()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading offsets of partition %d: %w", partition, err)
+	}
+	return first, end, nil
+}
+
+// messageReader reads the messages of one partition; *kafka.Reader implements it.
+type messageReader interface {
+	ReadMessage(ctx context.Context) (kafka.Message, error)
+}
+
+// readRange calls fn for every message of r before the end offset. The last
+// offsets of a partition may hold no readable record, so the read also ends
+// when no message arrives for idle or when a message at or past end arrives.
+func readRange(ctx context.Context, r messageReader, end int64, idle time.Duration, fn func(kafka.Message) error) error {
+	for {
+		readCtx, cancel := context.WithTimeout(ctx, idle)
+		msg, err := r.ReadMessage(readCtx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Offset >= end {
+			return nil
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+		if msg.Offset+1 >= end {
+			return nil
+		}
+	}
+}
+
+// dial tries each broker in turn and returns the first connection that succeeds.
+func (s *KafkaSource) dial(connect func(broker string) (*kafka.Conn, error)) (*kafka.Conn, error) {
+	var errs []error
+	for _, broker := range s.Brokers {
+		conn, err := connect(broker)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, fmt.Errorf("broker %s: %w", broker, err))
+	}
+	return nil, errors.Join(errs...)
+}
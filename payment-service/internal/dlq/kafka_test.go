@@ -0,0 +1,71 @@
+package dlq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReader returns its messages in order and then blocks like a reader at
+// the end of its partition.
+type fakeReader struct {
+	messages []kafka.Message
+}
+
+func (r *fakeReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.messages) == 0 {
+		<-ctx.Done()
+		return kafka.Message{}, ctx.Err()
+	}
+	msg := r.messages[0]
+	r.messages = r.messages[1:]
+	return msg, nil
+}
+
+func offsets(n ...int64) []kafka.Message {
+	messages := make([]kafka.Message, len(n))
+	for i, offset := range n {
+		messages[i] = kafka.Message{Offset: offset}
+	}
+	return messages
+}
+
+func TestReadRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []kafka.Message
+		end      int64
+		want     []int64
+	}{
+		{name: "stops at the end offset", messages: offsets(3, 4, 5), end: 6, want: []int64{3, 4, 5}},
+		{name: "transaction marker before the end", messages: offsets(3, 4), end: 6, want: []int64{3, 4}},
+		{name: "compacted gap", messages: offsets(3, 7), end: 9, want: []int64{3, 7}},
+		{name: "message produced after the call", messages: offsets(3, 5, 6), end: 5, want: []int64{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var read []int64
+			err := readRange(context.Background(), &fakeReader{messages: tt.messages}, tt.end, 20*time.Millisecond, func(msg kafka.Message) error {
+				read = append(read, msg.Offset)
+				return nil
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, read)
+		})
+	}
+}
+
+func TestReadRangeStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := readRange(ctx, &fakeReader{}, 10, time.Hour, func(kafka.Message) error { return nil })
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
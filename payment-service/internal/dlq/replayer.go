@@ -0,0 +1,162 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/internal/logging"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Headers added to every replayed message.
+const (
+	// HeaderReplay holds the DLQ coordinates of the message: topic/partition/offset.
+	HeaderReplay = "x-dlq-replay"
+	// HeaderReplayedAt holds the time of the replay in RFC 3339.
+	HeaderReplayedAt = "x-dlq-replayed-at"
+)
+
+// Source reads the messages of a DLQ topic partition by partition.
+type Source interface {
+	Partitions(ctx context.Context) ([]int, error)
+	// ReadPartition calls fn for every message of partition from offset up
+	// to the end of the partition at the time of the call. An offset of -1
+	// means the first offset.
+	ReadPartition(ctx context.Context, partition int, offset int64, fn func(kafka.Message) error) error
+}
+
+// MessageWriter publishes messages to the topic set on each message.
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Stats summarizes a replay run.
+type Stats struct {
+	Read     int
+	Matched  int
+	Replayed int
+	Invalid  int
+}
+
+// Replayer republishes the DLQ messages that match Filter to their original topic.
+// In DryRun mode matching messages are only reported and the checkpoint is
+// left untouched.
+type Replayer struct {
+	Source     Source
+	Writer     MessageWriter
+	Filter     Filter
+	Checkpoint *Checkpoint
+	DryRun     bool
+}
+
+func NewReplayer(source Source, writer MessageWriter, filter Filter, checkpoint *Checkpoint, dryRun bool) *Replayer {
+	return &Replayer{
+		Source:     source,
+		Writer:     writer,
+		Filter:     filter,
+		Checkpoint: checkpoint,
+		DryRun:     dryRun,
+	}
+}
+
+// Replay reads every partition from its checkpointed offset. The checkpoint is
+// saved after each replayed message, so at most one message is republished
+// twice if the process dies between the publish and the save.
+func (r *Replayer) Replay(ctx context.Context) (Stats, error) {
+	var stats Stats
+
+	partitions, err := r.Source.Partitions(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("error listing partitions of %s: %w", r.Checkpoint.DLQTopic, err)
+	}
+
+	for _, partition := range partitions {
+		err := r.Source.ReadPartition(ctx, partition, r.Checkpoint.Offset(partition), func(msg kafka.Message) error {
+			return r.handle(ctx, msg, &stats)
+		})
+		if err != nil {
+			return stats, errors.Join(err, r.save())
+		}
+	}
+
+	return stats, r.save()
+}
+
+func (r *Replayer) save() error {
+	if r.DryRun {
+		return nil
+	}
+	return r.Checkpoint.Save()
+}
+
+func (r *Replayer) handle(ctx context.Context, msg kafka.Message, stats *Stats) error {
+	stats.Read++
+	logger := logrus.WithFields(logging.Message(msg.Topic, msg.Partition, msg.Offset))
+
+	var dlqMessage models.DLQMessage
+	if err := json.Unmarshal(msg.Value, &dlqMessage); err != nil || dlqMessage.OriginalTopic == "" {
+		stats.Invalid++
+		logger.Warn("Skipping undecodable DLQ message")
+		return r.advance(msg, false)
+	}
+
+	if !r.Filter.Match(dlqMessage) {
+		return r.advance(msg, false)
+	}
+	stats.Matched++
+	logger = logger.WithFields(logrus.Fields{logging.FieldKey: dlqMessage.Key, logging.FieldTraceID: dlqMessage.TraceID})
+
+	if r.DryRun {
+		logger.Infof("[dry-run] Would replay to %s: error=%q", dlqMessage.OriginalTopic, dlqMessage.Error)
+		return nil
+	}
+
+	value, err := dlqMessage.RawValue()
+	if err != nil {
+		stats.Invalid++
+		logger.WithError(err).Warn("Skipping DLQ message with undecodable value")
+		return r.advance(msg, false)
+	}
+
+	replay := kafka.Message{
+		Topic: dlqMessage.OriginalTopic,
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: HeaderReplay, Value: fmt.Appendf(nil, "%s/%d/%d", msg.Topic, msg.Partition, msg.Offset)},
+			{Key: HeaderReplayedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+		},
+	}
+	if contentType := dlqMessage.Headers[contracts.HeaderContentType]; contentType != "" {
+		replay.Headers = append(replay.Headers, kafka.Header{Key: contracts.HeaderContentType, Value: []byte(contentType)})
+	}
+	if dlqMessage.Key != "" {
+		replay.Key = []byte(dlqMessage.Key)
+	}
+
+	if err := r.Writer.WriteMessages(ctx, replay); err != nil {
+		return fmt.Errorf("error replaying partition=%d, offset=%d to %s: %w", msg.Partition, msg.Offset, dlqMessage.OriginalTopic, err)
+	}
+	stats.Replayed++
+	logger.Infof("Replayed to %s", dlqMessage.OriginalTopic)
+
+	return r.advance(msg, true)
+}
+
+// advance moves the checkpoint past msg, saving it right away when msg was republished.
+func (r *Replayer) advance(msg kafka.Message, save bool) error {
+	if r.DryRun {
+		return nil
+	}
+
+	r.Checkpoint.Advance(msg.Partition, msg.Offset)
+	if !save {
+		return nil
+	}
+	return r.save()
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/jeffleon2/draftea-payment-service/internal/service"
+)
+
+// DLQService defines the interface for browsing DLQ topics.
+type DLQService interface {
+	ListMessages(ctx context.Context, query models.DLQQuery) (*models.DLQPage, error)
+	Summary(ctx context.Context, topic string) (*models.DLQSummary, error)
+}
+
+// DLQHandler exposes the contents of the DLQ topics over HTTP for triage.
+type DLQHandler struct {
+	Service DLQService
+}
+
+// NewDLQHandler creates a new DLQHandler with the provided service.
+func NewDLQHandler(s DLQService) *DLQHandler {
+	return &DLQHandler{Service: s}
+}
+
+// ListMessages handles GET /dlq/:topic/messages.
+// It supports the original_topic, error_class and key filters and
+// page/page_size pagination.
+func (h *DLQHandler) ListMessages(c *gin.Context) {
+	var query models.DLQQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+	query.Topic = c.Param("topic")
+
+	page, err := h.Service.ListMessages(c.Request.Context(), query)
+	if err != nil {
+		respondDLQError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Summary handles GET /dlq/:topic/summary.
+func (h *DLQHandler) Summary(c *gin.Context) {
+	summary, err := h.Service.Summary(c.Request.Context(), c.Param("topic"))
+	if err != nil {
+		respondDLQError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func respondDLQError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrUnknownDLQTopic) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
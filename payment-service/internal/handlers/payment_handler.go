@@ -2,20 +2,27 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
+	"github.com/jeffleon2/draftea-payment-service/internal/logging"
 	"github.com/jeffleon2/draftea-payment-service/internal/models"
 	"github.com/jeffleon2/draftea-payment-service/internal/models/dto"
-	"github.com/sirupsen/logrus"
+	"github.com/jeffleon2/draftea-payment-service/internal/service"
+	"github.com/jeffleon2/draftea-payment-service/internal/subscriber"
+	"gorm.io/gorm"
 )
 
 // PaymentService defines the interface for payment business logic operations.
 type PaymentService interface {
 	CreatePayment(ctx context.Context, payment *dto.Payment) error
-	UpdatePaymentFlags(ctx context.Context, paymentID string, walletApproved, fraudClean *bool, failureReason string) error
+	UpdatePaymentFlags(ctx context.Context, eventID, paymentID string, walletApproved, fraudClean *bool, failureReason string) error
+	HoldForReview(ctx context.Context, eventID, paymentID, reason string) error
+	ResolveReview(ctx context.Context, paymentID string, approve bool, reason string) (*models.Payment, error)
 }
 
 // PaymentHandler handles HTTP requests and Kafka events for payment operations.
@@ -30,8 +37,11 @@ func NewPaymentHandler(s PaymentService) *PaymentHandler {
 }
 
 // CreatePayment handles POST /payments HTTP requests.
-// It validates the request body, delegates to the service layer,
-// and returns 201 Created on success or appropriate error status.
+// It validates the request body, delegates to the service layer with the
+// trace ID set by the TraceID middleware, and returns 201 Created on success
+// or appropriate error status.
+// While the Postgres or Kafka circuit breaker is open it fails fast with
+// 503 Service Unavailable.
 func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 	var req dto.Payment
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -40,6 +50,11 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 	}
 
 	if err := h.Service.CreatePayment(c.Request.Context(), &req); err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Failed to create payment")
+		if errors.Is(err, breaker.ErrOpen) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -47,46 +62,88 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 	c.JSON(http.StatusCreated, req)
 }
 
+// ResolveReview handles POST /payments/:id/review.
+// It approves or declines a payment held in REVIEW by fraud-service and
+// returns the updated payment; 409 Conflict if the payment is not in review.
+func (h *PaymentHandler) ResolveReview(c *gin.Context) {
+	var req dto.ReviewDecision
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	payment, err := h.Service.ResolveReview(c.Request.Context(), c.Param("id"), req.Decision == dto.ReviewApprove, req.Reason)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithField(logging.FieldPaymentID, c.Param("id")).WithError(err).Error("Failed to resolve payment review")
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNotInReview):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, breaker.ErrOpen):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
 // HandleEvents processes Kafka events for payment verification updates.
 // It handles two types of events:
 //   - wallet.funds.verified: Updates wallet approval status
-//   - payments.checked: Updates fraud check status
+//   - payments.checked: Updates fraud check status; REVIEW decisions hold the payment in REVIEW
 //
-// The handler unmarshals the event, extracts the relevant status,
+// The subscriber has already decoded and validated the envelope, whatever its
+// format; the handler decodes its data by type and version (unknown types
+// and major versions are permanent errors), extracts the relevant status,
 // and calls UpdatePaymentFlags to update the payment's verification state.
-func (h *PaymentHandler) HandleEvents(ctx context.Context, topic string, value []byte) error {
+// Redelivered events are recognised by their envelope ID and skipped.
+func (h *PaymentHandler) HandleEvents(ctx context.Context, topic string, envelope contracts.Envelope) error {
+	logger := logging.FromContext(ctx)
+
 	var walletStatus *bool
 	var fraudStatus *bool
 	var paymentID string
 	var failureReason string
 
-	switch topic {
-	case models.WalletTopic2Subscribe:
+	switch envelope.Type {
+	case models.EventTypeWalletFundsVerified:
 		var event models.WalletResponseEvent
-		if err := json.Unmarshal(value, &event); err != nil {
-			logrus.Errorf("Error parsing Wallet response event %s", err.Error())
-			return fmt.Errorf("error parsing Wallet response event %w", err)
+		if err := envelope.Decode(&event); err != nil {
+			logger.Errorf("Error parsing Wallet response event %s", err.Error())
+			return subscriber.Permanent(fmt.Errorf("error parsing Wallet response event %w", err))
 		}
 		flag := event.Status == models.PaymentStatusApproved
 		walletStatus = &flag
 		paymentID = event.PaymentID
 		failureReason = event.Reason
-	case models.FraudTopic2Subscribe:
+	case models.EventTypePaymentChecked:
 		var event models.FraudCheckEvent
-		if err := json.Unmarshal(value, &event); err != nil {
-			logrus.Errorf("Error parsing Fraud check event %s", err.Error())
-			return fmt.Errorf("error parsing Fraud check event %w", err)
+		if err := envelope.Decode(&event); err != nil {
+			logger.Errorf("Error parsing Fraud check event %s", err.Error())
+			return subscriber.Permanent(fmt.Errorf("error parsing Fraud check event %w", err))
+		}
+		if event.Status == models.PaymentStatusReview {
+			if err := h.Service.HoldForReview(ctx, envelope.ID, event.ID, event.Reason); err != nil {
+				logger.WithField(logging.FieldPaymentID, event.ID).WithError(err).Error("Error holding payment for review")
+				return fmt.Errorf("error holding payment for review %w", err)
+			}
+			return nil
 		}
 		flag := event.Status == models.PaymentStatusApproved
 		fraudStatus = &flag
 		paymentID = event.ID
 		failureReason = event.Reason
 	default:
-		logrus.Errorf("topic not allowed %s", topic)
-		return fmt.Errorf("topic not allowed %s", topic)
+		logger.Errorf("event type %s not allowed on topic %s", envelope.Type, topic)
+		return subscriber.Permanent(fmt.Errorf("event type %s not allowed on topic %s", envelope.Type, topic))
 	}
 
-	if err := h.Service.UpdatePaymentFlags(ctx, paymentID, walletStatus, fraudStatus, failureReason); err != nil {
+	if err := h.Service.UpdatePaymentFlags(ctx, envelope.ID, paymentID, walletStatus, fraudStatus, failureReason); err != nil {
+		logger.WithField(logging.FieldPaymentID, paymentID).WithError(err).Error("Error updating payment flags")
 		return fmt.Errorf("error updating payment flags %w", err)
 	}
 
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+)
+
+// TraceIDHeader is the HTTP header carrying the trace ID of a payment flow.
+const TraceIDHeader = "X-Trace-ID"
+
+// maxTraceIDLength bounds client-supplied trace IDs, which travel in every
+// message header and log line of the flow.
+const maxTraceIDLength = 128
+
+// TraceID accepts the client's X-Trace-ID, or generates one when it is missing
+// or invalid, puts it in the request context (see contracts.TraceID) and
+// returns it in the response header.
+func TraceID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader(TraceIDHeader)
+		if !validTraceID(traceID) {
+			traceID = contracts.NewTraceID()
+		}
+
+		c.Request = c.Request.WithContext(contracts.WithTraceID(c.Request.Context(), traceID))
+		c.Header(TraceIDHeader, traceID)
+		c.Next()
+	}
+}
+
+// validTraceID accepts non-empty IDs of printable ASCII characters without
+// spaces, so they are safe to copy into headers and logs.
+func validTraceID(traceID string) bool {
+	if traceID == "" || len(traceID) > maxTraceIDLength {
+		return false
+	}
+	for i := 0; i < len(traceID); i++ {
+		if traceID[i] <= ' ' || traceID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
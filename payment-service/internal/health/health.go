@@ -0,0 +1,182 @@
+// Package health serves the liveness and readiness probes of the service.
+// Liveness only tells whether the process answers; readiness also runs the
+// checks of its dependencies and is off while the service starts or stops.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Statuses of a report and of each of its checks.
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+	StatusStarting  = "starting"
+	StatusStopping  = "stopping"
+)
+
+const (
+	stateStarting int32 = iota
+	stateReady
+	stateStopping
+)
+
+// Check reports whether a dependency of the service works.
+type Check func(ctx context.Context) error
+
+type check struct {
+	name     string
+	critical bool
+	run      Check
+}
+
+// Checker runs the dependency checks of the service and tracks whether it is
+// ready for traffic. A new Checker is starting until Ready is called.
+type Checker struct {
+	// Timeout bounds every run of the checks.
+	Timeout time.Duration
+
+	checks []check
+	state  atomic.Int32
+}
+
+// NewChecker returns a starting Checker whose checks time out after timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{Timeout: timeout}
+}
+
+// Critical registers a check whose failure makes the service unhealthy and
+// takes it out of service.
+func (c *Checker) Critical(name string, run Check) {
+	c.checks = append(c.checks, check{name: name, critical: true, run: run})
+}
+
+// Optional registers a check whose failure only degrades the service.
+func (c *Checker) Optional(name string, run Check) {
+	c.checks = append(c.checks, check{name: name, run: run})
+}
+
+// Ready marks the end of the startup: readiness now depends on the checks.
+func (c *Checker) Ready() {
+	c.state.Store(stateReady)
+}
+
+// Stopping marks the start of the shutdown: the service is not ready anymore,
+// so no new traffic is routed to it while it drains.
+func (c *Checker) Stopping() {
+	c.state.Store(stateStopping)
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the body of the probes.
+type Report struct {
+	Status    string                 `json:"status"`
+	Timestamp time.Time              `json:"timestamp"`
+	Checks    map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Run runs every check concurrently and reports the status of the service:
+// unhealthy if a critical check fails, degraded if an optional one does.
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = CheckResult{Status: StatusHealthy}
+			if err := check.run(ctx); err != nil {
+				results[i] = CheckResult{Status: StatusUnhealthy, Error: err.Error()}
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusHealthy, Timestamp: time.Now().UTC(), Checks: make(map[string]CheckResult, len(c.checks))}
+	for i, check := range c.checks {
+		report.Checks[check.name] = results[i]
+		if results[i].Status == StatusHealthy {
+			continue
+		}
+		logrus.WithField("check", check.name).Warnf("Health check failed: %s", results[i].Error)
+		switch {
+		case check.critical:
+			report.Status = StatusUnhealthy
+		case report.Status == StatusHealthy:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// Liveness serves /healthz. It does not run the checks: a dependency outage
+// must not restart every instance of the service.
+func (c *Checker) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: StatusHealthy, Timestamp: time.Now().UTC()})
+}
+
+// Readiness serves /readyz: 503 while the service starts, stops or is
+// unhealthy, 200 otherwise.
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	switch c.state.Load() {
+	case stateStarting:
+		writeReport(w, http.StatusServiceUnavailable, Report{Status: StatusStarting, Timestamp: time.Now().UTC()})
+		return
+	case stateStopping:
+		writeReport(w, http.StatusServiceUnavailable, Report{Status: StatusStopping, Timestamp: time.Now().UTC()})
+		return
+	}
+
+	report := c.Run(r.Context())
+	code := http.StatusOK
+	if report.Status == StatusUnhealthy {
+		code = http.StatusServiceUnavailable
+	}
+	writeReport(w, code, report)
+}
+
+func writeReport(w http.ResponseWriter, code int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// Database checks that the connection pool of db reaches Postgres.
+func Database(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// NATS checks that conn is connected to the NATS server.
+func NATS(conn *nats.Conn) Check {
+	return func(context.Context) error {
+		if status := conn.Status(); status != nats.CONNECTED {
+			return fmt.Errorf("NATS connection is %s", status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jeffleon2/draftea-payment-service/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passing(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("connection refused") }
+
+// probe calls handler and returns the status code and the decoded report.
+func probe(t *testing.T, handler http.HandlerFunc) (int, health.Report) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return rec.Code, report
+}
+
+func TestReadinessFlipsWithStartupAndShutdown(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Critical("postgres", passing)
+
+	code, report := probe(t, checker.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusStarting, report.Status)
+
+	checker.Ready()
+	code, report = probe(t, checker.Readiness)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusHealthy, report.Status)
+	assert.Equal(t, health.CheckResult{Status: health.StatusHealthy}, report.Checks["postgres"])
+
+	checker.Stopping()
+	code, report = probe(t, checker.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusStopping, report.Status)
+}
+
+func TestFailingOptionalCheckDegradesButStaysReady(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Critical("kafka", passing)
+	checker.Optional("kafka_consumer_group", failing)
+	checker.Ready()
+
+	code, report := probe(t, checker.Readiness)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, health.CheckResult{Status: health.StatusUnhealthy, Error: "connection refused"}, report.Checks["kafka_consumer_group"])
+}
+
+func TestFailingCriticalCheckIsNotReady(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Critical("postgres", failing)
+	checker.Optional("kafka_consumer_group", failing)
+	checker.Ready()
+
+	code, report := probe(t, checker.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusUnhealthy, report.Status)
+}
+
+func TestChecksAreBoundedByTheTimeout(t *testing.T) {
+	checker := health.NewChecker(10 * time.Millisecond)
+	checker.Critical("kafka", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	checker.Ready()
+
+	report := checker.Run(context.Background())
+	assert.Equal(t, health.StatusUnhealthy, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["kafka"].Error)
+}
+
+func TestLivenessDoesNotRunTheChecks(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Critical("postgres", failing)
+
+	code, report := probe(t, checker.Liveness)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusHealthy, report.Status)
+	assert.Empty(t, report.Checks)
+}
@@ -0,0 +1,136 @@
+// Package logging configures the structured logger of the service. Every
+// entry carries the service name, the work in progress is identified by the
+// trace_id, payment_id, topic, partition and offset fields, and secrets and
+// customer identifiers are redacted before an entry is written.
+package logging
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Fields of the log entries.
+const (
+	FieldService    = "service"
+	FieldTraceID    = "trace_id"
+	FieldPaymentID  = "payment_id"
+	FieldCustomerID = "customer_id"
+	FieldTopic      = "topic"
+	FieldPartition  = "partition"
+	FieldOffset     = "offset"
+	FieldKey        = "key"
+)
+
+// fieldMap names the built-in fields of every format alike.
+var fieldMap = logrus.FieldMap{
+	logrus.FieldKeyMsg:  "message",
+	logrus.FieldKeyTime: "timestamp",
+}
+
+// Setup configures the standard logrus logger with the level and format of
+// cfg, the service field and redaction. The standard library log package,
+// which some dependencies write to, is routed through it.
+func Setup(cfg config.Logging) error {
+	level, err := cfg.LogLevel()
+	if err != nil {
+		return err
+	}
+	format, err := cfg.LogFormat()
+	if err != nil {
+		return err
+	}
+
+	logger := logrus.StandardLogger()
+	logger.SetLevel(level)
+	switch format {
+	case config.LogFormatText:
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true, TimestampFormat: time.RFC3339Nano, FieldMap: fieldMap})
+	default:
+		logger.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano, FieldMap: fieldMap})
+	}
+	logger.ReplaceHooks(logrus.LevelHooks{})
+	logger.AddHook(hook{})
+
+	log.SetFlags(0)
+	log.SetOutput(logger.WriterLevel(logrus.InfoLevel))
+	return nil
+}
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx whose logger (see FromContext) has fields,
+// e.g. the Message fields of the message a handler is processing.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// FromContext returns the logger of the work in ctx: its trace ID and the
+// fields added with WithFields.
+func FromContext(ctx context.Context) *logrus.Entry {
+	entry := logrus.WithContext(ctx)
+	if fields, ok := ctx.Value(fieldsKey{}).(logrus.Fields); ok {
+		entry = entry.WithFields(fields)
+	}
+	if traceID := contracts.TraceID(ctx); traceID != "" {
+		entry = entry.WithField(FieldTraceID, traceID)
+	}
+	return entry
+}
+
+// Message returns the fields of a consumed Kafka message.
+func Message(topic string, partition int, offset int64) logrus.Fields {
+	return logrus.Fields{FieldTopic: topic, FieldPartition: partition, FieldOffset: offset}
+}
+
+// hook adds the service field to every entry and redacts it.
+type hook struct{}
+
+// Levels implements logrus.Hook.
+func (hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook. The entry is a copy made for this write, so
+// it can be changed in place.
+func (hook) Fire(entry *logrus.Entry) error {
+	entry.Message = Redact(entry.Message)
+	for key, value := range entry.Data {
+		entry.Data[key] = redactField(key, value)
+	}
+	entry.Data[FieldService] = models.ServiceName
+	return nil
+}
+
+// Requests logs every request that passes filter once it is served, with
+// its route, status and latency. The route is the path template, which
+// keeps the identifiers in the URL out of the logs. Server errors are logged
+// as errors.
+func Requests(filter func(c *gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		if !filter(c) {
+			return
+		}
+
+		entry := FromContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"route":      c.FullPath(),
+			"status":     c.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+		})
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			entry.Error("Request served")
+			return
+		}
+		entry.Info("Request served")
+	}
+}
@@ -0,0 +1,92 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capture sets the logger up with cfg and returns the buffer it writes to.
+func capture(t *testing.T, cfg config.Logging) *bytes.Buffer {
+	t.Helper()
+
+	require.NoError(t, logging.Setup(cfg))
+	var buf bytes.Buffer
+	logrus.SetOutput(&buf)
+	t.Cleanup(func() { logrus.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestEntriesAreJSONWithTheFieldsOfTheirWork(t *testing.T) {
+	buf := capture(t, config.Logging{Level: "info", Format: config.LogFormatJSON})
+
+	ctx := logging.WithFields(contracts.WithTraceID(context.Background(), "trace-1"), logging.Message("payments.checked", 2, 42))
+	logging.FromContext(ctx).WithField(logging.FieldPaymentID, "pay_1").Info("Payment flags updated")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "payment-service", entry[logging.FieldService])
+	assert.Equal(t, "trace-1", entry[logging.FieldTraceID])
+	assert.Equal(t, "pay_1", entry[logging.FieldPaymentID])
+	assert.Equal(t, "payments.checked", entry[logging.FieldTopic])
+	assert.EqualValues(t, 2, entry[logging.FieldPartition])
+	assert.EqualValues(t, 42, entry[logging.FieldOffset])
+	assert.Equal(t, "Payment flags updated", entry["message"])
+	assert.Equal(t, "info", entry["level"])
+	assert.Contains(t, entry, "timestamp")
+}
+
+func TestEntriesBelowTheLevelAreDropped(t *testing.T) {
+	buf := capture(t, config.Logging{Level: "warn", Format: config.LogFormatText})
+
+	logrus.Info("dropped")
+	logrus.Warn("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), `message=kept`)
+	assert.Contains(t, buf.String(), `service=payment-service`)
+}
+
+func TestSecretsAndCustomerIdentifiersAreRedacted(t *testing.T) {
+	buf := capture(t, config.Logging{Level: "info", Format: config.LogFormatJSON})
+
+	logrus.WithFields(logrus.Fields{
+		"db_password":           "s3cret",
+		logging.FieldCustomerID: "user_1",
+		logging.FieldKey:        "user_1",
+	}).WithError(errors.New(`dial failed: password=s3cret`)).Error(`connecting with token=abc123 for {"customer_id":"user_1"}`)
+
+	assert.NotContains(t, buf.String(), "s3cret")
+	assert.NotContains(t, buf.String(), "abc123")
+	assert.NotContains(t, buf.String(), "user_1")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	masked := logging.MaskCustomerID("user_1")
+	assert.Equal(t, masked, entry[logging.FieldCustomerID])
+	assert.Equal(t, masked, entry[logging.FieldKey], "message keys can be user IDs")
+	assert.Equal(t, "[REDACTED]", entry["db_password"])
+	assert.Equal(t, "dial failed: password=[REDACTED]", entry["error"])
+	assert.Equal(t, `connecting with token=[REDACTED] for {"customer_id":"`+masked+`"}`, entry["message"])
+}
+
+func TestMaskCustomerIDIsStable(t *testing.T) {
+	assert.Equal(t, logging.MaskCustomerID("user_1"), logging.MaskCustomerID("user_1"))
+	assert.NotEqual(t, logging.MaskCustomerID("user_1"), logging.MaskCustomerID("user_2"))
+	assert.Empty(t, logging.MaskCustomerID(""))
+}
+
+func TestSetupRejectsUnknownSettings(t *testing.T) {
+	assert.Error(t, logging.Setup(config.Logging{Level: "loud", Format: config.LogFormatJSON}))
+	assert.Error(t, logging.Setup(config.Logging{Level: "info", Format: "xml"}))
+}
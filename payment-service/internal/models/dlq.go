@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// DLQEntry is a message stored in a DLQ topic. Raw holds the undecoded value
+// when it is not a valid DLQMessage.
+type DLQEntry struct {
+	Partition int        `json:"partition"`
+	Offset    int64      `json:"offset"`
+	Message   DLQMessage `json:"message"`
+	Raw       string     `json:"raw,omitempty"`
+}
+
+// DLQQuery selects a page of a DLQ topic. Empty filters match every message.
+type DLQQuery struct {
+	Topic         string `form:"-"`
+	OriginalTopic string `form:"original_topic"`
+	ErrorClass    string `form:"error_class"`
+	Key           string `form:"key"`
+	Page          int    `form:"page" binding:"omitempty,min=1"`
+	PageSize      int    `form:"page_size" binding:"omitempty,min=1,max=500"`
+}
+
+// DLQPage is a page of DLQ entries, newest first. Truncated reports that
+// only the newest messages of the topic were read, so Total is a lower bound.
+type DLQPage struct {
+	Topic     string     `json:"topic"`
+	Page      int        `json:"page"`
+	PageSize  int        `json:"page_size"`
+	Total     int        `json:"total"`
+	Truncated bool       `json:"truncated"`
+	Entries   []DLQEntry `json:"entries"`
+}
+
+// DLQErrorCount counts the messages of an original topic that failed with the same error.
+type DLQErrorCount struct {
+	OriginalTopic string    `json:"original_topic"`
+	Error         string    `json:"error"`
+	ErrorClass    string    `json:"error_class"`
+	Count         int       `json:"count"`
+	LastSeen      time.Time `json:"last_seen"`
+}
+
+// DLQSummary aggregates the contents of a DLQ topic. Truncated reports that
+// only its newest messages were counted.
+type DLQSummary struct {
+	Topic           string          `json:"topic"`
+	Total           int             `json:"total"`
+	Truncated       bool            `json:"truncated"`
+	Invalid         int             `json:"invalid"`
+	ByOriginalTopic map[string]int  `json:"by_original_topic"`
+	ByErrorClass    map[string]int  `json:"by_error_class"`
+	Errors          []DLQErrorCount `json:"errors"`
+}
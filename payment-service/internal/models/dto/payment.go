@@ -12,6 +12,9 @@ type Payment struct {
 	Status     string  `json:"status"`
 	Method     string  `json:"method"`
 	CustomerID string  `json:"customer_id"`
+	// CustomerName and CustomerCountry are used by fraud-service for sanctions screening.
+	CustomerName    string `json:"customer_name,omitempty"`
+	CustomerCountry string `json:"customer_country,omitempty"`
 }
 
 func (p *Payment) Sanitize() {
@@ -19,18 +22,23 @@ func (p *Payment) Sanitize() {
 	p.Status = strings.TrimSpace(p.Status)
 	p.Method = strings.TrimSpace(p.Method)
 	p.CustomerID = strings.TrimSpace(p.CustomerID)
+	p.CustomerName = strings.TrimSpace(p.CustomerName)
+	p.CustomerCountry = strings.TrimSpace(p.CustomerCountry)
 
 	p.Currency = strings.ToUpper(p.Currency)
 	p.Status = strings.ToUpper(p.Status)
 	p.Method = strings.ToUpper(p.Method)
+	p.CustomerCountry = strings.ToUpper(p.CustomerCountry)
 }
 
 func (p *Payment) ToEntity() *models.Payment {
 	return &models.Payment{
-		Amount:     p.Amount,
-		Currency:   models.Currency(p.Currency),
-		Method:     models.PaymentMethod(p.Method),
-		CustomerID: p.CustomerID,
-		Status:     models.StatusPending,
+		Amount:          p.Amount,
+		Currency:        models.Currency(p.Currency),
+		Method:          models.PaymentMethod(p.Method),
+		CustomerID:      p.CustomerID,
+		CustomerName:    p.CustomerName,
+		CustomerCountry: p.CustomerCountry,
+		Status:          models.StatusPending,
 	}
 }
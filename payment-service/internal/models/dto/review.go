@@ -0,0 +1,13 @@
+package dto
+
+// Review decisions accepted by POST /payments/:id/review.
+const (
+	ReviewApprove = "APPROVE"
+	ReviewDecline = "DECLINE"
+)
+
+// ReviewDecision is the body of POST /payments/:id/review.
+type ReviewDecision struct {
+	Decision string `json:"decision" binding:"required,oneof=APPROVE DECLINE"`
+	Reason   string `json:"reason"`
+}
@@ -17,6 +17,9 @@ const (
 	StatusAuthorized PaymentStatus = "AUTHORIZED"
 	StatusFailed     PaymentStatus = "FAILED"
 	StatusCancelled  PaymentStatus = "CANCELLED"
+	// StatusReview holds a payment that fraud-service sent to manual review
+	// until it is approved or declined through POST /payments/:id/review.
+	StatusReview PaymentStatus = "REVIEW"
 
 	CurrencyUSD Currency = "USD"
 	CurrencyEUR Currency = "EUR"
@@ -30,19 +33,22 @@ const (
 )
 
 type Payment struct {
-	ID             string        `json:"id"`
-	Amount         float64       `json:"amount"`
-	Currency       Currency      `json:"currency"`
-	Status         PaymentStatus `json:"status"`
-	Method         PaymentMethod `json:"method"`
-	CustomerID     string        `json:"customer_id"`
-	WalletApproved bool
-	FraudCleared   bool
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	AuthorizedAt   time.Time `json:"authorized_at,omitempty"`
-	FailedReason   string    `json:"failed_reason,omitempty"`
-	TraceID        string    `json:"trace_id"`
+	ID              string        `json:"id"`
+	Amount          float64       `json:"amount"`
+	Currency        Currency      `json:"currency"`
+	Status          PaymentStatus `json:"status"`
+	Method          PaymentMethod `json:"method"`
+	CustomerID      string        `json:"customer_id"`
+	CustomerName    string        `json:"customer_name,omitempty"`
+	CustomerCountry string        `json:"customer_country,omitempty"`
+	WalletApproved  bool
+	FraudCleared    bool
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	AuthorizedAt    time.Time `json:"authorized_at,omitempty"`
+	FailedReason    string    `json:"failed_reason,omitempty"`
+	ReviewReason    string    `json:"review_reason,omitempty"`
+	TraceID         string    `json:"trace_id"`
 }
 
 func (p *Payment) BeforeCreate(tx *gorm.DB) (err error) {
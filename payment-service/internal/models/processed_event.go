@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ProcessedEvent is an inbox entry: a consumed event whose effects are
+// already committed. It is written in the same transaction as those effects,
+// so a redelivered event is recognised and skipped.
+type ProcessedEvent struct {
+	ID          string    `gorm:"primaryKey" json:"id"`
+	ProcessedAt time.Time `gorm:"index" json:"processed_at"`
+}
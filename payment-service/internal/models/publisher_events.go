@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"encoding/base64"
+	"time"
+	"unicode/utf8"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+)
 
 const (
 	PaymentCreatedEventTopic = "payments.created"
@@ -8,29 +14,59 @@ const (
 	PaymentsDLQTopic         = "payments.dlq"
 )
 
-type PaymentCreatedEvent struct {
-	ID         string    `json:"id"`
-	Amount     float64   `json:"amount"`
-	Currency   string    `json:"currency"`
-	Status     string    `json:"status"`
-	Method     string    `json:"method"`
-	CustomerID string    `json:"customer_id"`
-	TraceID    string    `json:"trace_id"`
-	CreatedAt  time.Time `json:"created_at"`
-}
+// Events published by this service. Their schemas live in the shared
+// event-contracts module.
+type (
+	PaymentCreatedEvent       = contracts.PaymentCreatedEvent
+	WalletDebitRequestedEvent = contracts.WalletDebitRequestedEvent
+)
 
-type WalletDebitRequestedEvent struct {
-	PaymentID string  `json:"payment_id"`
-	UserID    string  `json:"user_id"`
-	Amount    float64 `json:"amount"`
-	Reason    string  `json:"reason"`
-	TraceID   string  `json:"trace_id"`
-}
+// ServiceName identifies this service in dead-lettered messages.
+const ServiceName = "payment-service"
 
 type DLQMessage struct {
 	OriginalTopic string    `json:"original_topic"`
 	Key           string    `json:"key"`
+	TraceID       string    `json:"trace_id,omitempty"`
 	Value         string    `json:"value"`
+	ValueEncoding string    `json:"value_encoding,omitempty"`
 	Timestamp     time.Time `json:"timestamp"`
 	Attempts      int       `json:"attempts"`
+	Error         string    `json:"error,omitempty"`
+	ErrorClass    string    `json:"error_class,omitempty"`
+
+	// Where the message failed: the producing service, its consumer group and
+	// the partition and offset it was first read from on OriginalTopic.
+	Service       string            `json:"service,omitempty"`
+	ConsumerGroup string            `json:"consumer_group,omitempty"`
+	Partition     int               `json:"partition"`
+	Offset        int64             `json:"offset"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+// MessageKey keeps the key of the original message.
+func (m DLQMessage) MessageKey() string {
+	return m.Key
+}
+
+// DLQValueBase64 is the DLQMessage.ValueEncoding of values that are not
+// text, e.g. protobuf encoded events.
+const DLQValueBase64 = "base64"
+
+// SetValue stores the value of the failed message, base64 encoded unless it
+// is UTF-8 text that survives the JSON encoding of the DLQ message.
+func (m *DLQMessage) SetValue(value []byte) {
+	if utf8.Valid(value) {
+		m.Value, m.ValueEncoding = string(value), ""
+		return
+	}
+	m.Value, m.ValueEncoding = base64.StdEncoding.EncodeToString(value), DLQValueBase64
+}
+
+// RawValue returns the value of the failed message as it was consumed.
+func (m DLQMessage) RawValue() ([]byte, error) {
+	if m.ValueEncoding == DLQValueBase64 {
+		return base64.StdEncoding.DecodeString(m.Value)
+	}
+	return []byte(m.Value), nil
 }
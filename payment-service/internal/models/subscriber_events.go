@@ -1,27 +1,21 @@
 package models
 
-import "time"
+import contracts "github.com/jeffleon2/draftea-event-contracts"
 
 const (
 	FraudTopic2Subscribe  string = "payments.checked"
 	WalletTopic2Subscribe string = "wallet.funds.verified"
 
-	PaymentStatusApproved = "APPROVED"
-	PaymentStatusDeclined = "DECLINED"
+	PaymentStatusApproved = contracts.FraudStatusApproved
+	PaymentStatusDeclined = contracts.FraudStatusDeclined
+	// PaymentStatusReview is sent by fraud-service when a payment needs manual review,
+	// e.g. a possible sanctions list match. The payment is kept PENDING.
+	PaymentStatusReview = contracts.FraudStatusReview
 )
 
-type FraudCheckEvent struct {
-	ID        string    `json:"id"`
-	TraceID   string    `json:"trace_id"`
-	Status    string    `json:"status"`
-	Reason    string    `json:"reason,omitempty"`
-	CheckedAt time.Time `json:"checked_at"`
-}
-
-type WalletResponseEvent struct {
-	PaymentID string  `json:"payment_id"`
-	UserID    string  `json:"user_id"`
-	Status    string  `json:"status"`
-	Amount    float64 `json:"amount"`
-	Reason    string  `json:"reason"`
-}
+// Events consumed by this service. Their schemas live in the shared
+// event-contracts module.
+type (
+	FraudCheckEvent     = contracts.FraudCheckEvent
+	WalletResponseEvent = contracts.WalletResponseEvent
+)
@@ -0,0 +1,154 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
+	"github.com/jeffleon2/draftea-payment-service/internal/logging"
+	"github.com/jeffleon2/draftea-payment-service/internal/metrics"
+	"github.com/jeffleon2/draftea-payment-service/internal/tracing"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// NATSPublisher publishes to JetStream with the same encoding as
+// KafkaPublisher: topics are subjects and every publish waits for the
+// stream's acknowledgement.
+type NATSPublisher struct {
+	JetStream   jetstream.JetStream
+	RetryConfig config.RetryConfig
+	// Serializer encodes events; JSON unless configured otherwise.
+	Serializer contracts.Serializer
+	// Breaker stops publish attempts while NATS keeps failing.
+	Breaker *breaker.Breaker
+}
+
+func NewNATSPublisher(
+	js jetstream.JetStream,
+	retryConfig config.RetryConfig,
+	publisherConfig config.PublisherConfig,
+	cb *breaker.Breaker,
+) *NATSPublisher {
+	if retryConfig.MaxAttempts == 0 {
+		retryConfig.MaxAttempts = 5
+	}
+	if retryConfig.BaseDelay == 0 {
+		retryConfig.BaseDelay = 100 * time.Millisecond
+	}
+	if retryConfig.MaxDelay == 0 {
+		retryConfig.MaxDelay = 10 * time.Second
+	}
+
+	p := &NATSPublisher{
+		JetStream:   js,
+		RetryConfig: retryConfig,
+		Breaker:     cb,
+		Serializer:  publisherConfig.Serializer,
+	}
+	if p.Serializer == nil {
+		p.Serializer = contracts.JSON
+	}
+	return p
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, topic string, message interface{}) error {
+	encoded, err := encode(ctx, p.Serializer, message)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(topic)
+	msg.Data = encoded.value
+	if len(encoded.key) > 0 {
+		msg.Header.Set(contracts.HeaderMessageKey, string(encoded.key))
+	}
+	if encoded.contentType != "" {
+		msg.Header.Set(contracts.HeaderContentType, encoded.contentType)
+	}
+	if encoded.traceID != "" {
+		msg.Header.Set(contracts.HeaderTraceID, encoded.traceID)
+	}
+
+	return p.publishWithRetry(ctx, msg)
+}
+
+// Allow returns breaker.ErrOpen while the NATS breaker is open, when
+// Publish would fail without trying.
+func (p *NATSPublisher) Allow() error {
+	return p.Breaker.Allow()
+}
+
+// Close drains the NATS connection and closes it. Every publish already
+// waited for its acknowledgement, so there is nothing else to flush.
+func (p *NATSPublisher) Close() error {
+	return p.JetStream.Conn().Drain()
+}
+
+// publishWithRetry publishes msg with exponential backoff, up to MaxAttempts
+// attempts, in a producer span whose context goes in the headers of msg.
+func (p *NATSPublisher) publishWithRetry(ctx context.Context, msg *nats.Msg) (err error) {
+	ctx, span := tracing.StartProducer(ctx, tracing.SystemNATS, msg.Subject)
+	defer func() { tracing.End(span, err) }()
+	tracing.Inject(ctx, propagation.HeaderCarrier(msg.Header))
+
+	start := time.Now()
+	var lastErr error
+
+	for attempt := 0; attempt < p.RetryConfig.MaxAttempts; attempt++ {
+		err := p.Breaker.Execute(func() error {
+			_, err := p.JetStream.PublishMsg(ctx, msg)
+			return err
+		})
+		if errors.Is(err, breaker.ErrOpen) {
+			p.record(msg.Subject, start, err)
+			return fmt.Errorf("failed to publish message to topic '%s': %w", msg.Subject, err)
+		}
+		if err == nil {
+			p.record(msg.Subject, start, nil)
+			if attempt > 0 {
+				logging.FromContext(ctx).WithField(logging.FieldTopic, msg.Subject).Infof("Message published after %d attempts", attempt+1)
+			}
+			return nil
+		}
+
+		lastErr = err
+
+		if attempt == p.RetryConfig.MaxAttempts-1 {
+			break
+		}
+
+		delay := calculateBackoff(p.RetryConfig, attempt)
+
+		logging.FromContext(ctx).WithField(logging.FieldTopic, msg.Subject).WithError(err).Warnf("Publish retry %d/%d in %v",
+			attempt+1, p.RetryConfig.MaxAttempts, delay)
+
+		select {
+		case <-time.After(delay):
+			continue
+		case <-ctx.Done():
+			p.record(msg.Subject, start, ctx.Err())
+			return fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+		}
+	}
+
+	p.record(msg.Subject, start, lastErr)
+	return fmt.Errorf("failed to publish message to topic '%s' after %d attempts: %w",
+		msg.Subject, p.RetryConfig.MaxAttempts, lastErr)
+}
+
+// record updates the same delivery metrics as the Kafka writers.
+func (p *NATSPublisher) record(topic string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	metrics.PublishedMessagesTotal.WithLabelValues(topic, result).Inc()
+	metrics.PublishDuration.WithLabelValues(topic, result).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,110 @@
+package posgrest
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type txKey struct{}
+
+// conn returns the transaction stored in ctx by the inbox or
+// repository.Transaction, or db outside one.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// forUpdate locks the rows db selects until the transaction of ctx ends.
+// Outside a transaction it returns db unchanged.
+func forUpdate(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})
+	}
+	return db
+}
+
+// inbox records processed events in the processed_events table.
+type inbox struct {
+	db      *gorm.DB
+	breaker *breaker.Breaker
+}
+
+// NewInbox creates the inbox of consumed events. Recording an event goes
+// through the circuit breaker cb.
+func NewInbox(db *gorm.DB, cb *breaker.Breaker) *inbox {
+	return &inbox{
+		db,
+		cb,
+	}
+}
+
+// ProcessOnce runs fn in a transaction that first records eventID. If the
+// event was already recorded it returns false without calling fn. Repository
+// calls made with the context given to fn join the transaction, so the event
+// is only recorded when fn succeeds. GetByID locks the row it reads, so
+// concurrent events of the same entity update it one after the other.
+func (i *inbox) ProcessOnce(ctx context.Context, eventID string, fn func(ctx context.Context) error) (bool, error) {
+	processed := false
+	err := i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inserted int64
+		err := i.breaker.Execute(func() error {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.ProcessedEvent{ID: eventID, ProcessedAt: time.Now().UTC()})
+			inserted = result.RowsAffected
+			return result.Error
+		})
+		if err != nil || inserted == 0 {
+			return err
+		}
+
+		processed = true
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	if err != nil {
+		return false, err
+	}
+	return processed, nil
+}
+
+// Cleanup deletes the events processed before cutoff and returns how many.
+func (i *inbox) Cleanup(ctx context.Context, cutoff time.Time) (int64, error) {
+	var deleted int64
+	err := i.breaker.Execute(func() error {
+		result := i.db.WithContext(ctx).
+			Where("processed_at < ?", cutoff).
+			Delete(&models.ProcessedEvent{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// CleanupEvery deletes the events older than ttl every interval until ctx is done.
+func (i *inbox) CleanupEvery(ctx context.Context, ttl, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := i.Cleanup(ctx, time.Now().UTC().Add(-ttl))
+			if err != nil {
+				logrus.WithError(err).Error("Failed to clean up processed events")
+				continue
+			}
+			if deleted > 0 {
+				logrus.Infof("Deleted %d processed events older than %v", deleted, ttl)
+			}
+		}
+	}
+}
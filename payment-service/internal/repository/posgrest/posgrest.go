@@ -2,43 +2,73 @@ package posgrest
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
 	"gorm.io/gorm"
 )
 
 // repository is a generic GORM-based repository implementation.
 // It provides standard CRUD operations for any entity type T.
 type repository[T interface{}] struct {
-	db *gorm.DB
+	db      *gorm.DB
+	breaker *breaker.Breaker
 }
 
 // New creates a new generic repository instance for type T.
-// The repository uses the provided GORM database connection for all operations.
-func New[T interface{}](db *gorm.DB) *repository[T] {
+// The repository uses the provided GORM database connection for all operations,
+// each one guarded by the circuit breaker cb.
+func New[T interface{}](db *gorm.DB, cb *breaker.Breaker) *repository[T] {
 	return &repository[T]{
 		db,
+		cb,
 	}
 }
 
+// IsFailure reports whether err means Postgres is failing. Missing records
+// and cancelled requests do not count against the circuit breaker: the first
+// is an answer from a healthy database and the second is the caller giving
+// up, so neither should open it and reject every other request.
+func IsFailure(err error) bool {
+	return !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, context.Canceled)
+}
+
+// Transaction runs fn in a database transaction. Repository calls made with
+// the context given to fn join it, and GetByID locks the rows it reads until
+// the transaction ends, as inside an inbox transaction.
+func (r *repository[T]) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
 // Create inserts a new entity into the database.
 func (r *repository[T]) Create(ctx context.Context, entity *T) error {
-	return r.db.WithContext(ctx).Create(&entity).Error
+	return r.breaker.Execute(func() error {
+		return conn(ctx, r.db).Create(&entity).Error
+	})
 }
 
 // GetAll retrieves all entities of type T from the database.
 func (r *repository[T]) GetAll(ctx context.Context) (*[]T, error) {
 	var entities []T
-	err := r.db.WithContext(ctx).Find(&entities).Error
+	err := r.breaker.Execute(func() error {
+		return conn(ctx, r.db).Find(&entities).Error
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &entities, nil
 }
 
-// GetByID retrieves a single entity by its ID.
+// GetByID retrieves a single entity by its ID. Inside a transaction (see
+// Transaction and inbox.ProcessOnce) the row stays locked until it ends.
 func (r *repository[T]) GetByID(ctx context.Context, id string) (*T, error) {
 	var entity T
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&entity).Error; err != nil {
+	err := r.breaker.Execute(func() error {
+		return forUpdate(ctx, conn(ctx, r.db)).Where("id = ?", id).First(&entity).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &entity, nil
@@ -48,7 +78,10 @@ func (r *repository[T]) GetByID(ctx context.Context, id string) (*T, error) {
 // The key parameter is the field name, and value is the value to match.
 func (r *repository[T]) GetBy(ctx context.Context, key string, value interface{}) (*[]T, error) {
 	var entity []T
-	if err := r.db.WithContext(ctx).Where(key, value).Find(&entity).Error; err != nil {
+	err := r.breaker.Execute(func() error {
+		return conn(ctx, r.db).Where(key, value).Find(&entity).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &entity, nil
@@ -56,11 +89,15 @@ func (r *repository[T]) GetBy(ctx context.Context, key string, value interface{}
 
 // Update updates an existing entity identified by ID.
 func (r *repository[T]) Update(ctx context.Context, entity *T, id string) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Updates(entity).Error
+	return r.breaker.Execute(func() error {
+		return conn(ctx, r.db).Where("id = ?", id).Updates(entity).Error
+	})
 }
 
 // Delete removes an entity by its ID.
 func (r *repository[T]) Delete(ctx context.Context, id string) error {
 	var entity T
-	return r.db.WithContext(ctx).Delete(&entity, id).Error
+	return r.breaker.Execute(func() error {
+		return conn(ctx, r.db).Delete(&entity, id).Error
+	})
 }
@@ -0,0 +1,112 @@
+package posgrest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/jeffleon2/draftea-payment-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGetByIDLocksTheRowInsideTheInboxTransaction(t *testing.T) {
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		queries = append(queries, tx.Statement.SQL.String())
+	}))
+	repo := New[models.Payment](db, breaker.New("postgres-test", config.CircuitBreaker{}))
+
+	_, _ = repo.GetByID(context.Background(), "pay_1")
+	_, _ = repo.GetByID(context.WithValue(context.Background(), txKey{}, db), "pay_1")
+
+	require.Len(t, queries, 2)
+	assert.NotContains(t, queries[0], "FOR UPDATE")
+	assert.Contains(t, queries[1], "FOR UPDATE")
+}
+
+// openDB opens an in-memory SQLite database with a single connection, so a
+// transaction keeps every other statement waiting as a row lock would.
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.Payment{}, &models.ProcessedEvent{}))
+	return db
+}
+
+// recordingPublisher records the topics published to.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	topics []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, message interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.topics = append(p.topics, topic)
+	return nil
+}
+
+type reviewKey struct{}
+
+func TestResolveReviewDoesNotOverwriteAConcurrentEvent(t *testing.T) {
+	db := openDB(t)
+	cb := breaker.New("postgres-test", config.CircuitBreaker{})
+	publisher := &recordingPublisher{}
+	payments := service.NewPaymentService(New[models.Payment](db, cb), publisher, NewInbox(db, cb))
+	ctx := context.Background()
+	require.NoError(t, payments.Repo.Create(ctx, &models.Payment{ID: "pay_1", Status: models.StatusReview, CustomerID: "user_1", Amount: 50}))
+
+	// Once the review has read the payment, the wallet approval arrives. It
+	// has to wait for the review to commit instead of being overwritten.
+	var event sync.WaitGroup
+	var once sync.Once
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:concurrent_event", func(tx *gorm.DB) {
+		if tx.Statement.Context.Value(reviewKey{}) == nil {
+			return
+		}
+		once.Do(func() {
+			applied := make(chan struct{})
+			event.Add(1)
+			go func() {
+				defer event.Done()
+				defer close(applied)
+				approved := true
+				assert.NoError(t, payments.UpdatePaymentFlags(ctx, "evt_1", "pay_1", &approved, nil, ""))
+			}()
+			select {
+			case <-applied:
+			case <-time.After(200 * time.Millisecond):
+			}
+		})
+	}))
+
+	_, err := payments.ResolveReview(context.WithValue(ctx, reviewKey{}, true), "pay_1", true, "")
+	require.NoError(t, err)
+	event.Wait()
+
+	payment, err := payments.Repo.GetByID(ctx, "pay_1")
+	require.NoError(t, err)
+	assert.True(t, payment.FraudCleared)
+	assert.True(t, payment.WalletApproved)
+	assert.Equal(t, models.StatusAuthorized, payment.Status)
+	assert.Equal(t, []string{models.WalletDebitEventTopic}, publisher.topics)
+}
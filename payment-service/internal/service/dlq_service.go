@@ -0,0 +1,150 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+)
+
+const (
+	defaultDLQPageSize = 50
+	// defaultDLQReadLimit bounds the messages read per partition when no limit is set.
+	defaultDLQReadLimit = 1000
+)
+
+// ErrUnknownDLQTopic is returned for topics that are not configured as DLQs.
+var ErrUnknownDLQTopic = errors.New("unknown DLQ topic")
+
+// DLQReader defines the interface for reading the contents of a DLQ topic.
+type DLQReader interface {
+	// ReadRecent returns at most limit of the newest messages of each
+	// partition of topic and whether older ones were left out.
+	ReadRecent(ctx context.Context, topic string, limit int) ([]models.DLQEntry, bool, error)
+}
+
+// DLQService lets on-call browse the dead-lettered messages of the configured DLQ topics.
+// Each request reads at most ReadLimit messages per partition, the newest ones.
+type DLQService struct {
+	Reader    DLQReader
+	Topics    []string
+	ReadLimit int
+}
+
+// NewDLQService creates a DLQService restricted to the given DLQ topics that
+// reads at most readLimit messages per partition.
+func NewDLQService(reader DLQReader, topics []string, readLimit int) *DLQService {
+	if readLimit <= 0 {
+		readLimit = defaultDLQReadLimit
+	}
+
+	return &DLQService{
+		Reader:    reader,
+		Topics:    topics,
+		ReadLimit: readLimit,
+	}
+}
+
+// ListMessages returns a page of the messages of query.Topic matching the
+// query filters, newest first.
+func (s *DLQService) ListMessages(ctx context.Context, query models.DLQQuery) (*models.DLQPage, error) {
+	entries, truncated, err := s.read(ctx, query.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := slices.DeleteFunc(entries, func(e models.DLQEntry) bool {
+		return (query.OriginalTopic != "" && e.Message.OriginalTopic != query.OriginalTopic) ||
+			(query.ErrorClass != "" && e.Message.ErrorClass != query.ErrorClass) ||
+			(query.Key != "" && e.Message.Key != query.Key)
+	})
+	slices.SortStableFunc(matched, func(a, b models.DLQEntry) int {
+		if c := b.Message.Timestamp.Compare(a.Message.Timestamp); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Partition, b.Partition); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Offset, a.Offset)
+	})
+
+	page := max(query.Page, 1)
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultDLQPageSize
+	}
+
+	start := min((page-1)*pageSize, len(matched))
+	end := min(start+pageSize, len(matched))
+
+	return &models.DLQPage{
+		Topic:     query.Topic,
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     len(matched),
+		Truncated: truncated,
+		Entries:   matched[start:end],
+	}, nil
+}
+
+// Summary counts the messages of a DLQ topic per original topic, error class
+// and error, the most frequent errors first.
+func (s *DLQService) Summary(ctx context.Context, topic string) (*models.DLQSummary, error) {
+	entries, truncated, err := s.read(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.DLQSummary{
+		Topic:           topic,
+		Total:           len(entries),
+		Truncated:       truncated,
+		ByOriginalTopic: map[string]int{},
+		ByErrorClass:    map[string]int{},
+		Errors:          []models.DLQErrorCount{},
+	}
+
+	type errorKey struct{ topic, err string }
+	errorIndex := map[errorKey]int{}
+	for _, e := range entries {
+		if e.Raw != "" {
+			summary.Invalid++
+			continue
+		}
+
+		msg := e.Message
+		summary.ByOriginalTopic[msg.OriginalTopic]++
+		summary.ByErrorClass[msg.ErrorClass]++
+
+		key := errorKey{msg.OriginalTopic, msg.Error}
+		i, ok := errorIndex[key]
+		if !ok {
+			i = len(summary.Errors)
+			errorIndex[key] = i
+			summary.Errors = append(summary.Errors, models.DLQErrorCount{
+				OriginalTopic: msg.OriginalTopic,
+				Error:         msg.Error,
+				ErrorClass:    msg.ErrorClass,
+			})
+		}
+		summary.Errors[i].Count++
+		if msg.Timestamp.After(summary.Errors[i].LastSeen) {
+			summary.Errors[i].LastSeen = msg.Timestamp
+		}
+	}
+
+	slices.SortStableFunc(summary.Errors, func(a, b models.DLQErrorCount) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+
+	return summary, nil
+}
+
+func (s *DLQService) read(ctx context.Context, topic string) ([]models.DLQEntry, bool, error) {
+	if !slices.Contains(s.Topics, topic) {
+		return nil, false, ErrUnknownDLQTopic
+	}
+	return s.Reader.ReadRecent(ctx, topic, s.ReadLimit)
+}
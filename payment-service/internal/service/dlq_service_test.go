@@ -0,0 +1,155 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/jeffleon2/draftea-payment-service/internal/service"
+	"github.com/jeffleon2/draftea-payment-service/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dlqTopics = []string{models.PaymentsDLQTopic, "wallet.dlq"}
+
+func dlqEntries() []models.DLQEntry {
+	base := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	entry := func(offset int64, topic, key, err, class string) models.DLQEntry {
+		return models.DLQEntry{
+			Offset: offset,
+			Message: models.DLQMessage{
+				OriginalTopic: topic,
+				Key:           key,
+				Timestamp:     base.Add(time.Duration(offset) * time.Minute),
+				Error:         err,
+				ErrorClass:    class,
+			},
+		}
+	}
+
+	return []models.DLQEntry{
+		entry(0, "payments.checked", "payment-1", "database timeout", "transient"),
+		entry(1, "wallet.funds.verified", "payment-2", "database timeout", "transient"),
+		entry(2, "payments.checked", "payment-3", "database timeout", "transient"),
+		entry(3, "payments.checked", "payment-4", "invalid character 'x'", "permanent"),
+		{Offset: 4, Raw: "not json"},
+	}
+}
+
+func TestDLQListMessages_FiltersAndPaginatesNewestFirst(t *testing.T) {
+	mockReader := mocks.NewMockDLQReader(t)
+	dlqService := service.NewDLQService(mockReader, dlqTopics, 100)
+	ctx := context.Background()
+
+	mockReader.EXPECT().
+		ReadRecent(ctx, models.PaymentsDLQTopic, 100).
+		Return(dlqEntries(), false, nil).
+		Once()
+
+	page, err := dlqService.ListMessages(ctx, models.DLQQuery{
+		Topic:         models.PaymentsDLQTopic,
+		OriginalTopic: "payments.checked",
+		Page:          1,
+		PageSize:      2,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Entries, 2)
+	assert.Equal(t, "payment-4", page.Entries[0].Message.Key)
+	assert.Equal(t, "payment-3", page.Entries[1].Message.Key)
+}
+
+func TestDLQListMessages_PageOutOfRange(t *testing.T) {
+	mockReader := mocks.NewMockDLQReader(t)
+	dlqService := service.NewDLQService(mockReader, dlqTopics, 100)
+	ctx := context.Background()
+
+	mockReader.EXPECT().
+		ReadRecent(ctx, models.PaymentsDLQTopic, 100).
+		Return(dlqEntries(), false, nil).
+		Once()
+
+	page, err := dlqService.ListMessages(ctx, models.DLQQuery{Topic: models.PaymentsDLQTopic, Page: 3})
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+	assert.Equal(t, 50, page.PageSize)
+	assert.Empty(t, page.Entries)
+}
+
+func TestDLQListMessages_UnknownTopic(t *testing.T) {
+	mockReader := mocks.NewMockDLQReader(t)
+	dlqService := service.NewDLQService(mockReader, dlqTopics, 100)
+
+	_, err := dlqService.ListMessages(context.Background(), models.DLQQuery{Topic: "payments.created"})
+
+	assert.ErrorIs(t, err, service.ErrUnknownDLQTopic)
+}
+
+func TestDLQSummary_CountsPerTopicAndError(t *testing.T) {
+	mockReader := mocks.NewMockDLQReader(t)
+	dlqService := service.NewDLQService(mockReader, dlqTopics, 100)
+	ctx := context.Background()
+
+	mockReader.EXPECT().
+		ReadRecent(ctx, models.PaymentsDLQTopic, 100).
+		Return(dlqEntries(), false, nil).
+		Once()
+
+	summary, err := dlqService.Summary(ctx, models.PaymentsDLQTopic)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, summary.Total)
+	assert.Equal(t, 1, summary.Invalid)
+	assert.Equal(t, map[string]int{"payments.checked": 3, "wallet.funds.verified": 1}, summary.ByOriginalTopic)
+	assert.Equal(t, map[string]int{"transient": 3, "permanent": 1}, summary.ByErrorClass)
+
+	require.Len(t, summary.Errors, 3)
+	assert.Equal(t, "payments.checked", summary.Errors[0].OriginalTopic)
+	assert.Equal(t, "database timeout", summary.Errors[0].Error)
+	assert.Equal(t, 2, summary.Errors[0].Count)
+	assert.Equal(t, time.Date(2025, 1, 15, 12, 2, 0, 0, time.UTC), summary.Errors[0].LastSeen)
+}
+
+func TestDLQSummary_ReaderError(t *testing.T) {
+	mockReader := mocks.NewMockDLQReader(t)
+	dlqService := service.NewDLQService(mockReader, dlqTopics, 100)
+	ctx := context.Background()
+
+	mockReader.EXPECT().
+		ReadRecent(ctx, "wallet.dlq", 100).
+		Return(nil, false, errors.New("broker unavailable")).
+		Once()
+
+	summary, err := dlqService.Summary(ctx, "wallet.dlq")
+
+	assert.Error(t, err)
+	assert.Nil(t, summary)
+}
+
+func TestDLQListMessages_ReportsTruncatedReads(t *testing.T) {
+	mockReader := mocks.NewMockDLQReader(t)
+	dlqService := service.NewDLQService(mockReader, dlqTopics, 2)
+	ctx := context.Background()
+
+	mockReader.EXPECT().
+		ReadRecent(ctx, models.PaymentsDLQTopic, 2).
+		Return(dlqEntries()[3:], true, nil).
+		Once()
+
+	page, err := dlqService.ListMessages(ctx, models.DLQQuery{Topic: models.PaymentsDLQTopic})
+
+	require.NoError(t, err)
+	assert.True(t, page.Truncated)
+	assert.Equal(t, 2, page.Total)
+}
+
+func TestNewDLQService_DefaultReadLimit(t *testing.T) {
+	dlqService := service.NewDLQService(mocks.NewMockDLQReader(t), dlqTopics, 0)
+
+	assert.Equal(t, 1000, dlqService.ReadLimit)
+}
@@ -2,16 +2,32 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/internal/logging"
+	"github.com/jeffleon2/draftea-payment-service/internal/metrics"
 	"github.com/jeffleon2/draftea-payment-service/internal/models"
 	"github.com/jeffleon2/draftea-payment-service/internal/models/dto"
+	"github.com/sirupsen/logrus"
 )
 
 var paymentLocks = make(map[string]*sync.Mutex)
 var mu sync.Mutex
 
+// ErrNotInReview is returned when resolving the review of a payment that is
+// not held for review.
+var ErrNotInReview = errors.New("payment is not held for review")
+
+// Outcomes of the manual fraud review counted in metrics.PaymentReviewsTotal.
+const (
+	reviewHeld     = "held"
+	reviewApproved = "approved"
+	reviewDeclined = "declined"
+)
+
 // PaymentRepo defines the interface for payment data persistence operations.
 // Implementations should handle CRUD operations for payment entities.
 type PaymentRepo interface {
@@ -27,20 +43,44 @@ type Publisher interface {
 	Publish(ctx context.Context, topic string, message interface{}) error
 }
 
+// PublishGate is implemented by publishers that know, before anything is
+// written, that a publish would be rejected, such as a publisher whose
+// circuit breaker is open.
+type PublishGate interface {
+	Allow() error
+}
+
+// Transactor is implemented by repositories that can run several calls in
+// one transaction. Repository calls made with the context given to fn join
+// it, and GetByID locks the row it reads until it ends.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Inbox records consumed events so redelivered ones are skipped.
+type Inbox interface {
+	// ProcessOnce runs fn in a transaction that also records eventID, and
+	// returns false without calling fn if eventID was already processed.
+	ProcessOnce(ctx context.Context, eventID string, fn func(ctx context.Context) error) (bool, error)
+}
+
 // PaymentService orchestrates payment processing workflows.
 // It coordinates between fraud detection, wallet verification, and payment authorization,
 // implementing a saga pattern with parallel verification of fraud and funds.
 type PaymentService struct {
 	Repo      PaymentRepo
 	Publisher Publisher
+	Inbox     Inbox
 }
 
-// NewPaymentService creates a new PaymentService with the provided repository and publisher.
-// The service uses the repository for payment persistence and the publisher for event-driven communication.
-func NewPaymentService(repo PaymentRepo, publisher Publisher) *PaymentService {
+// NewPaymentService creates a new PaymentService with the provided repository, publisher and inbox.
+// The service uses the repository for payment persistence, the publisher for event-driven communication
+// and the inbox to apply each consumed event only once.
+func NewPaymentService(repo PaymentRepo, publisher Publisher, inbox Inbox) *PaymentService {
 	return &PaymentService{
 		Repo:      repo,
 		Publisher: publisher,
+		Inbox:     inbox,
 	}
 }
 
@@ -49,6 +89,8 @@ func NewPaymentService(repo PaymentRepo, publisher Publisher) *PaymentService {
 // and publishes a payments.created event to trigger parallel fraud and funds verification.
 //
 // The payment starts with both fraud_checked and funds_verified flags set to false.
+// Nothing is stored while the publisher rejects publishes (see PublishGate).
+// It stores the trace ID of ctx, or a new one, and the whole flow carries it.
 // These flags will be updated by the fraud and wallet services asynchronously.
 func (s *PaymentService) CreatePayment(ctx context.Context, paymentDTO *dto.Payment) error {
 	paymentDTO.Sanitize()
@@ -57,19 +99,39 @@ func (s *PaymentService) CreatePayment(ctx context.Context, paymentDTO *dto.Paym
 		return err
 	}
 
+	// Requests that did not go through the HTTP middleware start a new trace.
+	payment.TraceID = contracts.TraceID(ctx)
+	if payment.TraceID == "" {
+		payment.TraceID = contracts.NewTraceID()
+	}
+
+	// A payment stored without its payments.created event would stay
+	// PENDING forever, so do not store it while the event cannot be sent.
+	if gate, ok := s.Publisher.(PublishGate); ok {
+		if err := gate.Allow(); err != nil {
+			return fmt.Errorf("error publishing %s: %w", models.PaymentCreatedEventTopic, err)
+		}
+	}
+
 	if err := s.Repo.Create(ctx, payment); err != nil {
 		return err
 	}
+	logrus.WithFields(logrus.Fields{
+		logging.FieldTraceID:   payment.TraceID,
+		logging.FieldPaymentID: payment.ID,
+	}).Info("Payment created")
 
 	event := models.PaymentCreatedEvent{
-		ID:         payment.ID,
-		Amount:     payment.Amount,
-		Currency:   string(payment.Currency),
-		Status:     string(payment.Status),
-		Method:     string(payment.Method),
-		CustomerID: payment.CustomerID,
-		TraceID:    payment.TraceID,
-		CreatedAt:  payment.CreatedAt,
+		ID:              payment.ID,
+		Amount:          payment.Amount,
+		Currency:        string(payment.Currency),
+		Status:          string(payment.Status),
+		Method:          string(payment.Method),
+		CustomerID:      payment.CustomerID,
+		CustomerName:    payment.CustomerName,
+		CustomerCountry: payment.CustomerCountry,
+		TraceID:         payment.TraceID,
+		CreatedAt:       payment.CreatedAt,
 	}
 
 	return s.Publisher.Publish(ctx, models.PaymentCreatedEventTopic, event)
@@ -79,13 +141,43 @@ func (s *PaymentService) CreatePayment(ctx context.Context, paymentDTO *dto.Paym
 // This method is called by event handlers when receiving fraud check or wallet verification results.
 //
 // Parameters:
+//   - eventID: envelope ID of the consumed event; "" disables deduplication
 //   - walletApproved: pointer to bool indicating wallet funds verification result (nil if not updating)
 //   - fraudClean: pointer to bool indicating fraud check result (nil if not updating)
 //   - failureReason: reason for failure if either check declined the payment
 //
 // If either check fails, the payment status is immediately set to FAILED.
 // If both checks pass, CompletePaymentIfReady is called to authorize the payment and trigger wallet debit.
+//
+// The whole update runs in the inbox transaction that records eventID, so a
+// redelivered event is acknowledged without side effects, and a failed
+// update, including its wallet.debit.requested publish, leaves no record.
 func (s *PaymentService) UpdatePaymentFlags(
+	ctx context.Context,
+	eventID string,
+	paymentID string,
+	walletApproved *bool,
+	fraudClean *bool,
+	failureReason string,
+) error {
+	if eventID == "" {
+		return s.updatePaymentFlags(ctx, paymentID, walletApproved, fraudClean, failureReason)
+	}
+
+	processed, err := s.Inbox.ProcessOnce(ctx, eventID, func(ctx context.Context) error {
+		return s.updatePaymentFlags(ctx, paymentID, walletApproved, fraudClean, failureReason)
+	})
+	if err != nil {
+		return err
+	}
+	if !processed {
+		logging.FromContext(ctx).WithField(logging.FieldPaymentID, paymentID).Infof("Skipping already processed event %s", eventID)
+		metrics.InboxDuplicatesTotal.Inc()
+	}
+	return nil
+}
+
+func (s *PaymentService) updatePaymentFlags(
 	ctx context.Context,
 	paymentID string,
 	walletApproved *bool,
@@ -98,8 +190,6 @@ func (s *PaymentService) UpdatePaymentFlags(
 		return fmt.Errorf("payment not found: %w", err)
 	}
 
-	fmt.Println("Payment ", payment)
-
 	if walletApproved != nil {
 		if !*walletApproved {
 			payment.FailedReason = failureReason
@@ -119,7 +209,7 @@ func (s *PaymentService) UpdatePaymentFlags(
 		return err
 	}
 
-	fmt.Println("Update successfully flags")
+	logging.FromContext(ctx).WithField(logging.FieldPaymentID, paymentID).Infof("Payment flags updated, status %s", payment.Status)
 
 	return s.CompletePaymentIfReady(ctx, payment)
 }
@@ -148,6 +238,7 @@ func (s *PaymentService) CompletePaymentIfReady(ctx context.Context, payment *mo
 	if err := s.Repo.Update(ctx, payment, payment.ID); err != nil {
 		return err
 	}
+	logging.FromContext(ctx).WithField(logging.FieldPaymentID, payment.ID).Info("Payment authorized")
 
 	event := models.WalletDebitRequestedEvent{
 		PaymentID: payment.ID,
@@ -160,6 +251,141 @@ func (s *PaymentService) CompletePaymentIfReady(ctx context.Context, payment *mo
 	return s.Publisher.Publish(ctx, models.WalletDebitEventTopic, event)
 }
 
+// HoldForReview moves a PENDING payment to REVIEW after fraud-service asked
+// for a manual review. Like UpdatePaymentFlags it runs in the inbox
+// transaction of eventID. A payment that already failed is left as is.
+func (s *PaymentService) HoldForReview(ctx context.Context, eventID, paymentID, reason string) error {
+	if eventID == "" {
+		return s.holdForReview(ctx, paymentID, reason)
+	}
+
+	processed, err := s.Inbox.ProcessOnce(ctx, eventID, func(ctx context.Context) error {
+		return s.holdForReview(ctx, paymentID, reason)
+	})
+	if err != nil {
+		return err
+	}
+	if !processed {
+		logging.FromContext(ctx).WithField(logging.FieldPaymentID, paymentID).Infof("Skipping already processed event %s", eventID)
+		metrics.InboxDuplicatesTotal.Inc()
+	}
+	return nil
+}
+
+func (s *PaymentService) holdForReview(ctx context.Context, paymentID, reason string) error {
+	payment, err := s.Repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return fmt.Errorf("payment not found: %w", err)
+	}
+
+	logger := logging.FromContext(ctx).WithField(logging.FieldPaymentID, paymentID)
+	if payment.Status != models.StatusPending {
+		logger.Infof("Payment is %s, not holding it for review", payment.Status)
+		return nil
+	}
+
+	payment.Status = models.StatusReview
+	payment.ReviewReason = reason
+	if err := s.Repo.Update(ctx, payment, paymentID); err != nil {
+		return err
+	}
+
+	logger.Warnf("Payment held for manual fraud review: %s", reason)
+	metrics.PaymentReviewsTotal.WithLabelValues(reviewHeld).Inc()
+	return nil
+}
+
+// ResolveReview ends the manual review of a payment held in REVIEW.
+// Approving it clears the fraud check and authorizes the payment once the
+// wallet has approved it too; declining it fails the payment with reason.
+// It returns ErrNotInReview if the payment is not held for review.
+//
+// When the repository is a Transactor the review runs in one transaction that
+// locks the payment, so an event updating it meanwhile is applied before or
+// after the review instead of being overwritten by it.
+func (s *PaymentService) ResolveReview(ctx context.Context, paymentID string, approve bool, reason string) (*models.Payment, error) {
+	var payment *models.Payment
+	err := s.transaction(ctx, func(ctx context.Context) error {
+		var err error
+		payment, err = s.resolveReview(ctx, paymentID, approve, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := reviewDeclined
+	if approve {
+		outcome = reviewApproved
+	}
+	logging.FromContext(ctx).WithField(logging.FieldPaymentID, paymentID).Infof("Manual fraud review %s", outcome)
+	metrics.PaymentReviewsTotal.WithLabelValues(outcome).Inc()
+	return payment, nil
+}
+
+func (s *PaymentService) resolveReview(ctx context.Context, paymentID string, approve bool, reason string) (*models.Payment, error) {
+	payment, err := s.Repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("payment not found: %w", err)
+	}
+	if payment.Status != models.StatusReview {
+		return nil, fmt.Errorf("%w: payment %s is %s", ErrNotInReview, paymentID, payment.Status)
+	}
+
+	if approve {
+		payment.Status = models.StatusPending
+		payment.FraudCleared = true
+	} else {
+		if reason == "" {
+			reason = "declined in manual fraud review"
+		}
+		payment.Status = models.StatusFailed
+		payment.FailedReason = reason
+	}
+	if err := s.Repo.Update(ctx, payment, paymentID); err != nil {
+		return nil, err
+	}
+
+	if approve {
+		if err := s.CompletePaymentIfReady(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+	return payment, nil
+}
+
+// FailUndelivered fails a PENDING payment whose payments.created event could
+// not be delivered, so it does not wait forever for checks that will never
+// run. Payments that already moved on are left as is.
+func (s *PaymentService) FailUndelivered(ctx context.Context, paymentID string, cause error) error {
+	return s.transaction(ctx, func(ctx context.Context) error {
+		payment, err := s.Repo.GetByID(ctx, paymentID)
+		if err != nil {
+			return fmt.Errorf("payment not found: %w", err)
+		}
+		if payment.Status != models.StatusPending {
+			return nil
+		}
+
+		payment.Status = models.StatusFailed
+		payment.FailedReason = fmt.Sprintf("%s could not be delivered: %v", models.PaymentCreatedEventTopic, cause)
+		if err := s.Repo.Update(ctx, payment, paymentID); err != nil {
+			return err
+		}
+		logging.FromContext(ctx).WithField(logging.FieldPaymentID, paymentID).Warn("Payment failed, its payments.created event was not delivered")
+		return nil
+	})
+}
+
+// transaction runs fn in a transaction of the repository, or directly when
+// the repository is not a Transactor.
+func (s *PaymentService) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if transactor, ok := s.Repo.(Transactor); ok {
+		return transactor.Transaction(ctx, fn)
+	}
+	return fn(ctx)
+}
+
 func getLock(paymentID string) *sync.Mutex {
 	mu.Lock()
 	defer mu.Unlock()
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
 	"github.com/jeffleon2/draftea-payment-service/internal/models"
 	"github.com/jeffleon2/draftea-payment-service/internal/models/dto"
 	"github.com/jeffleon2/draftea-payment-service/internal/service"
@@ -13,10 +16,18 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// matchesContract matches events that pass their schema in event-contracts,
+// so a producer change that would break a consumer fails the build here.
+func matchesContract[T contracts.Event]() interface{} {
+	return mock.MatchedBy(func(event T) bool {
+		return contracts.ValidateEvent(event) == nil
+	})
+}
+
 func TestCreatePayment_Success(t *testing.T) {
 	mockRepo := mocks.NewMockPaymentRepo(t)
 	mockPublisher := mocks.NewMockPublisher(t)
-	paymentService := service.NewPaymentService(mockRepo, mockPublisher)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
 
 	ctx := context.Background()
 	paymentDTO := &dto.Payment{
@@ -28,11 +39,16 @@ func TestCreatePayment_Success(t *testing.T) {
 
 	mockRepo.EXPECT().
 		Create(ctx, mock.AnythingOfType("*models.Payment")).
+		Run(func(ctx context.Context, payment *models.Payment) {
+			// Set by the database on insert.
+			payment.ID = "payment-123"
+			payment.CreatedAt = time.Now()
+		}).
 		Return(nil).
 		Once()
 
 	mockPublisher.EXPECT().
-		Publish(ctx, models.PaymentCreatedEventTopic, mock.AnythingOfType("models.PaymentCreatedEvent")).
+		Publish(ctx, models.PaymentCreatedEventTopic, matchesContract[models.PaymentCreatedEvent]()).
 		Return(nil).
 		Once()
 
@@ -43,10 +59,162 @@ func TestCreatePayment_Success(t *testing.T) {
 	mockPublisher.AssertExpectations(t)
 }
 
+// gatedPublisher is a publisher whose breaker state is err.
+type gatedPublisher struct {
+	*mocks.MockPublisher
+	err error
+}
+
+func (p gatedPublisher) Allow() error { return p.err }
+
+func TestCreatePayment_PublisherUnavailable(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	publisher := gatedPublisher{MockPublisher: mocks.NewMockPublisher(t), err: breaker.ErrOpen}
+	paymentService := service.NewPaymentService(mockRepo, publisher, mocks.NewMockInbox(t))
+
+	err := paymentService.CreatePayment(context.Background(), &dto.Payment{
+		Amount:     100.50,
+		Currency:   "USD",
+		Method:     "CREDIT_CARD",
+		CustomerID: "customer-123",
+	})
+
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreatePayment_PublisherAvailable(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	publisher := gatedPublisher{MockPublisher: mocks.NewMockPublisher(t)}
+	paymentService := service.NewPaymentService(mockRepo, publisher, mocks.NewMockInbox(t))
+
+	ctx := context.Background()
+	mockRepo.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.Payment")).
+		Run(func(ctx context.Context, payment *models.Payment) {
+			payment.ID = "payment-123"
+			payment.CreatedAt = time.Now()
+		}).
+		Return(nil).
+		Once()
+	publisher.EXPECT().
+		Publish(ctx, models.PaymentCreatedEventTopic, matchesContract[models.PaymentCreatedEvent]()).
+		Return(nil).
+		Once()
+
+	err := paymentService.CreatePayment(ctx, &dto.Payment{
+		Amount:     100.50,
+		Currency:   "USD",
+		Method:     "CREDIT_CARD",
+		CustomerID: "customer-123",
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestCreatePayment_PublishesCustomerScreeningData(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockPublisher := mocks.NewMockPublisher(t)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
+
+	ctx := context.Background()
+	paymentDTO := &dto.Payment{
+		Amount:          100.50,
+		Currency:        "USD",
+		Method:          "CREDIT_CARD",
+		CustomerID:      "customer-123",
+		CustomerName:    " Jane Doe ",
+		CustomerCountry: "mx",
+	}
+
+	mockRepo.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.Payment")).
+		Return(nil).
+		Once()
+
+	mockPublisher.EXPECT().
+		Publish(ctx, models.PaymentCreatedEventTopic, mock.MatchedBy(func(event models.PaymentCreatedEvent) bool {
+			return event.CustomerName == "Jane Doe" && event.CustomerCountry == "MX"
+		})).
+		Return(nil).
+		Once()
+
+	err := paymentService.CreatePayment(ctx, paymentDTO)
+
+	assert.NoError(t, err)
+}
+
+func TestCreatePayment_StoresTraceID(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockPublisher := mocks.NewMockPublisher(t)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
+
+	ctx := contracts.WithTraceID(context.Background(), "trace-123")
+	paymentDTO := &dto.Payment{
+		Amount:     100.50,
+		Currency:   "USD",
+		Method:     "CREDIT_CARD",
+		CustomerID: "customer-123",
+	}
+
+	mockRepo.EXPECT().
+		Create(ctx, mock.MatchedBy(func(payment *models.Payment) bool {
+			return payment.TraceID == "trace-123"
+		})).
+		Return(nil).
+		Once()
+
+	mockPublisher.EXPECT().
+		Publish(ctx, models.PaymentCreatedEventTopic, mock.MatchedBy(func(event models.PaymentCreatedEvent) bool {
+			return event.TraceID == "trace-123"
+		})).
+		Return(nil).
+		Once()
+
+	err := paymentService.CreatePayment(ctx, paymentDTO)
+
+	assert.NoError(t, err)
+}
+
+func TestCreatePayment_GeneratesTraceIDWhenMissing(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockPublisher := mocks.NewMockPublisher(t)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
+
+	ctx := context.Background()
+	paymentDTO := &dto.Payment{
+		Amount:     100.50,
+		Currency:   "USD",
+		Method:     "CREDIT_CARD",
+		CustomerID: "customer-123",
+	}
+
+	var stored string
+	mockRepo.EXPECT().
+		Create(ctx, mock.AnythingOfType("*models.Payment")).
+		Run(func(ctx context.Context, payment *models.Payment) {
+			stored = payment.TraceID
+		}).
+		Return(nil).
+		Once()
+
+	mockPublisher.EXPECT().
+		Publish(ctx, models.PaymentCreatedEventTopic, mock.MatchedBy(func(event models.PaymentCreatedEvent) bool {
+			return event.TraceID != "" && event.TraceID == stored
+		})).
+		Return(nil).
+		Once()
+
+	err := paymentService.CreatePayment(ctx, paymentDTO)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, stored)
+}
+
 func TestCreatePayment_RepoError(t *testing.T) {
 	mockRepo := mocks.NewMockPaymentRepo(t)
 	mockPublisher := mocks.NewMockPublisher(t)
-	paymentService := service.NewPaymentService(mockRepo, mockPublisher)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
 
 	ctx := context.Background()
 	paymentDTO := &dto.Payment{
@@ -74,7 +242,7 @@ func TestCreatePayment_RepoError(t *testing.T) {
 func TestCreatePayment_PublisherError(t *testing.T) {
 	mockRepo := mocks.NewMockPaymentRepo(t)
 	mockPublisher := mocks.NewMockPublisher(t)
-	paymentService := service.NewPaymentService(mockRepo, mockPublisher)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
 
 	ctx := context.Background()
 	paymentDTO := &dto.Payment{
@@ -92,7 +260,7 @@ func TestCreatePayment_PublisherError(t *testing.T) {
 		Once()
 
 	mockPublisher.EXPECT().
-		Publish(ctx, models.PaymentCreatedEventTopic, mock.AnythingOfType("models.PaymentCreatedEvent")).
+		Publish(ctx, models.PaymentCreatedEventTopic, mock.IsType(models.PaymentCreatedEvent{})).
 		Return(expectedError).
 		Once()
 
@@ -105,7 +273,7 @@ func TestCreatePayment_PublisherError(t *testing.T) {
 func TestUpdatePaymentFlags_FraudDeclined(t *testing.T) {
 	mockRepo := mocks.NewMockPaymentRepo(t)
 	mockPublisher := mocks.NewMockPublisher(t)
-	paymentService := service.NewPaymentService(mockRepo, mockPublisher)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
 
 	ctx := context.Background()
 	paymentID := "payment-123"
@@ -134,7 +302,7 @@ func TestUpdatePaymentFlags_FraudDeclined(t *testing.T) {
 		Return(nil).
 		Once()
 
-	err := paymentService.UpdatePaymentFlags(ctx, paymentID, nil, &fraudClean, failureReason)
+	err := paymentService.UpdatePaymentFlags(ctx, "", paymentID, nil, &fraudClean, failureReason)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -143,7 +311,7 @@ func TestUpdatePaymentFlags_FraudDeclined(t *testing.T) {
 func TestUpdatePaymentFlags_WalletDeclined(t *testing.T) {
 	mockRepo := mocks.NewMockPaymentRepo(t)
 	mockPublisher := mocks.NewMockPublisher(t)
-	paymentService := service.NewPaymentService(mockRepo, mockPublisher)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
 
 	ctx := context.Background()
 	paymentID := "payment-456"
@@ -171,7 +339,7 @@ func TestUpdatePaymentFlags_WalletDeclined(t *testing.T) {
 		}), paymentID).
 		Return(nil).
 		Once()
-	err := paymentService.UpdatePaymentFlags(ctx, paymentID, &walletApproved, nil, failureReason)
+	err := paymentService.UpdatePaymentFlags(ctx, "", paymentID, &walletApproved, nil, failureReason)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -180,7 +348,7 @@ func TestUpdatePaymentFlags_WalletDeclined(t *testing.T) {
 func TestUpdatePaymentFlags_BothApproved_TriggersDebit(t *testing.T) {
 	mockRepo := mocks.NewMockPaymentRepo(t)
 	mockPublisher := mocks.NewMockPublisher(t)
-	paymentService := service.NewPaymentService(mockRepo, mockPublisher)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
 
 	ctx := context.Background()
 	paymentID := "payment-789"
@@ -217,11 +385,11 @@ func TestUpdatePaymentFlags_BothApproved_TriggersDebit(t *testing.T) {
 		Once()
 
 	mockPublisher.EXPECT().
-		Publish(ctx, models.WalletDebitEventTopic, mock.AnythingOfType("models.WalletDebitRequestedEvent")).
+		Publish(ctx, models.WalletDebitEventTopic, matchesContract[models.WalletDebitRequestedEvent]()).
 		Return(nil).
 		Once()
 
-	err := paymentService.UpdatePaymentFlags(ctx, paymentID, &walletApproved, &fraudClean, "")
+	err := paymentService.UpdatePaymentFlags(ctx, "", paymentID, &walletApproved, &fraudClean, "")
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -232,9 +400,271 @@ func TestNewPaymentService(t *testing.T) {
 	mockRepo := mocks.NewMockPaymentRepo(t)
 	mockPublisher := mocks.NewMockPublisher(t)
 
-	paymentService := service.NewPaymentService(mockRepo, mockPublisher)
+	mockInbox := mocks.NewMockInbox(t)
+
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mockInbox)
 
 	assert.NotNil(t, paymentService)
 	assert.Equal(t, mockRepo, paymentService.Repo)
 	assert.Equal(t, mockPublisher, paymentService.Publisher)
+	assert.Equal(t, mockInbox, paymentService.Inbox)
+}
+
+func TestUpdatePaymentFlags_RecordsEventInInbox(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockPublisher := mocks.NewMockPublisher(t)
+	mockInbox := mocks.NewMockInbox(t)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mockInbox)
+
+	ctx := context.Background()
+	eventID := "payments.checked/0/42"
+	paymentID := "payment-123"
+	fraudClean := false
+
+	mockInbox.EXPECT().
+		ProcessOnce(ctx, eventID, mock.Anything).
+		RunAndReturn(func(ctx context.Context, _ string, fn func(ctx context.Context) error) (bool, error) {
+			return true, fn(ctx)
+		}).
+		Once()
+
+	mockRepo.EXPECT().
+		GetByID(ctx, paymentID).
+		Return(&models.Payment{ID: paymentID, Status: models.StatusPending}, nil).
+		Once()
+
+	mockRepo.EXPECT().
+		Update(ctx, mock.AnythingOfType("*models.Payment"), paymentID).
+		Return(nil).
+		Once()
+
+	err := paymentService.UpdatePaymentFlags(ctx, eventID, paymentID, nil, &fraudClean, "fraud detected")
+
+	assert.NoError(t, err)
+}
+
+func TestUpdatePaymentFlags_DuplicateEventHasNoSideEffects(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockPublisher := mocks.NewMockPublisher(t)
+	mockInbox := mocks.NewMockInbox(t)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mockInbox)
+
+	ctx := context.Background()
+	walletApproved := true
+	fraudClean := true
+
+	mockInbox.EXPECT().
+		ProcessOnce(ctx, "wallet.funds.verified/1/7", mock.Anything).
+		Return(false, nil).
+		Once()
+
+	err := paymentService.UpdatePaymentFlags(ctx, "wallet.funds.verified/1/7", "payment-789", &walletApproved, &fraudClean, "")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdatePaymentFlags_InboxError(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockPublisher := mocks.NewMockPublisher(t)
+	mockInbox := mocks.NewMockInbox(t)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mockInbox)
+
+	ctx := context.Background()
+	fraudClean := true
+	expectedError := errors.New("database unavailable")
+
+	mockInbox.EXPECT().
+		ProcessOnce(ctx, "payments.checked/0/1", mock.Anything).
+		Return(false, expectedError).
+		Once()
+
+	err := paymentService.UpdatePaymentFlags(ctx, "payments.checked/0/1", "payment-123", nil, &fraudClean, "")
+
+	assert.ErrorIs(t, err, expectedError)
+}
+
+func TestHoldForReview_HoldsPendingPayment(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockInbox := mocks.NewMockInbox(t)
+	paymentService := service.NewPaymentService(mockRepo, mocks.NewMockPublisher(t), mockInbox)
+
+	ctx := context.Background()
+	paymentID := "payment-123"
+
+	mockInbox.EXPECT().
+		ProcessOnce(ctx, "payments.checked/0/3", mock.Anything).
+		RunAndReturn(func(ctx context.Context, _ string, fn func(ctx context.Context) error) (bool, error) {
+			return true, fn(ctx)
+		}).
+		Once()
+
+	mockRepo.EXPECT().
+		GetByID(ctx, paymentID).
+		Return(&models.Payment{ID: paymentID, Status: models.StatusPending, WalletApproved: true}, nil).
+		Once()
+
+	mockRepo.EXPECT().
+		Update(ctx, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.StatusReview &&
+				p.ReviewReason == "sanctions match" &&
+				!p.FraudCleared
+		}), paymentID).
+		Return(nil).
+		Once()
+
+	err := paymentService.HoldForReview(ctx, "payments.checked/0/3", paymentID, "sanctions match")
+
+	assert.NoError(t, err)
+}
+
+func TestHoldForReview_LeavesFailedPayment(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	paymentService := service.NewPaymentService(mockRepo, mocks.NewMockPublisher(t), mocks.NewMockInbox(t))
+
+	ctx := context.Background()
+
+	mockRepo.EXPECT().
+		GetByID(ctx, "payment-123").
+		Return(&models.Payment{ID: "payment-123", Status: models.StatusFailed}, nil).
+		Once()
+
+	err := paymentService.HoldForReview(ctx, "", "payment-123", "sanctions match")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolveReview_ApproveAuthorizesWhenWalletApproved(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockPublisher := mocks.NewMockPublisher(t)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
+
+	ctx := context.Background()
+	paymentID := "payment-review-approve"
+
+	mockRepo.EXPECT().
+		GetByID(ctx, paymentID).
+		Return(&models.Payment{ID: paymentID, Status: models.StatusReview, WalletApproved: true, CustomerID: "customer-123", Amount: 50}, nil).
+		Once()
+
+	mockRepo.EXPECT().
+		Update(ctx, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.StatusPending && p.FraudCleared
+		}), paymentID).
+		Return(nil).
+		Once()
+
+	mockRepo.EXPECT().
+		Update(ctx, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.StatusAuthorized
+		}), paymentID).
+		Return(nil).
+		Once()
+
+	mockPublisher.EXPECT().
+		Publish(ctx, models.WalletDebitEventTopic, matchesContract[models.WalletDebitRequestedEvent]()).
+		Return(nil).
+		Once()
+
+	payment, err := paymentService.ResolveReview(ctx, paymentID, true, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusAuthorized, payment.Status)
+}
+
+func TestResolveReview_DeclineFailsPayment(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	mockPublisher := mocks.NewMockPublisher(t)
+	paymentService := service.NewPaymentService(mockRepo, mockPublisher, mocks.NewMockInbox(t))
+
+	ctx := context.Background()
+	paymentID := "payment-review-decline"
+
+	mockRepo.EXPECT().
+		GetByID(ctx, paymentID).
+		Return(&models.Payment{ID: paymentID, Status: models.StatusReview, WalletApproved: true}, nil).
+		Once()
+
+	mockRepo.EXPECT().
+		Update(ctx, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.StatusFailed && p.FailedReason == "confirmed fraud"
+		}), paymentID).
+		Return(nil).
+		Once()
+
+	payment, err := paymentService.ResolveReview(ctx, paymentID, false, "confirmed fraud")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusFailed, payment.Status)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolveReview_NotInReview(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	paymentService := service.NewPaymentService(mockRepo, mocks.NewMockPublisher(t), mocks.NewMockInbox(t))
+
+	ctx := context.Background()
+
+	mockRepo.EXPECT().
+		GetByID(ctx, "payment-123").
+		Return(&models.Payment{ID: "payment-123", Status: models.StatusAuthorized}, nil).
+		Once()
+
+	_, err := paymentService.ResolveReview(ctx, "payment-123", true, "")
+
+	assert.ErrorIs(t, err, service.ErrNotInReview)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// transactionalRepo marks the context of its transactions so the calls made
+// inside them can be told apart.
+type transactionalRepo struct {
+	*mocks.MockPaymentRepo
+}
+
+type inTransactionKey struct{}
+
+func (r transactionalRepo) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, inTransactionKey{}, true))
+}
+
+func TestResolveReview_RunsInOneTransaction(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	paymentService := service.NewPaymentService(transactionalRepo{mockRepo}, mocks.NewMockPublisher(t), mocks.NewMockInbox(t))
+
+	inTransaction := mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value(inTransactionKey{}) != nil
+	})
+	mockRepo.EXPECT().
+		GetByID(inTransaction, "payment-123").
+		Return(&models.Payment{ID: "payment-123", Status: models.StatusReview}, nil).
+		Once()
+	mockRepo.EXPECT().
+		Update(inTransaction, mock.Anything, "payment-123").
+		Return(nil).
+		Once()
+
+	payment, err := paymentService.ResolveReview(context.Background(), "payment-123", true, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusPending, payment.Status, "the wallet has not approved the payment yet")
+}
+
+func TestFailUndelivered_LeavesPaymentsThatMovedOn(t *testing.T) {
+	mockRepo := mocks.NewMockPaymentRepo(t)
+	paymentService := service.NewPaymentService(mockRepo, mocks.NewMockPublisher(t), mocks.NewMockInbox(t))
+
+	ctx := context.Background()
+
+	mockRepo.EXPECT().
+		GetByID(ctx, "payment-123").
+		Return(&models.Payment{ID: "payment-123", Status: models.StatusAuthorized}, nil).
+		Once()
+
+	err := paymentService.FailUndelivered(ctx, "payment-123", errors.New("broker unavailable"))
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
 }
@@ -0,0 +1,342 @@
+package subscriber_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/jeffleon2/draftea-payment-service/internal/publisher"
+	"github.com/jeffleon2/draftea-payment-service/internal/subscriber"
+	"github.com/jeffleon2/draftea-payment-service/internal/tracing/tracingtest"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const waitFor = 5 * time.Second
+
+// natsSystem is an embedded JetStream server with the streams of the
+// consumed topic and the DLQ.
+type natsSystem struct {
+	js        jetstream.JetStream
+	publisher *publisher.NATSPublisher
+	consumer  *subscriber.NATSConsumer
+}
+
+func startNATS(t *testing.T, delays ...time.Duration) *natsSystem {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{Port: -1, JetStream: true, StoreDir: t.TempDir()})
+	require.NoError(t, err)
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(waitFor), "nats-server did not start")
+
+	natsConfig := config.NATS{URL: srv.ClientURL(), Replicas: 1, AckWait: time.Second, MaxAckPending: 100}
+	nc, js, err := natsConfig.Connect()
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+
+	ctx := context.Background()
+	require.NoError(t, natsConfig.EnsureStreams(ctx, js, []string{models.FraudTopic2Subscribe, models.PaymentsDLQTopic}))
+
+	retryConfig := config.RetryConfig{
+		MaxAttempts: 3,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    100 * time.Millisecond,
+		Delays:      delays,
+		TopicPrefix: "payments.retry",
+	}
+	cb := breaker.New("nats", config.CircuitBreaker{MaxFailures: 5, OpenTimeout: time.Second, HalfOpenMaxRequests: 1})
+	pub := publisher.NewNATSPublisher(js, retryConfig, config.PublisherConfig{}, cb)
+	consumer, err := subscriber.NewNATSConsumer(ctx, js, []string{models.FraudTopic2Subscribe}, "payment-service", pub, retryConfig, natsConfig, 2)
+	require.NoError(t, err)
+
+	return &natsSystem{js: js, publisher: pub, consumer: consumer}
+}
+
+// listen runs handler until the test ends.
+func (s *natsSystem) listen(t *testing.T, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.consumer.Listen(ctx, handler)
+	t.Cleanup(func() {
+		cancel()
+		s.consumer.Close()
+	})
+}
+
+// dlqMessages returns the messages stored in the DLQ stream.
+func (s *natsSystem) dlqMessages(t *testing.T) []models.DLQMessage {
+	t.Helper()
+
+	stream, err := s.js.Stream(context.Background(), config.StreamName(models.PaymentsDLQTopic))
+	require.NoError(t, err)
+	info, err := stream.Info(context.Background())
+	require.NoError(t, err)
+
+	var messages []models.DLQMessage
+	for seq := uint64(1); seq <= info.State.LastSeq; seq++ {
+		raw, err := stream.GetMsg(context.Background(), seq)
+		require.NoError(t, err)
+		var msg models.DLQMessage
+		require.NoError(t, json.Unmarshal(raw.Data, &msg))
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// pending returns how many messages of the consumer are not acknowledged yet.
+func (s *natsSystem) pending(t *testing.T) int {
+	t.Helper()
+
+	info, err := s.consumer.Consumers[models.FraudTopic2Subscribe].Info(context.Background())
+	require.NoError(t, err)
+	return info.NumAckPending + int(info.NumPending)
+}
+
+func fraudCheck(id string) models.FraudCheckEvent {
+	return models.FraudCheckEvent{ID: id, TraceID: "trace-" + id, Status: models.PaymentStatusApproved, CheckedAt: time.Now()}
+}
+
+func TestNATSConsumerAcknowledgesHandledMessages(t *testing.T) {
+	s := startNATS(t, 10*time.Millisecond)
+
+	var mu sync.Mutex
+	var eventIDs []string
+	s.listen(t, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, models.FraudTopic2Subscribe, topic)
+		assert.Equal(t, contracts.EventTypePaymentChecked, envelope.Type)
+		eventIDs = append(eventIDs, envelope.ID)
+		return nil
+	})
+
+	require.NoError(t, s.publisher.Publish(context.Background(), models.FraudTopic2Subscribe, fraudCheck("pay_1")))
+	require.NoError(t, s.publisher.Publish(context.Background(), models.FraudTopic2Subscribe, fraudCheck("pay_2")))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(eventIDs) == 2 && s.pending(t) == 0
+	}, waitFor, 10*time.Millisecond)
+	assert.NotEmpty(t, eventIDs[0])
+	assert.NotEqual(t, eventIDs[0], eventIDs[1], "every event has its own ID")
+	assert.Empty(t, s.dlqMessages(t))
+}
+
+func TestNATSConsumerRedeliversTransientErrors(t *testing.T) {
+	s := startNATS(t, 10*time.Millisecond, 20*time.Millisecond)
+
+	var mu sync.Mutex
+	var eventIDs []string
+	s.listen(t, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		eventIDs = append(eventIDs, envelope.ID)
+		if len(eventIDs) < 3 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	require.NoError(t, s.publisher.Publish(context.Background(), models.FraudTopic2Subscribe, fraudCheck("pay_1")))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(eventIDs) == 3 && s.pending(t) == 0
+	}, waitFor, 10*time.Millisecond)
+	assert.NotEmpty(t, eventIDs[0])
+	assert.Equal(t, []string{eventIDs[0], eventIDs[0], eventIDs[0]}, eventIDs, "every redelivery keeps the event ID")
+	assert.Empty(t, s.dlqMessages(t))
+}
+
+func TestNATSConsumerDeadLettersExhaustedMessages(t *testing.T) {
+	s := startNATS(t, 10*time.Millisecond)
+
+	s.listen(t, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		return errors.New("database unavailable")
+	})
+
+	require.NoError(t, s.publisher.Publish(context.Background(), models.FraudTopic2Subscribe, fraudCheck("pay_1")))
+
+	require.Eventually(t, func() bool {
+		return len(s.dlqMessages(t)) == 1 && s.pending(t) == 0
+	}, waitFor, 10*time.Millisecond)
+	dlqMessage := s.dlqMessages(t)[0]
+	assert.Equal(t, models.FraudTopic2Subscribe, dlqMessage.OriginalTopic)
+	assert.Equal(t, "pay_1", dlqMessage.Key)
+	assert.Equal(t, 2, dlqMessage.Attempts)
+	assert.Equal(t, subscriber.ErrorClassTransient, dlqMessage.ErrorClass)
+	assert.Equal(t, "payment-service", dlqMessage.ConsumerGroup)
+	assert.Equal(t, int64(1), dlqMessage.Offset)
+	assert.Equal(t, "trace-pay_1", dlqMessage.TraceID)
+	assert.Equal(t, contracts.JSON.ContentType(), dlqMessage.Headers[contracts.HeaderContentType])
+}
+
+func TestNATSConsumerRestoresTheTraceID(t *testing.T) {
+	s := startNATS(t, 10*time.Millisecond)
+
+	traceIDs := make(chan string, 1)
+	s.listen(t, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		traceIDs <- contracts.TraceID(ctx)
+		return nil
+	})
+
+	// The event has no trace ID of its own: the publisher takes the one of ctx.
+	event := fraudCheck("pay_1")
+	event.TraceID = ""
+	ctx := contracts.WithTraceID(context.Background(), "trace-from-request")
+	require.NoError(t, s.publisher.Publish(ctx, models.FraudTopic2Subscribe, event))
+
+	select {
+	case traceID := <-traceIDs:
+		assert.Equal(t, "trace-from-request", traceID)
+	case <-time.After(waitFor):
+		t.Fatal("the message was not handled")
+	}
+}
+
+func TestNATSConsumerDeadLettersPermanentErrorsWithoutRetrying(t *testing.T) {
+	s := startNATS(t, 10*time.Millisecond, 20*time.Millisecond)
+
+	var mu sync.Mutex
+	calls := 0
+	s.listen(t, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return nil
+	})
+
+	// A payload that breaks its event contract never reaches the handler.
+	msg := nats.NewMsg(models.FraudTopic2Subscribe)
+	msg.Data = []byte(`{"not":"an envelope"`)
+	msg.Header.Set(contracts.HeaderMessageKey, "pay_1")
+	_, err := s.js.PublishMsg(context.Background(), msg)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(s.dlqMessages(t)) == 1 && s.pending(t) == 0
+	}, waitFor, 10*time.Millisecond)
+	dlqMessage := s.dlqMessages(t)[0]
+	assert.Equal(t, "pay_1", dlqMessage.Key)
+	assert.Equal(t, 1, dlqMessage.Attempts)
+	assert.Equal(t, subscriber.ErrorClassPermanent, dlqMessage.ErrorClass)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Zero(t, calls)
+}
+
+func TestNATSConsumerContinuesThePublisherTrace(t *testing.T) {
+	_, exporter := tracingtest.NewInMemoryProvider()
+	s := startNATS(t, 10*time.Millisecond)
+
+	handled := make(chan trace.SpanContext, 1)
+	s.listen(t, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		handled <- trace.SpanContextFromContext(ctx)
+		return nil
+	})
+
+	require.NoError(t, s.publisher.Publish(context.Background(), models.FraudTopic2Subscribe, fraudCheck("pay_1")))
+
+	var consumer trace.SpanContext
+	select {
+	case consumer = <-handled:
+	case <-time.After(waitFor):
+		t.Fatal("the message was not handled")
+	}
+
+	var producer tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "send "+models.FraudTopic2Subscribe {
+			producer = span
+		}
+	}
+	require.True(t, producer.SpanContext.IsValid(), "the publish was not traced")
+	assert.Equal(t, producer.SpanContext.TraceID(), consumer.TraceID(), "the handler runs in the trace of the publish")
+}
+
+// failingAckConsumer hands out the messages of a real consumer but fails the
+// first acknowledgement.
+type failingAckConsumer struct {
+	jetstream.Consumer
+	failed atomic.Bool
+}
+
+func (c *failingAckConsumer) Messages(opts ...jetstream.PullMessagesOpt) (jetstream.MessagesContext, error) {
+	messages, err := c.Consumer.Messages(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &failingAckMessages{MessagesContext: messages, consumer: c}, nil
+}
+
+type failingAckMessages struct {
+	jetstream.MessagesContext
+	consumer *failingAckConsumer
+}
+
+func (m *failingAckMessages) Next(opts ...jetstream.NextOpt) (jetstream.Msg, error) {
+	msg, err := m.MessagesContext.Next(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &failingAckMsg{Msg: msg, consumer: m.consumer}, nil
+}
+
+type failingAckMsg struct {
+	jetstream.Msg
+	consumer *failingAckConsumer
+}
+
+func (m *failingAckMsg) Ack() error {
+	if m.consumer.failed.CompareAndSwap(false, true) {
+		return errors.New("connection closed")
+	}
+	return m.Msg.Ack()
+}
+
+func TestNATSConsumerKeepsWorkingAfterAFailedAck(t *testing.T) {
+	s := startNATS(t, 10*time.Millisecond)
+	// One worker, so the second message waits behind the one whose ack fails.
+	s.consumer.Workers = 1
+	s.consumer.Consumers[models.FraudTopic2Subscribe] = &failingAckConsumer{Consumer: s.consumer.Consumers[models.FraudTopic2Subscribe]}
+
+	var mu sync.Mutex
+	var handled []string
+	s.listen(t, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		var event models.FraudCheckEvent
+		require.NoError(t, envelope.Decode(&event))
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, event.ID)
+		return nil
+	})
+
+	require.NoError(t, s.publisher.Publish(context.Background(), models.FraudTopic2Subscribe, fraudCheck("pay_1")))
+	require.NoError(t, s.publisher.Publish(context.Background(), models.FraudTopic2Subscribe, fraudCheck("pay_2")))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 3 && s.pending(t) == 0
+	}, waitFor, 10*time.Millisecond)
+	assert.Equal(t, []string{"pay_1", "pay_2", "pay_1"}, handled, "pay_1 is redelivered after AckWait")
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin traces every query of a gorm.DB as a child of the span in its
+// context (see gorm.DB.WithContext). Queries are recorded with their
+// placeholders, never with their values.
+type GormPlugin struct{}
+
+// Name implements gorm.Plugin.
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the callbacks that start and end the span of each
+// create, query, update, delete, row and raw statement.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", startQuery("INSERT")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", endQuery),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", startQuery("SELECT")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", endQuery),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", startQuery("UPDATE")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", endQuery),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", startQuery("DELETE")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", endQuery),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", startQuery("ROW")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", endQuery),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", startQuery("RAW")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", endQuery),
+	)
+}
+
+func startQuery(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Statement.Context == nil {
+			return
+		}
+
+		_, span := tracer().Start(tx.Statement.Context, operation+" "+tx.Statement.Table,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameKey.String(tx.Dialector.Name()),
+				semconv.DBOperationName(operation),
+			),
+		)
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endQuery(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		semconv.DBCollectionName(tx.Statement.Table),
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+	)
+	if !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		RecordError(span, tx.Error)
+	}
+	span.End()
+}
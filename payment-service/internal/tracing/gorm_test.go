@@ -0,0 +1,45 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/jeffleon2/draftea-payment-service/internal/tracing"
+	"github.com/jeffleon2/draftea-payment-service/internal/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestGormPluginTracesQueriesInTheTraceOfTheirContext(t *testing.T) {
+	_, exporter := tracingtest.NewInMemoryProvider()
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tracing.GormPlugin{}))
+
+	ctx, parent := tracing.StartProducer(context.Background(), tracing.SystemKafka, "payments.created")
+	require.NoError(t, db.WithContext(ctx).Create(&models.Payment{ID: "pay_1", CustomerID: "user_1"}).Error)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	query := spans[0]
+	assert.Equal(t, "INSERT payments", query.Name)
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), query.Parent.SpanID())
+	assert.Contains(t, query.Attributes, semconv.DBSystemNameKey.String("postgres"))
+	assert.Contains(t, query.Attributes, semconv.DBCollectionName("payments"))
+
+	var text string
+	for _, attr := range query.Attributes {
+		if attr.Key == semconv.DBQueryTextKey {
+			text = attr.Value.AsString()
+		}
+	}
+	assert.Contains(t, text, `INSERT INTO "payments"`)
+	assert.NotContains(t, text, "user_1", "values are never recorded")
+}
@@ -0,0 +1,50 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jeffleon2/draftea-payment-service/internal/tracing"
+	"github.com/jeffleon2/draftea-payment-service/internal/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestKafkaHeadersCarryTheTraceToTheConsumer(t *testing.T) {
+	_, exporter := tracingtest.NewInMemoryProvider()
+
+	ctx, producer := tracing.StartProducer(context.Background(), tracing.SystemKafka, "payments.created")
+	headers := tracing.KafkaHeaders{{Key: "content-type", Value: []byte("application/cloudevents+json")}}
+	tracing.Inject(ctx, &headers)
+	producer.End()
+
+	_, consumer := tracing.StartConsumer(context.Background(), &headers, tracing.SystemKafka, "payments.created")
+	consumer.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "send payments.created", spans[0].Name)
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind)
+	assert.Equal(t, "process payments.created", spans[1].Name)
+	assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind)
+	assert.Equal(t, spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID(), "one trace across the message")
+	assert.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+	assert.Equal(t, "application/cloudevents+json", headers.Get("content-type"))
+}
+
+func TestKafkaHeadersReplaceTheTraceOfARepublishedMessage(t *testing.T) {
+	_, exporter := tracingtest.NewInMemoryProvider()
+
+	var headers tracing.KafkaHeaders
+	for range 2 {
+		ctx, span := tracing.StartProducer(context.Background(), tracing.SystemKafka, "payments.retry.1s")
+		tracing.Inject(ctx, &headers)
+		span.End()
+	}
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, []string{"traceparent"}, headers.Keys())
+	assert.Contains(t, headers.Get("traceparent"), spans[1].SpanContext.SpanID().String())
+}
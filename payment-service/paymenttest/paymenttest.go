@@ -0,0 +1,119 @@
+// Package paymenttest runs payment-service in process for whole-system
+// tests: its event handlers consume from a membroker.Broker instead of Kafka
+// and its repositories use the given database instead of Postgres. The
+// configuration is read from the environment, with the service defaults.
+package paymenttest
+
+import (
+	"context"
+	"strings"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/jeffleon2/draftea-event-contracts/membroker"
+	"github.com/jeffleon2/draftea-payment-service/config"
+	"github.com/jeffleon2/draftea-payment-service/internal/breaker"
+	handlers "github.com/jeffleon2/draftea-payment-service/internal/handlers"
+	"github.com/jeffleon2/draftea-payment-service/internal/models"
+	"github.com/jeffleon2/draftea-payment-service/internal/models/dto"
+	"github.com/jeffleon2/draftea-payment-service/internal/repository/posgrest"
+	"github.com/jeffleon2/draftea-payment-service/internal/service"
+	"github.com/jeffleon2/draftea-payment-service/internal/subscriber"
+	"gorm.io/gorm"
+)
+
+// Payment is the stored state of a payment.
+type Payment struct {
+	ID             string
+	CustomerID     string
+	Amount         float64
+	Status         string
+	FraudCleared   bool
+	WalletApproved bool
+	FailedReason   string
+	TraceID        string
+}
+
+// Service is a running payment-service.
+type Service struct {
+	Consumer *membroker.Consumer
+
+	payments *service.PaymentService
+	db       *gorm.DB
+}
+
+// Start migrates db and starts consuming the service's topics from broker
+// until ctx is cancelled.
+func Start(ctx context.Context, broker *membroker.Broker, db *gorm.DB) (*Service, error) {
+	var cfg config.Config
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&models.Payment{}, &models.ProcessedEvent{}); err != nil {
+		return nil, err
+	}
+
+	publisherConfig, err := cfg.Kafka.GetPublisherConfig()
+	if err != nil {
+		return nil, err
+	}
+	publisher := broker.Publisher(models.ServiceName)
+	publisher.Serializer = publisherConfig.Serializer
+
+	dbBreaker := breaker.New("postgres", cfg.CircuitBreaker)
+	dbBreaker.IsFailure = posgrest.IsFailure
+	paymentService := service.NewPaymentService(posgrest.New[models.Payment](db, dbBreaker), publisher, posgrest.NewInbox(db, dbBreaker))
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+
+	consumer := broker.Consumer(cfg.Kafka.PaymentConsumerGroup, strings.Split(cfg.Kafka.SubscriberTopics, ",")...)
+	consumer.IsPermanent = subscriber.IsPermanent
+	consumer.Listen(ctx, paymentHandler.HandleEvents)
+
+	return &Service{Consumer: consumer, payments: paymentService, db: db}, nil
+}
+
+// CreatePayment creates a payment as POST /payments does, with the trace ID
+// of ctx (see contracts.WithTraceID) as if sent in X-Trace-ID.
+func (s *Service) CreatePayment(ctx context.Context, amount float64, currency, method, customerID string) error {
+	return s.payments.CreatePayment(ctx, &dto.Payment{
+		Amount:     amount,
+		Currency:   currency,
+		Method:     method,
+		CustomerID: customerID,
+	})
+}
+
+// Payments returns every stored payment.
+func (s *Service) Payments(ctx context.Context) ([]Payment, error) {
+	stored, err := s.payments.Repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]Payment, len(*stored))
+	for i, payment := range *stored {
+		payments[i] = Payment{
+			ID:             payment.ID,
+			CustomerID:     payment.CustomerID,
+			Amount:         payment.Amount,
+			Status:         string(payment.Status),
+			FraudCleared:   payment.FraudCleared,
+			WalletApproved: payment.WalletApproved,
+			FailedReason:   payment.FailedReason,
+			TraceID:        payment.TraceID,
+		}
+	}
+	return payments, nil
+}
+
+// ProcessedEvents returns the IDs of the events recorded in the inbox.
+func (s *Service) ProcessedEvents(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := s.db.WithContext(ctx).Model(&models.ProcessedEvent{}).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Close waits for the consumer to stop. The context given to Start must be
+// cancelled first.
+func (s *Service) Close() error {
+	return s.Consumer.Close()
+}
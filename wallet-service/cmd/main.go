@@ -2,72 +2,191 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/jeffleon2/draftea-wallet-service/internal/breaker"
 	"github.com/jeffleon2/draftea-wallet-service/internal/database"
 	"github.com/jeffleon2/draftea-wallet-service/internal/handler"
+	"github.com/jeffleon2/draftea-wallet-service/internal/health"
+	"github.com/jeffleon2/draftea-wallet-service/internal/logging"
+	"github.com/jeffleon2/draftea-wallet-service/internal/metrics"
 	"github.com/jeffleon2/draftea-wallet-service/internal/models"
 	"github.com/jeffleon2/draftea-wallet-service/internal/publisher"
 	"github.com/jeffleon2/draftea-wallet-service/internal/repository/posgrest"
 	"github.com/jeffleon2/draftea-wallet-service/internal/service"
 	"github.com/jeffleon2/draftea-wallet-service/internal/subscriber"
+	"github.com/jeffleon2/draftea-wallet-service/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
 )
 
+// serverShutdownTimeout bounds the wait for in-flight scrapes and probes.
+const serverShutdownTimeout = 5 * time.Second
+
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	cfg, err := config.New()
 	if err != nil {
-		fmt.Println("Error reading config file", err)
-		os.Exit(1)
+		logrus.Fatalf("Error reading config file: %v", err)
+	}
+	if err := logging.Setup(cfg.Logging); err != nil {
+		logrus.Fatalf("Error setting up logging: %v", err)
 	}
+	checker := health.NewChecker(cfg.APP.HealthCheckTimeout)
 
-	brokers := strings.Split(cfg.Kafka.Brokers, ",")
-	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
-	subscriberTopics := strings.Split(cfg.Kafka.SubscriberTopics, ",")
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		logrus.Fatalf("Error setting up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
 
 	db, err := cfg.DB.GormConnect()
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		logrus.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		logrus.Fatalf("failed to instrument database: %v", err)
 	}
 
 	if err := db.AutoMigrate(&models.Wallet{}); err != nil {
-		log.Fatalf("failed to auto migrate: %v", err)
+		logrus.Fatalf("failed to auto migrate: %v", err)
 	}
+	checker.Critical("postgres", health.Database(db))
 
 	if os.Getenv("GO_ENV") == "local" {
 		if err := database.SeedWallets(db); err != nil {
-			log.Printf("Warning: failed to seed wallets: %v", err)
+			logrus.WithError(err).Warn("Failed to seed wallets")
 		}
 	}
 
-	publishers := publisher.NewKafkaPublisher(brokers[0], publishTopics, cfg.Kafka.GetRetryConfig())
+	metrics.RegisterMetrics()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", checker.Liveness)
+	mux.HandleFunc("/readyz", checker.Readiness)
+	server := &http.Server{Addr: fmt.Sprintf(":%s", cfg.APP.PORT), Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatalf("failed to start http server: %v", err)
+		}
+	}()
 
-	multiConsumer := subscriber.NewMultiTopicConsumer(brokers, subscriberTopics, cfg.Kafka.WalletConsumerGroup, publishers, cfg.Kafka.GetRetryConfig())
-	walletRepo := posgrest.New[models.Wallet](db)
+	publisherConfig, err := cfg.Kafka.GetPublisherConfig()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	messageTransport, err := cfg.APP.MessageTransport()
+	if err != nil {
+		logrus.Fatalf("invalid configuration: %v", err)
+	}
+	var (
+		publishers    service.Publisher
+		multiConsumer eventConsumer
+	)
+	switch messageTransport {
+	case config.TransportNATS:
+		publishers, multiConsumer = connectNATS(ctx, cfg, publisherConfig, checker)
+	default:
+		publishers, multiConsumer = connectKafka(ctx, cfg, publisherConfig, checker)
+	}
+	dbBreaker := breaker.New("postgres", cfg.CircuitBreaker)
+	dbBreaker.IsFailure = posgrest.IsFailure
+	walletRepo := posgrest.New[models.Wallet](db, dbBreaker)
 	walletService := service.NewWalletService(publishers, walletRepo)
 	walletHandler := handler.Wallet(walletService)
 
-	multiConsumer.Listen(ctx, func(topic string, value []byte) error {
-		log.Printf("📩 Received event → topic=%s value=%s\n", topic, string(value))
-		walletHandler.Handler(ctx, topic, value)
-		return nil
+	multiConsumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		logging.FromContext(ctx).Infof("Received %s event %s", envelope.Type, envelope.ID)
+		return walletHandler.Handler(ctx, topic, envelope)
 	})
+	checker.Ready()
 
 	<-ctx.Done()
+	checker.Stopping()
 
-	for _, reader := range multiConsumer.Readers {
-		if err := reader.Close(); err != nil {
-			log.Println("Error closing consumer:", err)
-		}
+	if err := multiConsumer.Close(); err != nil {
+		logrus.WithError(err).Error("Error closing consumer")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Error("Error stopping http server")
+	}
+
+	logrus.Info("Wallet service stopped")
+}
+
+// eventConsumer is the multi-topic consumer of the configured transport.
+type eventConsumer interface {
+	Listen(ctx context.Context, handler func(ctx context.Context, topic string, envelope contracts.Envelope) error)
+	Close() error
+}
+
+// connectKafka connects to Kafka and returns its publisher and the consumer
+// of the subscribed topics, whose checks it registers in checker.
+func connectKafka(ctx context.Context, cfg *config.Config, publisherConfig config.PublisherConfig, checker *health.Checker) (*publisher.KafkaPublisher, *subscriber.KafkaConsumer) {
+	dialer, err := cfg.Kafka.Dialer()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	transport, err := cfg.Kafka.Transport()
+	if err != nil {
+		logrus.Fatalf("invalid Kafka configuration: %v", err)
+	}
+	if err := cfg.Kafka.CheckConnection(ctx, dialer); err != nil {
+		logrus.Fatalf("failed to connect to Kafka: %v", err)
 	}
 
-	log.Println("Wallet service stopped")
+	brokers := cfg.Kafka.BrokerList()
+	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
+	publishTopics = append(publishTopics, cfg.Kafka.GetRetryConfig().RetryTopics()...)
+	subscriberTopics := strings.Split(cfg.Kafka.SubscriberTopics, ",")
+	publisherConfig.SyncTopics = append(cfg.Kafka.GetRetryConfig().RetryTopics(), models.WalletDLQTopic, models.WalletResponseTopic)
+	publishers := publisher.NewKafkaPublisher(brokers, transport, publishTopics, cfg.Kafka.GetRetryConfig(), publisherConfig, breaker.New("kafka", cfg.CircuitBreaker))
+	multiConsumer := subscriber.NewMultiTopicConsumer(brokers, dialer, subscriberTopics, cfg.Kafka.WalletConsumerGroup, publishers, cfg.Kafka.GetRetryConfig(), cfg.Kafka.GetCommitConfig(), cfg.Kafka.ConsumerWorkers)
+
+	checker.Critical("kafka", func(ctx context.Context) error {
+		return cfg.Kafka.CheckConnection(ctx, dialer)
+	})
+	checker.Optional("kafka_consumer_group", func(ctx context.Context) error {
+		return cfg.Kafka.CheckConsumerGroup(ctx, transport, cfg.Kafka.WalletConsumerGroup, multiConsumer.Topics())
+	})
+	return publishers, multiConsumer
+}
+
+// connectNATS connects to NATS, creates the streams of every topic the
+// service publishes or consumes and returns the JetStream publisher and
+// consumer, registering the NATS check in checker. Topics, consumer group and
+// retry delays are the KAFKA_* settings.
+func connectNATS(ctx context.Context, cfg *config.Config, publisherConfig config.PublisherConfig, checker *health.Checker) (*publisher.NATSPublisher, *subscriber.NATSConsumer) {
+	conn, js, err := cfg.NATS.Connect()
+	if err != nil {
+		logrus.Fatalf("failed to connect to NATS: %v", err)
+	}
+	checker.Critical("nats", health.NATS(conn))
+
+	publishTopics := strings.Split(cfg.Kafka.PublishTopics, ",")
+	subscriberTopics := strings.Split(cfg.Kafka.SubscriberTopics, ",")
+	if err := cfg.NATS.EnsureStreams(ctx, js, append(publishTopics, subscriberTopics...)); err != nil {
+		logrus.Fatalf("failed to create NATS streams: %v", err)
+	}
+
+	publishers := publisher.NewNATSPublisher(js, cfg.Kafka.GetRetryConfig(), publisherConfig, breaker.New("nats", cfg.CircuitBreaker))
+	multiConsumer, err := subscriber.NewNATSConsumer(ctx, js, subscriberTopics, cfg.Kafka.WalletConsumerGroup, publishers, cfg.Kafka.GetRetryConfig(), cfg.NATS, cfg.Kafka.ConsumerWorkers)
+	if err != nil {
+		logrus.Fatalf("failed to create NATS consumers: %v", err)
+	}
+	return publishers, multiConsumer
 }
@@ -0,0 +1,126 @@
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/jeffleon2/draftea-wallet-service/internal/breaker"
+	"github.com/stretchr/testify/assert"
+)
+
+var errDown = errors.New("dependency down")
+
+func newBreaker() *breaker.Breaker {
+	return breaker.New("test", config.CircuitBreaker{
+		MaxFailures:         3,
+		OpenTimeout:         20 * time.Millisecond,
+		HalfOpenMaxRequests: 1,
+	})
+}
+
+func fail() error { return errDown }
+
+func succeed() error { return nil }
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	b := newBreaker()
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, b.Execute(fail), errDown)
+	}
+	assert.Equal(t, breaker.Open, b.State())
+
+	called := false
+	err := b.Execute(func() error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.False(t, called)
+}
+
+func TestBreakerSuccessResetsFailureCount(t *testing.T) {
+	b := newBreaker()
+
+	assert.Error(t, b.Execute(fail))
+	assert.Error(t, b.Execute(fail))
+	assert.NoError(t, b.Execute(succeed))
+	assert.Error(t, b.Execute(fail))
+	assert.Error(t, b.Execute(fail))
+
+	assert.Equal(t, breaker.Closed, b.State())
+}
+
+func TestBreakerHalfOpenProbeClosesOnSuccess(t *testing.T) {
+	b := newBreaker()
+	for i := 0; i < 3; i++ {
+		_ = b.Execute(fail)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, breaker.HalfOpen, b.State())
+
+	assert.NoError(t, b.Execute(succeed))
+	assert.Equal(t, breaker.Closed, b.State())
+}
+
+func TestBreakerHalfOpenProbeReopensOnFailure(t *testing.T) {
+	b := newBreaker()
+	for i := 0; i < 3; i++ {
+		_ = b.Execute(fail)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	assert.ErrorIs(t, b.Execute(fail), errDown)
+	assert.Equal(t, breaker.Open, b.State())
+	assert.ErrorIs(t, b.Execute(succeed), breaker.ErrOpen)
+}
+
+func TestBreakerHalfOpenLimitsConcurrentProbes(t *testing.T) {
+	b := newBreaker()
+	for i := 0; i < 3; i++ {
+		_ = b.Execute(fail)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- b.Execute(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+
+	<-started
+	assert.ErrorIs(t, b.Execute(succeed), breaker.ErrOpen)
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, breaker.Closed, b.State())
+}
+
+func TestBreakerIgnoresErrorsThatAreNotFailures(t *testing.T) {
+	b := newBreaker()
+	notFound := errors.New("not found")
+	b.IsFailure = func(err error) bool { return !errors.Is(err, notFound) }
+
+	for i := 0; i < 5; i++ {
+		assert.ErrorIs(t, b.Execute(func() error { return notFound }), notFound)
+	}
+	assert.Equal(t, breaker.Closed, b.State())
+}
+
+func TestBreakerIgnoresCancelledContextByDefault(t *testing.T) {
+	b := newBreaker()
+
+	for i := 0; i < 5; i++ {
+		_ = b.Execute(func() error { return context.Canceled })
+	}
+	assert.Equal(t, breaker.Closed, b.State())
+}
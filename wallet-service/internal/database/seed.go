@@ -1,10 +1,10 @@
 package database
 
 import (
-	"log"
 	"time"
 
 	"github.com/jeffleon2/draftea-wallet-service/internal/models"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
@@ -43,6 +43,6 @@ func SeedWallets(db *gorm.DB) error {
 		}
 	}
 
-	log.Println("✅ Wallets seeded successfully")
+	logrus.Info("Wallets seeded")
 	return nil
 }
@@ -2,10 +2,12 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-wallet-service/internal/logging"
 	"github.com/jeffleon2/draftea-wallet-service/internal/models"
-	"github.com/sirupsen/logrus"
+	"github.com/jeffleon2/draftea-wallet-service/internal/subscriber"
 )
 
 // WalletServiceIn defines the interface for wallet business logic operations.
@@ -32,38 +34,46 @@ func Wallet(s WalletServiceIn) *WalletHandler {
 //   - wallet.debit.requested: Executes wallet debit after payment authorization
 //   - payments.created: Validates funds availability for new payments
 //
-// The handler unmarshals the appropriate event type and delegates to the service layer.
-func (h *WalletHandler) Handler(ctx context.Context, topic string, raw []byte) error {
+// The subscriber has already decoded and validated the envelope, whatever its
+// format; the handler decodes its data by type and version (unknown types
+// and major versions are permanent errors) and delegates to the service layer.
+func (h *WalletHandler) Handler(ctx context.Context, topic string, envelope contracts.Envelope) error {
+	logger := logging.FromContext(ctx)
 
-	switch topic {
-	case models.WalletDebitEventTopic:
+	switch envelope.Type {
+	case models.EventTypeWalletDebitRequested:
 		var event models.WalletDebitRequestedEvent
 
-		if err := json.Unmarshal(raw, &event); err != nil {
-			logrus.Errorf("Error unmarshalling WalletDebitRequestedEvent: %s", err.Error())
-			return err
+		if err := envelope.Decode(&event); err != nil {
+			logger.Errorf("Error unmarshalling WalletDebitRequestedEvent: %s", err.Error())
+			return subscriber.Permanent(err)
 		}
+		logger = logger.WithField(logging.FieldPaymentID, event.PaymentID)
 
 		if err := h.WalletService.DebitBalance(ctx, event.UserID, event.Amount); err != nil {
-			logrus.Errorf("Error validating funds: %s", err.Error())
+			logger.Errorf("Error validating funds: %s", err.Error())
 			return err
 		}
 
-		logrus.Info("WalletDebitRequestedEvent handled successfully")
-	case models.PaymentCreatedEventTopic:
+		logger.Info("WalletDebitRequestedEvent handled successfully")
+	case models.EventTypePaymentCreated:
 		var event models.PaymentCreatedEvent
 
-		if err := json.Unmarshal(raw, &event); err != nil {
-			logrus.Errorf("Error unmarshalling PaymentCreatedEvent: %s", err.Error())
-			return err
+		if err := envelope.Decode(&event); err != nil {
+			logger.Errorf("Error unmarshalling PaymentCreatedEvent: %s", err.Error())
+			return subscriber.Permanent(err)
 		}
+		logger = logger.WithField(logging.FieldPaymentID, event.ID)
 
 		if err := h.WalletService.ValidateFunds(ctx, event); err != nil {
-			logrus.Errorf("Error validating funds: %s", err.Error())
+			logger.Errorf("Error validating funds: %s", err.Error())
 			return err
 		}
 
-		logrus.Info("PaymentCreatedEvent handled successfully")
+		logger.Info("PaymentCreatedEvent handled successfully")
+	default:
+		logger.Errorf("event type %s not allowed on topic %s", envelope.Type, topic)
+		return subscriber.Permanent(fmt.Errorf("event type %s not allowed on topic %s", envelope.Type, topic))
 	}
 
 	return nil
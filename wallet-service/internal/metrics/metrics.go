@@ -0,0 +1,84 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	ConsumerErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_errors_total",
+			Help: "Número total de errores de handlers de Kafka por topic y clase (permanent/transient)",
+		},
+		[]string{"topic", "class"},
+	)
+
+	DLQMessagesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_dlq_messages_total",
+			Help: "Número total de mensajes enviados a la DLQ por topic y clase de error",
+		},
+		[]string{"topic", "class"},
+	)
+
+	ConsumerRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_retries_total",
+			Help: "Número total de mensajes movidos a un topic de retry por topic original y topic de retry",
+		},
+		[]string{"topic", "retry_topic"},
+	)
+
+	PublishDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kafka_publish_duration_seconds",
+			Help:    "Tiempo desde que un mensaje se entrega al writer hasta que el broker confirma o falla la escritura, por topic y resultado",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"topic", "result"},
+	)
+
+	PublishBatchSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kafka_publish_batch_size",
+			Help:    "Número de mensajes por batch escrito en Kafka, por topic",
+			Buckets: []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+		},
+		[]string{"topic"},
+	)
+
+	PublishedMessagesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_published_messages_total",
+			Help: "Número total de mensajes escritos en Kafka por topic y resultado (success/error)",
+		},
+		[]string{"topic", "result"},
+	)
+
+	CircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "circuit_breaker_state",
+			Help: "Estado del circuit breaker por dependencia (0=closed, 1=open, 2=half-open)",
+		},
+		[]string{"name"},
+	)
+
+	CircuitBreakerRejectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "circuit_breaker_rejections_total",
+			Help: "Número total de llamadas rechazadas sin contactar la dependencia porque el circuit breaker estaba abierto",
+		},
+		[]string{"name"},
+	)
+)
+
+func RegisterMetrics() {
+	prometheus.MustRegister(
+		ConsumerErrorsTotal,
+		DLQMessagesTotal,
+		ConsumerRetriesTotal,
+		PublishDuration,
+		PublishBatchSize,
+		PublishedMessagesTotal,
+		CircuitBreakerState,
+		CircuitBreakerRejectionsTotal,
+	)
+}
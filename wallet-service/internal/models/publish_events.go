@@ -1,29 +1,73 @@
 package models
 
-import "time"
+import (
+	"encoding/base64"
+	"time"
+	"unicode/utf8"
 
-type WalletStatus string
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+)
+
+type WalletStatus = contracts.WalletStatus
 
 const (
-	WalletStatusApproved WalletStatus = "APPROVED"
-	WalletStatusDeclined WalletStatus = "DECLINED"
+	WalletStatusApproved = contracts.WalletStatusApproved
+	WalletStatusDeclined = contracts.WalletStatusDeclined
 
 	WalletResponseTopic = "wallet.funds.verified"
 	WalletDLQTopic      = "wallet.dlq"
 )
 
-type WalletResponseEvent struct {
-	PaymentID string       `json:"payment_id"`
-	UserID    string       `json:"user_id"`
-	Status    WalletStatus `json:"status"`
-	Amount    float64      `json:"amount"`
-	Reason    string       `json:"reason"`
-}
+// WalletResponseEvent is published with the funds verification of a
+// payment. Its schema lives in the shared event-contracts module.
+type WalletResponseEvent = contracts.WalletResponseEvent
+
+// ServiceName identifies this service in dead-lettered messages.
+const ServiceName = "wallet-service"
 
 type DLQMessage struct {
 	OriginalTopic string    `json:"original_topic"`
 	Key           string    `json:"key"`
+	TraceID       string    `json:"trace_id,omitempty"`
 	Value         string    `json:"value"`
+	ValueEncoding string    `json:"value_encoding,omitempty"`
 	Timestamp     time.Time `json:"timestamp"`
 	Attempts      int       `json:"attempts"`
+	Error         string    `json:"error,omitempty"`
+	ErrorClass    string    `json:"error_class,omitempty"`
+
+	// Where the message failed: the producing service, its consumer group and
+	// the partition and offset it was first read from on OriginalTopic.
+	Service       string            `json:"service,omitempty"`
+	ConsumerGroup string            `json:"consumer_group,omitempty"`
+	Partition     int               `json:"partition"`
+	Offset        int64             `json:"offset"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+// MessageKey keeps the key of the original message.
+func (m DLQMessage) MessageKey() string {
+	return m.Key
+}
+
+// DLQValueBase64 is the DLQMessage.ValueEncoding of values that are not
+// text, e.g. protobuf encoded events.
+const DLQValueBase64 = "base64"
+
+// SetValue stores the value of the failed message, base64 encoded unless it
+// is UTF-8 text that survives the JSON encoding of the DLQ message.
+func (m *DLQMessage) SetValue(value []byte) {
+	if utf8.Valid(value) {
+		m.Value, m.ValueEncoding = string(value), ""
+		return
+	}
+	m.Value, m.ValueEncoding = base64.StdEncoding.EncodeToString(value), DLQValueBase64
+}
+
+// RawValue returns the value of the failed message as it was consumed.
+func (m DLQMessage) RawValue() ([]byte, error) {
+	if m.ValueEncoding == DLQValueBase64 {
+		return base64.StdEncoding.DecodeString(m.Value)
+	}
+	return []byte(m.Value), nil
 }
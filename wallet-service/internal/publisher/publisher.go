@@ -2,22 +2,51 @@ package publisher
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"slices"
 	"time"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/jeffleon2/draftea-wallet-service/internal/breaker"
+	"github.com/jeffleon2/draftea-wallet-service/internal/logging"
+	"github.com/jeffleon2/draftea-wallet-service/internal/metrics"
+	"github.com/jeffleon2/draftea-wallet-service/internal/tracing"
 	kafka "github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
 )
 
+// Keyed is implemented by events that carry a partition key. Messages with
+// the same key go to the same partition and are consumed in order.
+type Keyed interface {
+	MessageKey() string
+}
+
+// DeliveryCallback is called with every batch the writers deliver or fail to
+// deliver. In async mode it is the only way to learn about failed publishes.
+type DeliveryCallback func(topic string, messages []kafka.Message, err error)
+
 type KafkaPublisher struct {
 	Writers     map[string]*kafka.Writer
 	RetryConfig config.RetryConfig
+	OnDelivery  DeliveryCallback
+	// Serializer encodes events; JSON unless configured otherwise.
+	Serializer contracts.Serializer
+	// Breaker stops publish attempts while Kafka keeps failing.
+	Breaker *breaker.Breaker
 }
 
-func NewKafkaPublisher(kafkaURL string, topics []string, retryConfig config.RetryConfig) *KafkaPublisher {
+func NewKafkaPublisher(
+	brokers []string,
+	transport *kafka.Transport,
+	topics []string,
+	retryConfig config.RetryConfig,
+	publisherConfig config.PublisherConfig,
+	cb *breaker.Breaker,
+) *KafkaPublisher {
 	writers := make(map[string]*kafka.Writer)
 	if retryConfig.MaxAttempts == 0 {
 		retryConfig.MaxAttempts = 5
@@ -29,18 +58,32 @@ func NewKafkaPublisher(kafkaURL string, topics []string, retryConfig config.Retr
 		retryConfig.MaxDelay = 10 * time.Second
 	}
 
+	p := &KafkaPublisher{
+		Writers:     writers,
+		RetryConfig: retryConfig,
+		Breaker:     cb,
+		Serializer:  publisherConfig.Serializer,
+	}
+	if p.Serializer == nil {
+		p.Serializer = contracts.JSON
+	}
+
 	for _, t := range topics {
 		writers[t] = &kafka.Writer{
-			Addr:     kafka.TCP(kafkaURL),
-			Topic:    t,
-			Balancer: &kafka.LeastBytes{},
+			Addr:         kafka.TCP(brokers...),
+			Topic:        t,
+			Balancer:     &kafka.Hash{},
+			Transport:    transport,
+			BatchSize:    publisherConfig.BatchSize,
+			BatchTimeout: publisherConfig.BatchTimeout,
+			Compression:  publisherConfig.Compression,
+			RequiredAcks: publisherConfig.RequiredAcks,
+			Async:        publisherConfig.Async && !slices.Contains(publisherConfig.SyncTopics, t),
+			Completion:   p.completion(t),
 		}
 	}
 
-	return &KafkaPublisher{
-		Writers:     writers,
-		RetryConfig: retryConfig,
-	}
+	return p
 }
 
 func (p *KafkaPublisher) Publish(ctx context.Context, topic string, message interface{}) error {
@@ -49,26 +92,116 @@ func (p *KafkaPublisher) Publish(ctx context.Context, topic string, message inte
 		return fmt.Errorf("error no writer configured for topic %s", topic)
 	}
 
-	data, err := json.Marshal(message)
+	msg, err := p.newMessage(ctx, message)
 	if err != nil {
-		return fmt.Errorf("error marshaling message: %w", err)
+		return err
 	}
 
-	msg := kafka.Message{
-		Value: data,
+	return p.publishWithRetry(ctx, writer, topic, msg)
+}
+
+// PublishBatch sends several messages to topic in a single write, so the
+// writer can batch and compress them together. Either all of them are
+// accepted or the call fails.
+func (p *KafkaPublisher) PublishBatch(ctx context.Context, topic string, messages []interface{}) error {
+	writer, ok := p.Writers[topic]
+	if !ok {
+		return fmt.Errorf("error no writer configured for topic %s", topic)
+	}
+	if len(messages) == 0 {
+		return nil
 	}
 
-	return p.publishWithRetry(ctx, writer, msg, topic)
+	msgs := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		msg, err := p.newMessage(ctx, message)
+		if err != nil {
+			return err
+		}
+		msgs[i] = msg
+	}
+
+	return p.publishWithRetry(ctx, writer, topic, msgs...)
 }
 
-func (p *KafkaPublisher) publishWithRetry(ctx context.Context, writer *kafka.Writer, msg kafka.Message, topic string) error {
+// PublishMessage sends a raw Kafka message, keeping its key and headers.
+// Used to move consumed messages to retry topics.
+func (p *KafkaPublisher) PublishMessage(ctx context.Context, topic string, msg kafka.Message) error {
+	writer, ok := p.Writers[topic]
+	if !ok {
+		return fmt.Errorf("error no writer configured for topic %s", topic)
+	}
+
+	return p.publishWithRetry(ctx, writer, topic, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: msg.Headers,
+		Time:    time.Now(),
+	})
+}
+
+// newMessage encodes message (see encode) as a Kafka message. The message
+// time marks when it was handed to the writer.
+func (p *KafkaPublisher) newMessage(ctx context.Context, message interface{}) (kafka.Message, error) {
+	encoded, err := encode(ctx, p.Serializer, message)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	msg := kafka.Message{Key: encoded.key, Value: encoded.value, Time: time.Now()}
+	if encoded.contentType != "" {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: contracts.HeaderContentType, Value: []byte(encoded.contentType)})
+	}
+	if encoded.traceID != "" {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: contracts.HeaderTraceID, Value: []byte(encoded.traceID)})
+	}
+	return msg, nil
+}
+
+// completion records the delivery metrics of topic's writer and reports
+// the batch to OnDelivery.
+func (p *KafkaPublisher) completion(topic string) func(messages []kafka.Message, err error) {
+	return func(messages []kafka.Message, err error) {
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+
+		metrics.PublishBatchSize.WithLabelValues(topic).Observe(float64(len(messages)))
+		metrics.PublishedMessagesTotal.WithLabelValues(topic, result).Add(float64(len(messages)))
+		for _, msg := range messages {
+			metrics.PublishDuration.WithLabelValues(topic, result).Observe(time.Since(msg.Time).Seconds())
+		}
+
+		if p.OnDelivery != nil {
+			p.OnDelivery(topic, messages, err)
+		} else if err != nil && p.Writers[topic].Async {
+			logrus.WithField(logging.FieldTopic, topic).WithError(err).Errorf("Async delivery of %d messages failed", len(messages))
+		}
+	}
+}
+
+// publishWithRetry writes msgs with exponential backoff, up to MaxAttempts
+// attempts, in a producer span whose context goes in the headers of msgs.
+func (p *KafkaPublisher) publishWithRetry(ctx context.Context, writer *kafka.Writer, topic string, msgs ...kafka.Message) (err error) {
+	ctx, span := tracing.StartProducer(ctx, tracing.SystemKafka, topic)
+	defer func() { tracing.End(span, err) }()
+	for i := range msgs {
+		tracing.Inject(ctx, (*tracing.KafkaHeaders)(&msgs[i].Headers))
+	}
+
 	var lastErr error
 
 	for attempt := 0; attempt < p.RetryConfig.MaxAttempts; attempt++ {
-		err := writer.WriteMessages(ctx, msg)
+		err := p.Breaker.Execute(func() error {
+			return writer.WriteMessages(ctx, msgs...)
+		})
+		if errors.Is(err, breaker.ErrOpen) {
+			return fmt.Errorf("failed to publish message to topic '%s': %w", topic, err)
+		}
 		if err == nil {
 			if attempt > 0 {
-				fmt.Printf("[Kafka Publisher] Message successfully published to topic '%s' after %d attempts\n", topic, attempt+1)
+				logging.FromContext(ctx).WithField(logging.FieldTopic, topic).Infof("Message published after %d attempts", attempt+1)
 			}
 			return nil
 		}
@@ -79,10 +212,10 @@ func (p *KafkaPublisher) publishWithRetry(ctx context.Context, writer *kafka.Wri
 			break
 		}
 
-		delay := p.calculateBackoff(attempt)
+		delay := calculateBackoff(p.RetryConfig, attempt)
 
-		fmt.Printf("[Kafka Publisher] Retry %d/%d for topic '%s' after %v: %v\n",
-			attempt+1, p.RetryConfig.MaxAttempts, topic, delay, err)
+		logging.FromContext(ctx).WithField(logging.FieldTopic, topic).WithError(err).Warnf("Publish retry %d/%d in %v",
+			attempt+1, p.RetryConfig.MaxAttempts, delay)
 
 		select {
 		case <-time.After(delay):
@@ -96,14 +229,14 @@ func (p *KafkaPublisher) publishWithRetry(ctx context.Context, writer *kafka.Wri
 		topic, p.RetryConfig.MaxAttempts, lastErr)
 }
 
-func (p *KafkaPublisher) calculateBackoff(attempt int) time.Duration {
-	delay := time.Duration(math.Pow(2, float64(attempt))) * p.RetryConfig.BaseDelay
+func calculateBackoff(cfg config.RetryConfig, attempt int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempt))) * cfg.BaseDelay
 
-	if delay > p.RetryConfig.MaxDelay {
-		delay = p.RetryConfig.MaxDelay
+	if delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
 	}
 
-	if p.RetryConfig.Jitter {
+	if cfg.Jitter {
 		jitter := time.Duration(rand.Float64() * float64(delay) * 0.3)
 		delay = delay + jitter - time.Duration(float64(delay)*0.15)
 	}
@@ -0,0 +1,161 @@
+package publisher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/jeffleon2/draftea-wallet-service/internal/breaker"
+	"github.com/jeffleon2/draftea-wallet-service/internal/publisher"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	metadataAPI "github.com/segmentio/kafka-go/protocol/metadata"
+	produceAPI "github.com/segmentio/kafka-go/protocol/produce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransport is a single-partition broker. It records the keys of every
+// produce request and fails them while err is set.
+type fakeTransport struct {
+	mu       sync.Mutex
+	produced [][]string
+	err      error
+}
+
+func (f *fakeTransport) RoundTrip(ctx context.Context, addr net.Addr, req protocol.Message) (protocol.Message, error) {
+	switch req := req.(type) {
+	case *metadataAPI.Request:
+		res := &metadataAPI.Response{}
+		for _, topic := range req.TopicNames {
+			res.Topics = append(res.Topics, metadataAPI.ResponseTopic{Name: topic, Partitions: []metadataAPI.ResponsePartition{{PartitionIndex: 0}}})
+		}
+		return res, nil
+	case *produceAPI.Request:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.err != nil {
+			return nil, f.err
+		}
+		var keys []string
+		records := req.Topics[0].Partitions[0].RecordSet.Records
+		for {
+			record, err := records.ReadRecord()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			key, err := protocol.ReadAll(record.Key)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, string(key))
+		}
+		f.produced = append(f.produced, keys)
+		topic := req.Topics[0]
+		return &produceAPI.Response{Topics: []produceAPI.ResponseTopic{{Topic: topic.Topic, Partitions: []produceAPI.ResponsePartition{{Partition: topic.Partitions[0].Partition}}}}}, nil
+	default:
+		return nil, fmt.Errorf("unexpected request %T", req)
+	}
+}
+
+func (f *fakeTransport) requests() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]string(nil), f.produced...)
+}
+
+// keyedMessage is a plain JSON message with a partition key.
+type keyedMessage struct {
+	ID string `json:"id"`
+}
+
+func (m keyedMessage) MessageKey() string { return m.ID }
+
+func newKafkaPublisher(t *testing.T, transport *fakeTransport, topics []string, publisherConfig config.PublisherConfig) *publisher.KafkaPublisher {
+	t.Helper()
+
+	retryConfig := config.RetryConfig{MaxAttempts: 1}
+	p := publisher.NewKafkaPublisher([]string{"localhost:9092"}, nil, topics, retryConfig, publisherConfig, breaker.New("kafka-test", config.CircuitBreaker{}))
+	for _, writer := range p.Writers {
+		writer.Transport = transport
+		t.Cleanup(func() { writer.Close() })
+	}
+	return p
+}
+
+func TestKafkaPublisher_PublishBatch(t *testing.T) {
+	transport := &fakeTransport{}
+	p := newKafkaPublisher(t, transport, []string{"wallet.dlq"}, config.PublisherConfig{BatchSize: 10, BatchTimeout: time.Millisecond})
+
+	err := p.PublishBatch(context.Background(), "wallet.dlq", []interface{}{
+		keyedMessage{ID: "pay_1"},
+		keyedMessage{ID: "pay_2"},
+		keyedMessage{ID: "pay_3"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"pay_1", "pay_2", "pay_3"}}, transport.requests(), "the batch is written in one produce request")
+}
+
+func TestKafkaPublisher_PublishBatchErrors(t *testing.T) {
+	transport := &fakeTransport{}
+	p := newKafkaPublisher(t, transport, []string{"wallet.dlq"}, config.PublisherConfig{BatchTimeout: time.Millisecond})
+
+	require.NoError(t, p.PublishBatch(context.Background(), "wallet.dlq", nil))
+	assert.ErrorContains(t, p.PublishBatch(context.Background(), "wallet.unknown", []interface{}{keyedMessage{ID: "pay_1"}}), "no writer configured")
+	assert.Error(t, p.PublishBatch(context.Background(), "wallet.dlq", []interface{}{keyedMessage{ID: "pay_1"}, func() {}}), "a message that cannot be encoded fails the whole batch")
+	assert.Empty(t, transport.requests())
+
+	transport.mu.Lock()
+	transport.err = errors.New("broker unavailable")
+	transport.mu.Unlock()
+	assert.ErrorContains(t, p.PublishBatch(context.Background(), "wallet.dlq", []interface{}{keyedMessage{ID: "pay_1"}}), "broker unavailable")
+}
+
+func TestKafkaPublisher_SyncTopicsOverrideAsync(t *testing.T) {
+	transport := &fakeTransport{err: errors.New("broker unavailable")}
+	p := newKafkaPublisher(t, transport, []string{"wallet.funds.verified", "wallet.retry.1s"}, config.PublisherConfig{
+		BatchTimeout: time.Millisecond,
+		Async:        true,
+		Serializer:   contracts.JSON,
+		SyncTopics:   []string{"wallet.retry.1s"},
+	})
+
+	assert.True(t, p.Writers["wallet.funds.verified"].Async)
+	assert.False(t, p.Writers["wallet.retry.1s"].Async)
+
+	assert.NoError(t, p.Publish(context.Background(), "wallet.funds.verified", keyedMessage{ID: "pay_1"}), "async writes only report delivery failures to the completion")
+	assert.ErrorContains(t, p.Publish(context.Background(), "wallet.retry.1s", keyedMessage{ID: "pay_1"}), "broker unavailable")
+}
+
+func TestKafkaPublisher_OnDelivery(t *testing.T) {
+	transport := &fakeTransport{}
+	p := newKafkaPublisher(t, transport, []string{"wallet.funds.verified"}, config.PublisherConfig{BatchTimeout: time.Millisecond, Async: true})
+
+	delivered := make(chan []kafka.Message, 1)
+	p.OnDelivery = func(topic string, messages []kafka.Message, err error) {
+		assert.Equal(t, "wallet.funds.verified", topic)
+		assert.NoError(t, err)
+		delivered <- messages
+	}
+
+	require.NoError(t, p.Publish(context.Background(), "wallet.funds.verified", keyedMessage{ID: "pay_1"}))
+
+	select {
+	case messages := <-delivered:
+		require.Len(t, messages, 1)
+		assert.Equal(t, "pay_1", string(messages[0].Key))
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnDelivery was not called")
+	}
+}
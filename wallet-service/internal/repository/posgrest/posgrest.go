@@ -2,27 +2,43 @@ package posgrest
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jeffleon2/draftea-wallet-service/internal/breaker"
 	"gorm.io/gorm"
 )
 
 type repository[T interface{}] struct {
-	db *gorm.DB
+	db      *gorm.DB
+	breaker *breaker.Breaker
 }
 
-func New[T interface{}](db *gorm.DB) *repository[T] {
+func New[T interface{}](db *gorm.DB, cb *breaker.Breaker) *repository[T] {
 	return &repository[T]{
 		db,
+		cb,
 	}
 }
 
+// IsFailure reports whether err means Postgres is failing. Missing records
+// and cancelled requests do not count against the circuit breaker: the first
+// is an answer from a healthy database and the second is the caller giving
+// up, so neither should open it and reject every other request.
+func IsFailure(err error) bool {
+	return !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, context.Canceled)
+}
+
 func (r *repository[T]) Create(ctx context.Context, entity *T) error {
-	return r.db.WithContext(ctx).Create(&entity).Error
+	return r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).Create(&entity).Error
+	})
 }
 
 func (r *repository[T]) GetAll(ctx context.Context) (*[]T, error) {
 	var entities []T
-	err := r.db.WithContext(ctx).Find(&entities).Error
+	err := r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).Find(&entities).Error
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +47,10 @@ func (r *repository[T]) GetAll(ctx context.Context) (*[]T, error) {
 
 func (r *repository[T]) GetByID(ctx context.Context, id string) (*T, error) {
 	var entity T
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&entity).Error; err != nil {
+	err := r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).Where("id = ?", id).First(&entity).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &entity, nil
@@ -39,17 +58,24 @@ func (r *repository[T]) GetByID(ctx context.Context, id string) (*T, error) {
 
 func (r *repository[T]) GetBy(ctx context.Context, key string, value interface{}) (*[]T, error) {
 	var entity []T
-	if err := r.db.WithContext(ctx).Where(key, value).Find(&entity).Error; err != nil {
+	err := r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).Where(key, value).Find(&entity).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &entity, nil
 }
 
 func (r *repository[T]) Update(ctx context.Context, entity *T, id string) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Updates(entity).Error
+	return r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).Where("id = ?", id).Updates(entity).Error
+	})
 }
 
 func (r *repository[T]) Delete(ctx context.Context, id string) error {
 	var entity T
-	return r.db.WithContext(ctx).Delete(&entity, id).Error
+	return r.breaker.Execute(func() error {
+		return r.db.WithContext(ctx).Delete(&entity, id).Error
+	})
 }
@@ -3,8 +3,9 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-wallet-service/internal/logging"
 	"github.com/jeffleon2/draftea-wallet-service/internal/models"
 )
 
@@ -45,7 +46,8 @@ func NewWalletService(p Publisher, w WalletRepo) *WalletService {
 // This method is called when a payments.created event is received.
 //
 // It verifies the wallet balance against the requested payment amount and publishes
-// a wallet.funds.verified event with either APPROVED or DECLINED status.
+// a wallet.funds.verified event with either APPROVED or DECLINED status that
+// carries the trace ID of the payment.
 // This is a verification-only operation; no funds are debited at this stage.
 //
 // Returns an error if the wallet is not found or if there's a database/publishing error.
@@ -74,7 +76,12 @@ func (s *WalletService) ValidateFunds(ctx context.Context, event models.PaymentC
 		Amount:    event.Amount,
 		Status:    status,
 		Reason:    reasonDeclained,
+		TraceID:   event.TraceID,
 	}
+	if walletResponse.TraceID == "" {
+		walletResponse.TraceID = contracts.TraceID(ctx)
+	}
+	logging.FromContext(ctx).WithField(logging.FieldPaymentID, event.ID).Infof("Funds verified: %s", status)
 
 	return s.Publisher.Publish(ctx, models.WalletResponseTopic, walletResponse)
 }
@@ -90,7 +97,7 @@ func (s *WalletService) ValidateFunds(ctx context.Context, event models.PaymentC
 // Note: This method assumes funds were already verified by ValidateFunds.
 // The actual balance check happened earlier in the payment flow.
 func (s *WalletService) DebitBalance(ctx context.Context, userID string, amount float64) error {
-	fmt.Println("Amount to discound", userID, amount)
+	logging.FromContext(ctx).WithField(logging.FieldCustomerID, userID).Infof("Debiting %.2f", amount)
 	wallet, err := s.WalletRepo.GetBy(ctx, "user_id", userID)
 	if err != nil {
 		return err
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	contracts "github.com/jeffleon2/draftea-event-contracts"
 	"github.com/jeffleon2/draftea-wallet-service/internal/models"
 	"github.com/jeffleon2/draftea-wallet-service/internal/service"
 	"github.com/jeffleon2/draftea-wallet-service/internal/service/mocks"
@@ -40,7 +41,8 @@ func TestValidateFunds_SufficientBalance(t *testing.T) {
 
 	mockPublisher.EXPECT().
 		Publish(ctx, models.WalletResponseTopic, mock.MatchedBy(func(evt models.WalletResponseEvent) bool {
-			return evt.PaymentID == event.ID &&
+			return contracts.ValidateEvent(evt) == nil &&
+				evt.PaymentID == event.ID &&
 				evt.UserID == event.CustomerID &&
 				evt.Status == models.WalletStatusApproved &&
 				evt.Amount == event.Amount &&
@@ -84,7 +86,8 @@ func TestValidateFunds_InsufficientBalance(t *testing.T) {
 
 	mockPublisher.EXPECT().
 		Publish(ctx, models.WalletResponseTopic, mock.MatchedBy(func(evt models.WalletResponseEvent) bool {
-			return evt.PaymentID == event.ID &&
+			return contracts.ValidateEvent(evt) == nil &&
+				evt.PaymentID == event.ID &&
 				evt.UserID == event.CustomerID &&
 				evt.Status == models.WalletStatusDeclined &&
 				evt.Amount == event.Amount &&
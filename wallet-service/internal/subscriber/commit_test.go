@@ -0,0 +1,162 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommitter records the offsets of every commit and fails the next
+// commits while err is set.
+type fakeCommitter struct {
+	mu      sync.Mutex
+	commits [][]int64
+	err     error
+}
+
+func (f *fakeCommitter) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	offsets := make([]int64, len(msgs))
+	for i, msg := range msgs {
+		offsets[i] = msg.Offset
+	}
+	f.commits = append(f.commits, offsets)
+	return nil
+}
+
+func (f *fakeCommitter) committed() [][]int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]int64(nil), f.commits...)
+}
+
+func message(partition int, offset int64) kafka.Message {
+	return kafka.Message{Topic: "payments.checked", Partition: partition, Offset: offset}
+}
+
+func TestOffsetCommitterBatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.CommitConfig
+		// intervalElapsed backdates the last commit before each message.
+		intervalElapsed bool
+		handled         int
+		wantCommits     [][]int64
+	}{
+		{
+			name:        "commits every full batch",
+			config:      config.CommitConfig{BatchSize: 3, Interval: time.Hour},
+			handled:     7,
+			wantCommits: [][]int64{{0, 1, 2}, {3, 4, 5}},
+		},
+		{
+			name:        "batch size below one commits every message",
+			config:      config.CommitConfig{BatchSize: 0, Interval: time.Hour},
+			handled:     2,
+			wantCommits: [][]int64{{0}, {1}},
+		},
+		{
+			name:        "partial batch waits for the interval",
+			config:      config.CommitConfig{BatchSize: 100, Interval: time.Hour},
+			handled:     5,
+			wantCommits: nil,
+		},
+		{
+			name:            "elapsed interval commits a partial batch",
+			config:          config.CommitConfig{BatchSize: 100, Interval: time.Second},
+			intervalElapsed: true,
+			handled:         2,
+			wantCommits:     [][]int64{{0}, {1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &fakeCommitter{}
+			committer := newOffsetCommitter(reader, tt.config)
+
+			for offset := range int64(tt.handled) {
+				if tt.intervalElapsed {
+					committer.lastCommit = time.Now().Add(-tt.config.Interval)
+				}
+				committer.Track(message(0, offset))
+				require.NoError(t, committer.Done(context.Background(), message(0, offset)))
+			}
+
+			assert.Equal(t, tt.wantCommits, reader.committed())
+		})
+	}
+}
+
+func TestOffsetCommitterKeepsTheBatchOnError(t *testing.T) {
+	reader := &fakeCommitter{err: errors.New("coordinator not available")}
+	committer := newOffsetCommitter(reader, config.CommitConfig{BatchSize: 2, Interval: time.Hour})
+
+	for offset := range int64(2) {
+		committer.Track(message(0, offset))
+	}
+	require.NoError(t, committer.Done(context.Background(), message(0, 0)))
+	assert.Error(t, committer.Done(context.Background(), message(0, 1)))
+
+	reader.mu.Lock()
+	reader.err = nil
+	reader.mu.Unlock()
+	require.NoError(t, committer.Flush(context.Background()))
+
+	assert.Equal(t, [][]int64{{0, 1}}, reader.committed())
+}
+
+func TestOffsetCommitterFlushesEveryInterval(t *testing.T) {
+	reader := &fakeCommitter{}
+	committer := newOffsetCommitter(reader, config.CommitConfig{BatchSize: 100, Interval: 20 * time.Millisecond})
+
+	committer.Track(message(0, 0))
+	require.NoError(t, committer.Done(context.Background(), message(0, 0)))
+	assert.Empty(t, reader.committed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go committer.FlushEvery(ctx, func(err error) { t.Error(err) })
+
+	assert.Eventually(t, func() bool {
+		return len(reader.committed()) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][]int64{{0}}, reader.committed())
+}
+
+func TestOffsetCommitterNeverCommitsPastAnUnfinishedOffset(t *testing.T) {
+	reader := &fakeCommitter{}
+	committer := newOffsetCommitter(reader, config.CommitConfig{BatchSize: 1, Interval: time.Hour})
+	ctx := context.Background()
+
+	for offset := range int64(4) {
+		committer.Track(message(0, offset))
+	}
+	committer.Track(message(1, 0))
+
+	require.NoError(t, committer.Done(ctx, message(0, 3)))
+	require.NoError(t, committer.Done(ctx, message(0, 1)))
+	require.NoError(t, committer.Flush(ctx))
+	assert.Empty(t, reader.committed(), "offset 0 of partition 0 is still in flight")
+
+	require.NoError(t, committer.Done(ctx, message(1, 0)))
+	assert.Equal(t, [][]int64{{0}}, reader.committed(), "other partitions are not held back")
+
+	require.NoError(t, committer.Done(ctx, message(0, 0)))
+	assert.Equal(t, [][]int64{{0}, {0, 1}}, reader.committed(), "offset 2 is still in flight")
+
+	require.NoError(t, committer.Done(ctx, message(0, 2)))
+	assert.Equal(t, [][]int64{{0}, {0, 1}, {2, 3}}, reader.committed())
+}
@@ -0,0 +1,52 @@
+package subscriber
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	ErrorClassPermanent = "permanent"
+	ErrorClassTransient = "transient"
+)
+
+// PermanentError marks a handler error that retrying cannot fix,
+// e.g. a malformed payload or an unknown topic. Such messages go
+// straight to the DLQ.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so the consumer does not retry it.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err should skip retries. Besides errors wrapped
+// with Permanent, JSON decoding errors are permanent: the payload will not change.
+// Everything else (database, Kafka, timeouts) is treated as transient.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &permanent) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
+
+// ErrorClass returns the metrics label for err.
+func ErrorClass(err error) string {
+	if IsPermanent(err) {
+		return ErrorClassPermanent
+	}
+	return ErrorClassTransient
+}
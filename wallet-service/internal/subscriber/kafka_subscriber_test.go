@@ -0,0 +1,162 @@
+package subscriber_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/jeffleon2/draftea-wallet-service/internal/models"
+	"github.com/jeffleon2/draftea-wallet-service/internal/subscriber"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const waitFor = 5 * time.Second
+
+func paymentCreated(id string) contracts.PaymentCreatedEvent {
+	return contracts.PaymentCreatedEvent{
+		ID:         id,
+		Amount:     100,
+		Currency:   "USD",
+		Status:     "PENDING",
+		Method:     "CREDIT_CARD",
+		CustomerID: "customer-1",
+		TraceID:    "trace-" + id,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// fakeReader serves the messages sent to it and records the committed offsets.
+type fakeReader struct {
+	topic    string
+	messages chan kafka.Message
+
+	mu        sync.Mutex
+	committed []int64
+	closed    bool
+}
+
+func newFakeReader(topic string) *fakeReader {
+	return &fakeReader{topic: topic, messages: make(chan kafka.Message, 100)}
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case msg := <-r.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Config() kafka.ReaderConfig {
+	return kafka.ReaderConfig{Topic: r.topic}
+}
+
+func (r *fakeReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *fakeReader) commits() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+// send queues a payments.created event for payment id at offset.
+func (r *fakeReader) send(t *testing.T, id string, offset int64) {
+	t.Helper()
+
+	envelope, err := contracts.New(models.ServiceName, paymentCreated(id))
+	require.NoError(t, err)
+	value, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	r.messages <- kafka.Message{Topic: r.topic, Key: []byte(id), Offset: offset, Value: value}
+}
+
+func newKafkaConsumer(reader *fakeReader, commitConfig config.CommitConfig) *subscriber.KafkaConsumer {
+	return &subscriber.KafkaConsumer{
+		Readers:      []subscriber.Reader{reader},
+		GroupID:      "wallet-service",
+		CommitConfig: commitConfig,
+		Workers:      4,
+		Serializers:  contracts.DefaultSerializers,
+	}
+}
+
+func TestKafkaConsumerFlushesOnShutdown(t *testing.T) {
+	reader := newFakeReader(models.PaymentCreatedEventTopic)
+	consumer := newKafkaConsumer(reader, config.CommitConfig{BatchSize: 100, Interval: time.Hour})
+
+	var mu sync.Mutex
+	handled := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled++
+		return nil
+	})
+
+	for offset := range int64(3) {
+		reader.send(t, fmt.Sprintf("pay_%d", offset), offset)
+	}
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return handled == 3
+	}, waitFor, 5*time.Millisecond)
+	assert.Empty(t, reader.commits(), "the batch is not full and the interval has not elapsed")
+
+	cancel()
+	require.NoError(t, consumer.Close())
+
+	assert.ElementsMatch(t, []int64{0, 1, 2}, reader.commits())
+	assert.True(t, reader.closed)
+}
+
+func TestKafkaConsumerFinishesTheMessageInFlightOnShutdown(t *testing.T) {
+	reader := newFakeReader(models.PaymentCreatedEventTopic)
+	consumer := newKafkaConsumer(reader, config.CommitConfig{BatchSize: 1, Interval: time.Hour})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handled := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.Listen(ctx, func(ctx context.Context, topic string, envelope contracts.Envelope) error {
+		close(started)
+		<-release
+		handled <- ctx.Err()
+		return ctx.Err()
+	})
+
+	reader.send(t, "pay_0", 0)
+	<-started
+	cancel()
+	close(release)
+	require.NoError(t, consumer.Close())
+
+	assert.NoError(t, <-handled, "the handler keeps a live context")
+	assert.Equal(t, []int64{0}, reader.commits())
+}
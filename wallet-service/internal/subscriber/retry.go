@@ -0,0 +1,200 @@
+package subscriber
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Headers carrying the retry metadata of a message moved to a retry topic.
+const (
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderRetryAttempt      = "x-retry-attempt"
+	HeaderRetryNotBefore    = "x-retry-not-before"
+	HeaderLastError         = "x-last-error"
+)
+
+// retryInfo is the retry metadata of a consumed message. Messages read from
+// the original topics have no headers: their original topic is their own
+// and no attempt has failed yet.
+type retryInfo struct {
+	originalTopic     string
+	originalPartition int
+	originalOffset    int64
+	attempts          int
+	notBefore         time.Time
+}
+
+func readRetryInfo(msg kafka.Message) retryInfo {
+	info := retryInfo{
+		originalTopic:     msg.Topic,
+		originalPartition: msg.Partition,
+		originalOffset:    msg.Offset,
+	}
+
+	for _, h := range msg.Headers {
+		value := string(h.Value)
+		switch h.Key {
+		case HeaderOriginalTopic:
+			info.originalTopic = value
+		case HeaderOriginalPartition:
+			if n, err := strconv.Atoi(value); err == nil {
+				info.originalPartition = n
+			}
+		case HeaderOriginalOffset:
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.originalOffset = n
+			}
+		case HeaderRetryAttempt:
+			if n, err := strconv.Atoi(value); err == nil {
+				info.attempts = n
+			}
+		case HeaderRetryNotBefore:
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.notBefore = time.UnixMilli(ms)
+			}
+		}
+	}
+
+	return info
+}
+
+// retryMessage copies msg for the next retry tier. The original topic,
+// partition and offset are kept from the first failure, and the trace ID
+// travels with the message.
+func retryMessage(msg kafka.Message, info retryInfo, handlerErr error, delay time.Duration) kafka.Message {
+	headers := map[string]string{
+		HeaderOriginalTopic:     msg.Topic,
+		HeaderOriginalPartition: strconv.Itoa(msg.Partition),
+		HeaderOriginalOffset:    strconv.FormatInt(msg.Offset, 10),
+	}
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset, contracts.HeaderContentType, contracts.HeaderTraceID:
+			headers[h.Key] = string(h.Value)
+		}
+	}
+	headers[HeaderRetryAttempt] = strconv.Itoa(info.attempts + 1)
+	headers[HeaderRetryNotBefore] = strconv.FormatInt(time.Now().Add(delay).UnixMilli(), 10)
+	headers[HeaderLastError] = handlerErr.Error()
+
+	retry := kafka.Message{Key: msg.Key, Value: msg.Value}
+	if contentType, ok := headers[contracts.HeaderContentType]; ok {
+		// The value keeps its format, so it keeps its content type.
+		retry.Headers = append(retry.Headers, kafka.Header{Key: contracts.HeaderContentType, Value: []byte(contentType)})
+	}
+	if traceID, ok := headers[contracts.HeaderTraceID]; ok {
+		retry.Headers = append(retry.Headers, kafka.Header{Key: contracts.HeaderTraceID, Value: []byte(traceID)})
+	}
+	for _, key := range []string{
+		HeaderOriginalTopic,
+		HeaderOriginalPartition,
+		HeaderOriginalOffset,
+		HeaderRetryAttempt,
+		HeaderRetryNotBefore,
+		HeaderLastError,
+	} {
+		retry.Headers = append(retry.Headers, kafka.Header{Key: key, Value: []byte(headers[key])})
+	}
+
+	return retry
+}
+
+// nextRetryTier returns the retry topic and delay of the tier a message that
+// failed with err moves to. It returns false when err is permanent or every
+// tier was tried, and the message goes to the DLQ.
+func nextRetryTier(cfg config.RetryConfig, info retryInfo, err error) (string, time.Duration, bool) {
+	if IsPermanent(err) || info.attempts >= len(cfg.Delays) {
+		return "", 0, false
+	}
+	return cfg.RetryTopic(info.attempts), cfg.Delays[info.attempts], true
+}
+
+func headersMap(headers []kafka.Header) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(headers))
+	for _, h := range headers {
+		m[h.Key] = string(h.Value)
+	}
+	return m
+}
+
+// waitUntil blocks until t or until ctx is done, returning ctx.Err() in the latter case.
+func waitUntil(ctx context.Context, t time.Time) error {
+	delay := time.Until(t)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// decode reads value with the serializer of contentType and validates it
+// against its event contract.
+func decode(serializers contracts.Serializers, contentType string, value []byte) (contracts.Envelope, error) {
+	serializer, err := serializers.For(contentType)
+	if err != nil {
+		return contracts.Envelope{}, err
+	}
+	envelope, err := serializer.Unmarshal(value)
+	if err != nil {
+		return contracts.Envelope{}, err
+	}
+	if err := envelope.Validate(); err != nil {
+		return contracts.Envelope{}, err
+	}
+	return envelope, nil
+}
+
+// publishUntilDone keeps calling publish with backoff until it succeeds or ctx
+// is done: acknowledging a message that was not republished would lose it.
+func publishUntilDone(ctx context.Context, cfg config.RetryConfig, publish func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := publish()
+		if err == nil {
+			return nil
+		}
+
+		logrus.WithError(err).Error("Failed to republish message")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(calculateBackoff(cfg, attempt)):
+		}
+	}
+}
+
+func calculateBackoff(cfg config.RetryConfig, attempt int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempt))) * cfg.BaseDelay
+
+	if delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
+	}
+
+	if cfg.Jitter {
+		jitter := time.Duration(rand.Float64() * float64(delay) * 0.3)
+		delay = delay + jitter - time.Duration(float64(delay)*0.15)
+	}
+
+	return delay
+}
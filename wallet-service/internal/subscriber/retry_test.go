@@ -0,0 +1,138 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	contracts "github.com/jeffleon2/draftea-event-contracts"
+	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var retryConfig = config.RetryConfig{
+	Delays:      []time.Duration{time.Second, 30 * time.Second, 5 * time.Minute},
+	TopicPrefix: "payments.retry",
+}
+
+func TestRetryHeadersRoundTrip(t *testing.T) {
+	original := kafka.Message{
+		Topic:     "payments.checked",
+		Partition: 2,
+		Offset:    41,
+		Key:       []byte("payment-123"),
+		Value:     []byte(`{"id":"event-1"}`),
+		Headers: []kafka.Header{
+			{Key: contracts.HeaderContentType, Value: []byte("application/json")},
+			{Key: contracts.HeaderTraceID, Value: []byte("trace-123")},
+			{Key: "traceparent", Value: []byte("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")},
+		},
+	}
+
+	first := retryMessage(original, readRetryInfo(original), errors.New("database unavailable"), time.Second)
+	first.Topic, first.Partition, first.Offset = "payments.retry.1s", 0, 7
+	info := readRetryInfo(first)
+
+	assert.Equal(t, "payments.checked", info.originalTopic)
+	assert.Equal(t, 2, info.originalPartition)
+	assert.Equal(t, int64(41), info.originalOffset)
+	assert.Equal(t, 1, info.attempts)
+	assert.WithinDuration(t, time.Now().Add(time.Second), info.notBefore, 100*time.Millisecond)
+	assert.Equal(t, original.Key, first.Key)
+	assert.Equal(t, original.Value, first.Value)
+
+	second := retryMessage(first, info, errors.New("still unavailable"), 30*time.Second)
+	second.Topic, second.Partition, second.Offset = "payments.retry.30s", 1, 3
+	info = readRetryInfo(second)
+
+	assert.Equal(t, "payments.checked", info.originalTopic, "the first failure stays the origin")
+	assert.Equal(t, 2, info.originalPartition)
+	assert.Equal(t, int64(41), info.originalOffset)
+	assert.Equal(t, 2, info.attempts)
+
+	headers := headersMap(second.Headers)
+	assert.Equal(t, "application/json", headers[contracts.HeaderContentType])
+	assert.Equal(t, "trace-123", headers[contracts.HeaderTraceID])
+	assert.Equal(t, "still unavailable", headers[HeaderLastError])
+	assert.NotContains(t, headers, "traceparent", "each hop injects its own span context")
+}
+
+func TestReadRetryInfoIgnoresMalformedHeaders(t *testing.T) {
+	msg := kafka.Message{
+		Topic:     "payments.retry.1s",
+		Partition: 1,
+		Offset:    9,
+		Headers: []kafka.Header{
+			{Key: HeaderOriginalPartition, Value: []byte("two")},
+			{Key: HeaderRetryAttempt, Value: []byte("")},
+			{Key: HeaderRetryNotBefore, Value: []byte("soon")},
+		},
+	}
+
+	info := readRetryInfo(msg)
+
+	assert.Equal(t, retryInfo{originalTopic: "payments.retry.1s", originalPartition: 1, originalOffset: 9}, info)
+}
+
+func TestNextRetryTier(t *testing.T) {
+	transient := errors.New("database unavailable")
+
+	tests := []struct {
+		name      string
+		config    config.RetryConfig
+		attempts  int
+		err       error
+		wantTopic string
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{name: "first failure goes to the first tier", config: retryConfig, attempts: 0, err: transient, wantTopic: "payments.retry.1s", wantDelay: time.Second, wantRetry: true},
+		{name: "second failure goes to the second tier", config: retryConfig, attempts: 1, err: transient, wantTopic: "payments.retry.30s", wantDelay: 30 * time.Second, wantRetry: true},
+		{name: "last tier", config: retryConfig, attempts: 2, err: transient, wantTopic: "payments.retry.5m", wantDelay: 5 * time.Minute, wantRetry: true},
+		{name: "exhausted tiers go to the DLQ", config: retryConfig, attempts: 3, err: transient},
+		{name: "permanent errors skip the tiers", config: retryConfig, attempts: 0, err: Permanent(transient)},
+		{name: "no tiers configured", config: config.RetryConfig{TopicPrefix: "payments.retry"}, attempts: 0, err: transient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, delay, ok := nextRetryTier(tt.config, retryInfo{attempts: tt.attempts}, tt.err)
+
+			assert.Equal(t, tt.wantRetry, ok)
+			assert.Equal(t, tt.wantTopic, topic)
+			assert.Equal(t, tt.wantDelay, delay)
+		})
+	}
+}
+
+func TestWaitUntil(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		until   time.Duration
+		wantErr error
+		minWait time.Duration
+	}{
+		{name: "past time returns at once", ctx: context.Background(), until: -time.Minute},
+		{name: "future time waits", ctx: context.Background(), until: 30 * time.Millisecond, minWait: 30 * time.Millisecond},
+		{name: "past time ignores a cancelled context", ctx: cancelled, until: -time.Minute},
+		{name: "cancelled context stops the wait", ctx: cancelled, until: time.Hour, wantErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := waitUntil(tt.ctx, start.Add(tt.until))
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.GreaterOrEqual(t, time.Since(start), tt.minWait)
+			assert.Less(t, time.Since(start), tt.minWait+time.Second)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package subscriber
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// workerQueueSize bounds how many messages wait for each worker before
+// fetching blocks.
+const workerQueueSize = 16
+
+// workerFor picks the worker of msg. Messages with the same key always go to
+// the same worker, which handles them one at a time, so per-key order is kept.
+// Messages without a key are spread by partition to keep partition order.
+func workerFor(msg kafka.Message, workers int) int {
+	key := msg.Key
+	if len(key) == 0 {
+		key = strconv.AppendInt(nil, int64(msg.Partition), 10)
+	}
+	return workerForKey(key, workers)
+}
+
+// workerForKey picks the worker of the messages with key.
+func workerForKey(key []byte, workers int) int {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % uint32(workers))
+}
@@ -0,0 +1,82 @@
+// Package wallettest runs wallet-service in process for whole-system tests:
+// its event handler consumes from a membroker.Broker instead of Kafka and the
+// wallets are stored in the given database instead of Postgres. The
+// configuration is read from the environment, with the service defaults.
+package wallettest
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/jeffleon2/draftea-event-contracts/membroker"
+	"github.com/jeffleon2/draftea-wallet-service/config"
+	"github.com/jeffleon2/draftea-wallet-service/internal/breaker"
+	"github.com/jeffleon2/draftea-wallet-service/internal/handler"
+	"github.com/jeffleon2/draftea-wallet-service/internal/models"
+	"github.com/jeffleon2/draftea-wallet-service/internal/repository/posgrest"
+	"github.com/jeffleon2/draftea-wallet-service/internal/service"
+	"github.com/jeffleon2/draftea-wallet-service/internal/subscriber"
+	"gorm.io/gorm"
+)
+
+// Service is a running wallet-service.
+type Service struct {
+	Consumer *membroker.Consumer
+
+	wallets *service.WalletService
+}
+
+// Start migrates db and starts consuming the service's topics from broker
+// until ctx is cancelled.
+func Start(ctx context.Context, broker *membroker.Broker, db *gorm.DB) (*Service, error) {
+	var cfg config.Config
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&models.Wallet{}); err != nil {
+		return nil, err
+	}
+
+	publisherConfig, err := cfg.Kafka.GetPublisherConfig()
+	if err != nil {
+		return nil, err
+	}
+	publisher := broker.Publisher(models.ServiceName)
+	publisher.Serializer = publisherConfig.Serializer
+
+	dbBreaker := breaker.New("postgres", cfg.CircuitBreaker)
+	dbBreaker.IsFailure = posgrest.IsFailure
+	walletService := service.NewWalletService(publisher, posgrest.New[models.Wallet](db, dbBreaker))
+	walletHandler := handler.Wallet(walletService)
+
+	consumer := broker.Consumer(cfg.Kafka.WalletConsumerGroup, strings.Split(cfg.Kafka.SubscriberTopics, ",")...)
+	consumer.IsPermanent = subscriber.IsPermanent
+	consumer.Listen(ctx, walletHandler.Handler)
+
+	return &Service{Consumer: consumer, wallets: walletService}, nil
+}
+
+// CreateWallet stores the wallet id of userID with balance.
+func (s *Service) CreateWallet(ctx context.Context, id, userID string, balance float64) error {
+	return s.wallets.WalletRepo.Create(ctx, &models.Wallet{ID: id, UserID: userID, Balance: balance})
+}
+
+// Balance returns the balance of the wallet of userID.
+func (s *Service) Balance(ctx context.Context, userID string) (float64, error) {
+	wallets, err := s.wallets.WalletRepo.GetBy(ctx, "user_id", userID)
+	if err != nil {
+		return 0, err
+	}
+	if wallets == nil || len(*wallets) == 0 {
+		return 0, errors.New("wallet not found")
+	}
+	return (*wallets)[0].Balance, nil
+}
+
+// Close waits for the consumer to stop. The context given to Start must be
+// cancelled first.
+func (s *Service) Close() error {
+	return s.Consumer.Close()
+}